@@ -21,14 +21,24 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"log"
+	"maps"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/kluctl/kluctl/lib/go-jinja2"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/term"
 
 	"github.com/retr0h/voidspan/internal/ansible"
+	"github.com/retr0h/voidspan/internal/executor"
+	"github.com/retr0h/voidspan/internal/inventory"
+	"github.com/retr0h/voidspan/internal/module"
+	"github.com/retr0h/voidspan/internal/vars"
 )
 
 var runCmd = &cobra.Command{
@@ -48,18 +58,81 @@ var runCmd = &cobra.Command{
 			log.Fatalf("failed to parse playbook: %v", err)
 		}
 
+		extraVars := make(map[string]interface{})
+		for _, value := range viper.GetStringSlice("extra-vars") {
+			parsed, err := vars.ParseExtraVars(value)
+			if err != nil {
+				log.Fatalf("failed to parse extra vars: %v", err)
+			}
+			maps.Copy(extraVars, parsed)
+		}
+
+		inv := inventory.Implicit()
+		if sources := viper.GetStringSlice("inventory"); len(sources) > 0 {
+			inv, err = inventory.Load(sources...)
+			if err != nil {
+				log.Fatalf("failed to load inventory: %v", err)
+			}
+		}
+		if err := inv.LoadVarsDir(filepath.Dir(playbookPath)); err != nil {
+			log.Fatalf("failed to load playbook vars: %v", err)
+		}
+
+		if limit := viper.GetString("limit"); limit != "" {
+			if err := inv.Limit(limit); err != nil {
+				log.Fatalf("failed to apply limit: %v", err)
+			}
+		}
+
+		strategy := viper.GetString("strategy")
+		if err := executor.ValidateStrategy(strategy); err != nil {
+			log.Fatalf("invalid --strategy: %v", err)
+		}
+
 		for _, play := range plays {
-			fmt.Printf("▶ Play: %s (hosts: %s)\n", play.Name, play.Hosts)
-			for _, task := range play.Tasks {
-				fmt.Printf("  ▸ Task: %s\n", task.Name)
-				fmt.Printf("    Module: %s\n", task.Module)
-				fmt.Printf("    Args: %+v\n", task.RawArgs)
-				fmt.Printf("    Vars: %+v\n", task.Vars)
-				if task.Loop != "" {
-					fmt.Printf("    Loop: %s\n", task.Loop)
-				}
+			if _, err := inv.ListHosts(play.Hosts); err != nil {
+				log.Fatalf("failed to resolve hosts of play %q: %v", play.Name, err)
+			}
+			if play.Strategy == "" {
+				continue
+			}
+			if err := executor.ValidateStrategy(play.Strategy); err != nil {
+				log.Fatalf("invalid strategy of play %q: %v", play.Name, err)
+			}
+		}
 
+		forks := viper.GetInt("forks")
+		opts := []executor.Option{
+			executor.WithInventory(inv),
+			executor.WithExtraVars(extraVars),
+			executor.WithForks(forks),
+			executor.WithStrategy(strategy),
+			executor.WithVerbosity(viper.GetInt("verbose")),
+		}
+		if viper.GetBool("ask-become-pass") {
+			password, err := askBecomePassword()
+			if err != nil {
+				log.Fatalf("failed to read become password: %v", err)
 			}
+			opts = append(opts, executor.WithBecomePassword(password))
+		}
+
+		// one renderer worker per fork, so forks do not wait on each other
+		renderer, err := jinja2.NewJinja2("voidspan", max(forks, 1), jinja2.WithStrict(false))
+		if err != nil {
+			log.Fatalf("failed to create jinja2 renderer: %v", err)
+		}
+
+		stats := executor.New(
+			renderer,
+			module.NewDefaultRegistry(),
+			os.Stdout,
+			opts...,
+		).Run(plays)
+		renderer.Close()
+
+		if stats.Failed() {
+			os.Exit(2)
 		}
 	},
 }
@@ -72,9 +145,52 @@ func init() {
 	runCmd.PersistentFlags().
 		StringP("playbook", "p", "playbook.yaml", "Path to the Ansible playbook file to parse and run")
 
+	runCmd.PersistentFlags().
+		StringArrayP("inventory", "i", nil, "Inventory file, script, directory or comma separated host list (default: localhost)")
+	runCmd.PersistentFlags().
+		StringP("limit", "l", "", "Further limit the hosts of every play to a pattern or @file")
+	runCmd.PersistentFlags().
+		StringArrayP("extra-vars", "e", nil, "Set additional variables as key=value, YAML/JSON, or @file")
+	runCmd.PersistentFlags().
+		IntP("forks", "f", 5, "Number of hosts a task runs on at once")
+	runCmd.PersistentFlags().
+		String("strategy", "linear", "Strategy of plays that set none: linear, free or host_pinned")
+	runCmd.PersistentFlags().
+		BoolP("ask-become-pass", "K", false, "Ask for the privilege escalation password")
+	runCmd.PersistentFlags().
+		CountP("verbose", "v", "Increase verbosity (-vv for more), as used by debug verbosity")
+
 	_ = viper.BindPFlag("playbook", runCmd.PersistentFlags().Lookup("playbook"))
 	_ = viper.BindPFlag("roles-path", runCmd.PersistentFlags().Lookup("roles-path"))
+	_ = viper.BindPFlag("inventory", runCmd.PersistentFlags().Lookup("inventory"))
+	_ = viper.BindPFlag("limit", runCmd.PersistentFlags().Lookup("limit"))
+	_ = viper.BindPFlag("extra-vars", runCmd.PersistentFlags().Lookup("extra-vars"))
+	_ = viper.BindPFlag("forks", runCmd.PersistentFlags().Lookup("forks"))
+	_ = viper.BindPFlag("strategy", runCmd.PersistentFlags().Lookup("strategy"))
+	_ = viper.BindPFlag("ask-become-pass", runCmd.PersistentFlags().Lookup("ask-become-pass"))
+	_ = viper.BindPFlag("verbose", runCmd.PersistentFlags().Lookup("verbose"))
 
 	_ = runCmd.MarkPersistentFlagRequired("playbook")
 	_ = runCmd.MarkPersistentFlagRequired("roles-path")
 }
+
+// askBecomePassword prompts for the become password without echoing it, or
+// reads a line from stdin when it is not a terminal.
+func askBecomePassword() (string, error) {
+	_, _ = fmt.Fprint(os.Stderr, "BECOME password: ")
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		_, _ = fmt.Fprintln(os.Stderr)
+		return string(password), err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	_, _ = fmt.Fprintln(os.Stderr)
+	if err != nil && line == "" {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"strings"
+)
+
+// set applies a become keyword to b and reports whether key is one.
+func (b *Become) set(
+	key string,
+	v interface{},
+) bool {
+	switch key {
+	case "become":
+		if enabled, ok := ParseBool(v); ok {
+			b.Enabled = &enabled
+		}
+	case "become_user":
+		b.User = safeString(v)
+	case "become_method":
+		b.Method = safeString(v)
+	case "become_flags":
+		b.Flags = safeString(v)
+	case "become_exe":
+		b.Exe = safeString(v)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// ParseBool converts a YAML boolean, including the YAML 1.1 spellings such as
+// "yes" and "off" that yaml.v3 decodes as strings.
+func ParseBool(
+	v interface{},
+) (bool, bool) {
+	switch val := v.(type) {
+	case bool:
+		return val, true
+	case string:
+		switch strings.ToLower(val) {
+		case "yes", "y", "true", "on":
+			return true, true
+		case "no", "n", "false", "off":
+			return false, true
+		}
+	}
+
+	return false, false
+}
+
+// inheritBecome applies the become settings of a play to its tasks.
+func inheritBecome(
+	become Become,
+	tasks []Task,
+) []Task {
+	for i := range tasks {
+		task := &tasks[i]
+		task.Become = task.Become.inherit(become)
+		task.Block = inheritBecome(become, task.Block)
+		task.Rescue = inheritBecome(become, task.Rescue)
+		task.Always = inheritBecome(become, task.Always)
+	}
+
+	return tasks
+}
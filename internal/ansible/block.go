@@ -0,0 +1,136 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// parseBlock parses the block, rescue and always sections of a block task
+// and pushes the block's when, vars, tags and become down to its children.
+func parseBlock(
+	block Task,
+	taskMap map[string]interface{},
+	sourcePath string,
+	rolesPath string,
+) (Task, error) {
+	for k := range taskMap {
+		if !isTaskKeyword(k) {
+			return Task{}, fmt.Errorf("block cannot also invoke module %q", k)
+		}
+	}
+
+	if block.Loop != nil {
+		return Task{}, fmt.Errorf("blocks do not support loops")
+	}
+
+	sections := []struct {
+		key string
+		dst *[]Task
+	}{
+		{"block", &block.Block},
+		{"rescue", &block.Rescue},
+		{"always", &block.Always},
+	}
+	for _, section := range sections {
+		raw := taskMap[section.key]
+		if raw == nil {
+			continue
+		}
+
+		rawTasks, ok := raw.([]interface{})
+		if !ok {
+			return Task{}, fmt.Errorf("%s must be a list of tasks", section.key)
+		}
+
+		children, err := parseTasks(toTaskMaps(rawTasks), sourcePath, rolesPath)
+		if err != nil {
+			return Task{}, err
+		}
+
+		*section.dst = inherit(block, children)
+	}
+
+	if block.Block == nil {
+		block.Block = []Task{}
+	}
+
+	return block, nil
+}
+
+// inherit applies the when, vars, tags and become of parent to children and
+// to everything nested below them. Children keep precedence over the parent.
+func inherit(
+	parent Task,
+	children []Task,
+) []Task {
+	for i := range children {
+		child := &children[i]
+
+		child.When = append(slices.Clone(parent.When), child.When...)
+
+		vars := maps.Clone(parent.Vars)
+		if vars == nil {
+			vars = make(map[string]interface{})
+		}
+		maps.Copy(vars, child.Vars)
+		child.Vars = vars
+
+		for _, tag := range parent.Tags {
+			if !slices.Contains(child.Tags, tag) {
+				child.Tags = append(child.Tags, tag)
+			}
+		}
+
+		child.Become = child.Become.inherit(parent.Become)
+
+		child.Block = inherit(parent, child.Block)
+		child.Rescue = inherit(parent, child.Rescue)
+		child.Always = inherit(parent, child.Always)
+	}
+
+	return children
+}
+
+// inherit fills the unset fields of b from parent.
+func (b Become) inherit(
+	parent Become,
+) Become {
+	if b.Enabled == nil {
+		b.Enabled = parent.Enabled
+	}
+	if b.User == "" {
+		b.User = parent.User
+	}
+	if b.Method == "" {
+		b.Method = parent.Method
+	}
+	if b.Flags == "" {
+		b.Flags = parent.Flags
+	}
+	if b.Exe == "" {
+		b.Exe = parent.Exe
+	}
+
+	return b
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BlockTestSuite struct {
+	suite.Suite
+}
+
+func (s *BlockTestSuite) TestBecomeInherit() {
+	enabled := true
+	disabled := false
+
+	tests := []struct {
+		name     string
+		child    Become
+		parent   Become
+		expected Become
+	}{
+		{
+			name:     "unset child takes parent",
+			child:    Become{},
+			parent:   Become{Enabled: &enabled, User: "app", Method: "su", Flags: "-l", Exe: "/usr/bin/su"},
+			expected: Become{Enabled: &enabled, User: "app", Method: "su", Flags: "-l", Exe: "/usr/bin/su"},
+		},
+		{
+			name:     "child overrides parent",
+			child:    Become{Enabled: &disabled, User: "root"},
+			parent:   Become{Enabled: &enabled, User: "app", Method: "su"},
+			expected: Become{Enabled: &disabled, User: "root", Method: "su"},
+		},
+		{
+			name:     "both unset",
+			child:    Become{},
+			parent:   Become{},
+			expected: Become{},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, tc.child.inherit(tc.parent))
+		})
+	}
+}
+
+func TestBlockTestSuite(t *testing.T) {
+	suite.Run(t, new(BlockTestSuite))
+}
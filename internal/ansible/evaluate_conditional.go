@@ -0,0 +1,59 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+)
+
+// EvaluateConditional evaluates a bare Jinja2 expression, as used by `when:`,
+// against the provided context and reports whether it is truthy.
+func EvaluateConditional(
+	expr string,
+	context map[string]interface{},
+	renderer *jinja2.Jinja2,
+) (bool, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "{{") && strings.HasSuffix(expr, "}}") {
+		expr = strings.TrimSpace(expr[2 : len(expr)-2])
+	}
+
+	if expr == "" {
+		return true, nil
+	}
+
+	template := fmt.Sprintf("{%% if (%s) %%}True{%% else %%}False{%% endif %%}", expr)
+	vars, err := resolveVars(template, context, renderer)
+	if err != nil {
+		return false, err
+	}
+
+	// like Ansible, an undefined name is an error rather than false
+	rendered, err := renderString(template, vars, renderer, jinja2.WithStrict(true))
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate conditional %q: %w", expr, err)
+	}
+
+	return rendered == "True", nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible_test
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+type EvaluateConditionalPublicTestSuite struct {
+	suite.Suite
+
+	renderer *jinja2.Jinja2
+}
+
+func (s *EvaluateConditionalPublicTestSuite) SetupSuite() {
+	r, err := jinja2.NewJinja2("test-conditional", 1, jinja2.WithStrict(false))
+	s.Require().NoError(err)
+	s.renderer = r
+}
+
+func (s *EvaluateConditionalPublicTestSuite) TearDownSuite() {
+	s.renderer.Close()
+}
+
+func (s *EvaluateConditionalPublicTestSuite) TestEvaluateConditional() {
+	tests := []struct {
+		name              string
+		expr              string
+		vars              map[string]interface{}
+		expected          bool
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:     "true variable",
+			expr:     "enabled",
+			vars:     map[string]interface{}{"enabled": true},
+			expected: true,
+		},
+		{
+			name:     "false variable",
+			expr:     "enabled",
+			vars:     map[string]interface{}{"enabled": false},
+			expected: false,
+		},
+		{
+			name:     "comparison",
+			expr:     "threshold > 40",
+			vars:     map[string]interface{}{"threshold": 42},
+			expected: true,
+		},
+		{
+			name:     "is defined test on missing var",
+			expr:     "missing is defined",
+			vars:     map[string]interface{}{},
+			expected: false,
+		},
+		{
+			name:     "literal bool",
+			expr:     "false",
+			vars:     map[string]interface{}{},
+			expected: false,
+		},
+		{
+			name:     "wrapped in braces",
+			expr:     "{{ name == 'web' }}",
+			vars:     map[string]interface{}{"name": "web"},
+			expected: true,
+		},
+		{
+			name:     "empty expression",
+			expr:     "  ",
+			vars:     map[string]interface{}{},
+			expected: true,
+		},
+		{
+			name: "vars defined in terms of other vars",
+			expr: "is_prod",
+			vars: map[string]interface{}{
+				"is_prod": "{{ env == 'prod' }}",
+				"env":     "dev",
+			},
+			expected: false,
+		},
+		{
+			name:     "bool filter on a string",
+			expr:     "enabled | bool",
+			vars:     map[string]interface{}{"enabled": "Yes"},
+			expected: true,
+		},
+		{
+			name:     "bool filter on a false string",
+			expr:     "enabled | bool",
+			vars:     map[string]interface{}{"enabled": "off"},
+			expected: false,
+		},
+		{
+			name:              "undefined variable returns error",
+			expr:              "missing == 'x'",
+			vars:              map[string]interface{}{},
+			expectErr:         true,
+			expectErrContains: "'missing' is undefined",
+		},
+		{
+			name:              "invalid expression",
+			expr:              "foo ==",
+			vars:              map[string]interface{}{},
+			expectErr:         true,
+			expectErrContains: `failed to evaluate conditional "foo =="`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			got, err := ansible.EvaluateConditional(tc.expr, tc.vars, s.renderer)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, got)
+		})
+	}
+}
+
+func TestEvaluateConditionalPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(EvaluateConditionalPublicTestSuite))
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+)
+
+// EvaluateExpression evaluates a template that consists of a single Jinja2
+// expression, such as "{{ some_list }}", and returns its value with its
+// type preserved. Any other string is rendered as text.
+func EvaluateExpression(
+	value string,
+	context map[string]interface{},
+	renderer *jinja2.Jinja2,
+) (interface{}, error) {
+	vars, err := resolveVars(value, context, renderer)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateExpression(value, vars, renderer)
+}
+
+// evaluateExpression evaluates value against an already resolved context.
+func evaluateExpression(
+	value string,
+	context map[string]interface{},
+	renderer *jinja2.Jinja2,
+) (interface{}, error) {
+	expr, ok := singleExpression(value)
+	if !ok {
+		return renderString(value, context, renderer)
+	}
+
+	template := fmt.Sprintf("{{ (%s) | tojson }}", expr)
+	rendered, err := renderString(template, context, renderer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate expression %q: %w", expr, err)
+	}
+
+	var result interface{}
+	if err := json.Unmarshal([]byte(rendered), &result); err != nil {
+		return nil, fmt.Errorf("failed to decode expression %q: %w", expr, err)
+	}
+
+	return result, nil
+}
+
+// singleExpression returns the expression of a template made of exactly one
+// {{ }} block.
+func singleExpression(
+	value string,
+) (string, bool) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "{{") || !strings.HasSuffix(value, "}}") {
+		return "", false
+	}
+
+	expr := value[2 : len(value)-2]
+	if strings.Contains(expr, "{{") || strings.Contains(expr, "}}") {
+		return "", false
+	}
+
+	return strings.TrimSpace(expr), true
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible_test
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+type EvaluateExpressionPublicTestSuite struct {
+	suite.Suite
+
+	renderer *jinja2.Jinja2
+}
+
+func (s *EvaluateExpressionPublicTestSuite) SetupSuite() {
+	r, err := jinja2.NewJinja2("test-expression", 1, jinja2.WithStrict(false))
+	s.Require().NoError(err)
+	s.renderer = r
+}
+
+func (s *EvaluateExpressionPublicTestSuite) TearDownSuite() {
+	s.renderer.Close()
+}
+
+func (s *EvaluateExpressionPublicTestSuite) TestEvaluateExpression() {
+	tests := []struct {
+		name              string
+		value             string
+		vars              map[string]interface{}
+		expected          interface{}
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:     "list variable",
+			value:    "{{ items }}",
+			vars:     map[string]interface{}{"items": []interface{}{"a", "b"}},
+			expected: []interface{}{"a", "b"},
+		},
+		{
+			name:     "list literal",
+			value:    "{{ ['one', 2, true] }}",
+			vars:     map[string]interface{}{},
+			expected: []interface{}{"one", float64(2), true},
+		},
+		{
+			name:  "mapping variable",
+			value: "{{ users }}",
+			vars: map[string]interface{}{
+				"users": map[string]interface{}{"alice": map[string]interface{}{"uid": 1001}},
+			},
+			expected: map[string]interface{}{
+				"alice": map[string]interface{}{"uid": float64(1001)},
+			},
+		},
+		{
+			name:  "nested templates are rendered",
+			value: "{{ items }}",
+			vars: map[string]interface{}{
+				"items": []interface{}{"{{ name }}"},
+				"name":  "voidspan",
+			},
+			expected: []interface{}{"voidspan"},
+		},
+		{
+			name:     "text is rendered as a string",
+			value:    "{{ first }}-{{ last }}",
+			vars:     map[string]interface{}{"first": "void", "last": "span"},
+			expected: "void-span",
+		},
+		{
+			name:     "plain string",
+			value:    "files/*.conf",
+			vars:     map[string]interface{}{},
+			expected: "files/*.conf",
+		},
+		{
+			name:              "invalid expression",
+			value:             "{{ foo | does_not_exist }}",
+			vars:              map[string]interface{}{},
+			expectErr:         true,
+			expectErrContains: `failed to evaluate expression "foo | does_not_exist"`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			got, err := ansible.EvaluateExpression(tc.value, tc.vars, s.renderer)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, got)
+		})
+	}
+}
+
+func TestEvaluateExpressionPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(EvaluateExpressionPublicTestSuite))
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// freeFormModules are the modules whose string argument is a command line
+// that may carry options as key=value words, as in "ls -l chdir=/tmp".
+var freeFormModules = map[string]struct{}{
+	"command":                 {},
+	"shell":                   {},
+	"raw":                     {},
+	"script":                  {},
+	"ansible.builtin.command": {},
+	"ansible.builtin.shell":   {},
+	"ansible.builtin.raw":     {},
+	"ansible.builtin.script":  {},
+}
+
+// freeFormOptions are the options picked out of a free-form command line.
+// Any other key=value word is part of the command.
+var freeFormOptions = map[string]struct{}{
+	"chdir":             {},
+	"creates":           {},
+	"executable":        {},
+	"removes":           {},
+	"stdin":             {},
+	"stdin_add_newline": {},
+	"strip_empty_ends":  {},
+}
+
+// rawParamModules are the modules other than the free-form ones that take
+// words which are not key=value, as in "meta: flush_handlers".
+var rawParamModules = map[string]struct{}{
+	"meta":                 {},
+	"ansible.builtin.meta": {},
+}
+
+// argKey matches the keys of key=value words.
+var argKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// isFreeForm reports whether module takes a free-form command line.
+func isFreeForm(module string) bool {
+	_, ok := freeFormModules[module]
+	return ok
+}
+
+// takesRawParams reports whether module accepts words that are not
+// key=value in its string argument.
+func takesRawParams(module string) bool {
+	_, ok := rawParamModules[module]
+	return ok || isFreeForm(module)
+}
+
+// parseFreeForm splits a free-form command line into its options and the
+// command, stored under __value__. Words inside quotes or Jinja2 blocks
+// are never options, and the command keeps its original spacing and line
+// breaks.
+func parseFreeForm(
+	line string,
+) map[string]interface{} {
+	return parseWords(line, func(key string) bool {
+		_, ok := freeFormOptions[key]
+		return ok
+	})
+}
+
+// parseKeyValue splits the string argument of a module, as in
+// "copy: content=hi dest=/tmp/x", into its key=value args. The words that
+// are not key=value are kept under __value__.
+func parseKeyValue(
+	line string,
+) map[string]interface{} {
+	return parseWords(line, argKey.MatchString)
+}
+
+// parseWords picks the key=value words of line whose key isArg accepts
+// out as args and keeps the rest of line under __value__.
+func parseWords(
+	line string,
+	isArg func(key string) bool,
+) map[string]interface{} {
+	args := make(map[string]interface{})
+
+	var rest strings.Builder
+	last := 0
+	for _, span := range splitFreeForm(line) {
+		word := line[span[0]:span[1]]
+		key, value, ok := strings.Cut(word, "=")
+		if !ok || !isArg(key) {
+			continue
+		}
+
+		args[key] = unquote(value)
+		rest.WriteString(strings.TrimRightFunc(line[last:span[0]], unicode.IsSpace))
+		last = span[1]
+	}
+	rest.WriteString(line[last:])
+
+	if value := strings.TrimSpace(rest.String()); value != "" {
+		args["__value__"] = value
+	}
+
+	return args
+}
+
+// splitFreeForm returns the start and end offsets of the whitespace
+// separated words of line. Whitespace inside quotes, after a backslash or
+// inside {{ }}, {% %} and {# #} does not separate words.
+func splitFreeForm(
+	line string,
+) [][2]int {
+	var spans [][2]int
+	start := -1
+	var quote byte
+	depth := 0
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case depth > 0 && i+1 < len(line) && strings.Contains("}%#", string(c)) && line[i+1] == '}':
+			depth--
+			i++
+		case i+1 < len(line) && c == '{' && strings.Contains("{%#", string(line[i+1])):
+			if start < 0 {
+				start = i
+			}
+			depth++
+			i++
+		case depth > 0:
+		case c == '\\':
+			if start < 0 {
+				start = i
+			}
+			i++
+		case c == '"' || c == '\'':
+			if start < 0 {
+				start = i
+			}
+			quote = c
+		case unicode.IsSpace(rune(c)):
+			if start >= 0 {
+				spans = append(spans, [2]int{start, i})
+				start = -1
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(line)})
+	}
+
+	return spans
+}
+
+// unquote removes matching single or double quotes around s.
+func unquote(
+	s string,
+) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+
+	return s
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FreeFormTestSuite struct {
+	suite.Suite
+}
+
+func (s *FreeFormTestSuite) TestParseFreeForm() {
+	tests := []struct {
+		name     string
+		line     string
+		expected map[string]interface{}
+	}{
+		{
+			name: "command only",
+			line: "ls -l /tmp",
+			expected: map[string]interface{}{
+				"__value__": "ls -l /tmp",
+			},
+		},
+		{
+			name: "options are picked out anywhere",
+			line: "chdir=/tmp ls -l creates=/tmp/done removes='/tmp/a b'",
+			expected: map[string]interface{}{
+				"__value__": "ls -l",
+				"chdir":     "/tmp",
+				"creates":   "/tmp/done",
+				"removes":   "/tmp/a b",
+			},
+		},
+		{
+			name: "unknown keys stay in the command",
+			line: "env FOO=bar make target=all executable=/bin/bash",
+			expected: map[string]interface{}{
+				"__value__":  "env FOO=bar make target=all",
+				"executable": "/bin/bash",
+			},
+		},
+		{
+			name: "quoted options stay in the command",
+			line: `echo "chdir=/tmp" 'creates=x' chdir\=y`,
+			expected: map[string]interface{}{
+				"__value__": `echo "chdir=/tmp" 'creates=x' chdir\=y`,
+			},
+		},
+		{
+			name: "jinja blocks are single words",
+			line: "echo {{ 'chdir=/tmp' if a else b }} chdir={{ base_dir }}/app",
+			expected: map[string]interface{}{
+				"__value__": "echo {{ 'chdir=/tmp' if a else b }}",
+				"chdir":     "{{ base_dir }}/app",
+			},
+		},
+		{
+			name: "spacing and line breaks are kept",
+			line: "set -e\necho  'a  b' |\n  tr a-z A-Z\nchdir=/tmp\n",
+			expected: map[string]interface{}{
+				"__value__": "set -e\necho  'a  b' |\n  tr a-z A-Z",
+				"chdir":     "/tmp",
+			},
+		},
+		{
+			name: "options only",
+			line: "creates=/tmp/done",
+			expected: map[string]interface{}{
+				"creates": "/tmp/done",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, parseFreeForm(tc.line))
+		})
+	}
+}
+
+func (s *FreeFormTestSuite) TestParseKeyValue() {
+	tests := []struct {
+		name     string
+		line     string
+		expected map[string]interface{}
+	}{
+		{
+			name: "every key is an arg",
+			line: "path=/tmp/x state=directory mode=0755",
+			expected: map[string]interface{}{
+				"path":  "/tmp/x",
+				"state": "directory",
+				"mode":  "0755",
+			},
+		},
+		{
+			name: "quoted values and jinja blocks",
+			line: `msg="hello {{ name }}" var={{ result.stdout }}`,
+			expected: map[string]interface{}{
+				"msg": "hello {{ name }}",
+				"var": "{{ result.stdout }}",
+			},
+		},
+		{
+			name: "expressions as values",
+			line: "var=r.results[1].stdout",
+			expected: map[string]interface{}{
+				"var": "r.results[1].stdout",
+			},
+		},
+		{
+			name: "other words are raw params",
+			line: "flush_handlers",
+			expected: map[string]interface{}{
+				"__value__": "flush_handlers",
+			},
+		},
+		{
+			name: "keys must be identifiers",
+			line: `"a=b" 1x=y`,
+			expected: map[string]interface{}{
+				"__value__": `"a=b" 1x=y`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, parseKeyValue(tc.line))
+		})
+	}
+}
+
+func TestFreeFormTestSuite(t *testing.T) {
+	suite.Run(t, new(FreeFormTestSuite))
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// taskKeywords lists every key Ansible accepts on a task or handler that is
+// not a module name. See the "Task" section of Ansible's playbook keywords.
+var taskKeywords = map[string]struct{}{
+	"action":             {},
+	"always":             {},
+	"any_errors_fatal":   {},
+	"args":               {},
+	"async":              {},
+	"become":             {},
+	"become_exe":         {},
+	"become_flags":       {},
+	"become_method":      {},
+	"become_user":        {},
+	"block":              {},
+	"changed_when":       {},
+	"check_mode":         {},
+	"collections":        {},
+	"connection":         {},
+	"debugger":           {},
+	"delay":              {},
+	"delegate_facts":     {},
+	"delegate_to":        {},
+	"diff":               {},
+	"environment":        {},
+	"failed_when":        {},
+	"ignore_errors":      {},
+	"ignore_unreachable": {},
+	"listen":             {},
+	"local_action":       {},
+	"loop":               {},
+	"loop_control":       {},
+	"module_defaults":    {},
+	"name":               {},
+	"no_log":             {},
+	"notify":             {},
+	"poll":               {},
+	"port":               {},
+	"register":           {},
+	"remote_user":        {},
+	"rescue":             {},
+	"retries":            {},
+	"run_once":           {},
+	"tags":               {},
+	"throttle":           {},
+	"timeout":            {},
+	"until":              {},
+	"vars":               {},
+	"when":               {},
+}
+
+// isTaskKeyword reports whether key is a task keyword rather than a module.
+func isTaskKeyword(key string) bool {
+	if _, ok := taskKeywords[key]; ok {
+		return true
+	}
+
+	return strings.HasPrefix(key, "with_")
+}
+
+// findModule returns the single module key of a task and its value. The
+// `action` and `local_action` keywords are honored when no module key is
+// present.
+func findModule(
+	taskMap map[string]interface{},
+) (string, interface{}, error) {
+	var keys []string
+	for k := range taskMap {
+		if !isTaskKeyword(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range []string{"action", "local_action"} {
+		if v, ok := taskMap[k]; ok {
+			if len(keys) > 0 {
+				return "", nil, fmt.Errorf(
+					"conflicting action statements: %s, %s",
+					k,
+					strings.Join(keys, ", "),
+				)
+			}
+
+			return parseAction(k, v)
+		}
+	}
+
+	switch len(keys) {
+	case 0:
+		return "", nil, fmt.Errorf("no module/action detected in task")
+	case 1:
+		return keys[0], taskMap[keys[0]], nil
+	default:
+		return "", nil, fmt.Errorf(
+			"conflicting action statements: %s",
+			strings.Join(keys, ", "),
+		)
+	}
+}
+
+// parseAction splits an `action: module args` statement into the module
+// name and its args.
+func parseAction(
+	keyword string,
+	v interface{},
+) (string, interface{}, error) {
+	switch val := v.(type) {
+	case string:
+		fields := strings.SplitN(strings.TrimSpace(val), " ", 2)
+		if fields[0] == "" {
+			return "", nil, fmt.Errorf("%s is missing a module name", keyword)
+		}
+		if len(fields) == 1 {
+			return fields[0], map[string]interface{}{}, nil
+		}
+
+		return fields[0], strings.TrimSpace(fields[1]), nil
+	case map[string]interface{}:
+		name := safeString(val["module"])
+		if name == "" {
+			return "", nil, fmt.Errorf("%s is missing a module name", keyword)
+		}
+
+		args := make(map[string]interface{}, len(val))
+		for k, arg := range val {
+			if k != "module" {
+				args[k] = arg
+			}
+		}
+
+		return name, args, nil
+	default:
+		return "", nil, fmt.Errorf("%s must be a string or a mapping", keyword)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KeywordsTestSuite struct {
+	suite.Suite
+}
+
+func (s *KeywordsTestSuite) TestIsTaskKeyword() {
+	tests := []struct {
+		name     string
+		key      string
+		expected bool
+	}{
+		{name: "register", key: "register", expected: true},
+		{name: "when", key: "when", expected: true},
+		{name: "with lookup", key: "with_fileglob", expected: true},
+		{name: "short module", key: "debug", expected: false},
+		{name: "fqcn module", key: "ansible.builtin.debug", expected: false},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, isTaskKeyword(tc.key))
+		})
+	}
+}
+
+func (s *KeywordsTestSuite) TestFindModule() {
+	tests := []struct {
+		name              string
+		taskMap           map[string]interface{}
+		expectedName      string
+		expectedArgs      interface{}
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "single module",
+			taskMap: map[string]interface{}{
+				"name":     "t",
+				"register": "out",
+				"debug":    map[string]interface{}{"msg": "hi"},
+			},
+			expectedName: "debug",
+			expectedArgs: map[string]interface{}{"msg": "hi"},
+		},
+		{
+			name: "action string without args",
+			taskMap: map[string]interface{}{
+				"action": "ping",
+			},
+			expectedName: "ping",
+			expectedArgs: map[string]interface{}{},
+		},
+		{
+			name: "local_action string with args",
+			taskMap: map[string]interface{}{
+				"local_action": "command  echo hi",
+			},
+			expectedName: "command",
+			expectedArgs: "echo hi",
+		},
+		{
+			name: "action conflicts with module",
+			taskMap: map[string]interface{}{
+				"action": "ping",
+				"debug":  nil,
+			},
+			expectErr:         true,
+			expectErrContains: "conflicting action statements: action, debug",
+		},
+		{
+			name: "action mapping without module",
+			taskMap: map[string]interface{}{
+				"action": map[string]interface{}{"msg": "hi"},
+			},
+			expectErr:         true,
+			expectErrContains: "action is missing a module name",
+		},
+		{
+			name: "action with invalid type",
+			taskMap: map[string]interface{}{
+				"action": 42,
+			},
+			expectErr:         true,
+			expectErrContains: "action must be a string or a mapping",
+		},
+		{
+			name: "empty action",
+			taskMap: map[string]interface{}{
+				"action": " ",
+			},
+			expectErr:         true,
+			expectErrContains: "action is missing a module name",
+		},
+		{
+			name: "no module",
+			taskMap: map[string]interface{}{
+				"name": "t",
+			},
+			expectErr:         true,
+			expectErrContains: "no module/action detected in task",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			name, args, err := findModule(tc.taskMap)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expectedName, name)
+			s.Equal(tc.expectedArgs, args)
+		})
+	}
+}
+
+func TestKeywordsTestSuite(t *testing.T) {
+	suite.Run(t, new(KeywordsTestSuite))
+}
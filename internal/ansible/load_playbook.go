@@ -22,6 +22,8 @@ package ansible
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -38,46 +40,64 @@ func LoadPlaybook(
 	}
 
 	parsedPlays := make([]Play, 0, len(rawPlays))
-	for _, rawPlay := range rawPlays {
+	for i, rawPlay := range rawPlays {
 		play := Play{
-			Name:  safeString(rawPlay["name"]),
-			Hosts: safeString(rawPlay["hosts"]),
+			Name:     safeString(rawPlay["name"]),
+			Hosts:    strings.Join(toStringList(rawPlay["hosts"]), ","),
+			Vars:     make(map[string]interface{}),
+			Strategy: safeString(rawPlay["strategy"]),
 		}
 
-		rawTasks, ok := rawPlay["tasks"].([]interface{})
-		if !ok {
-			continue
+		if playVars, ok := rawPlay["vars"].(map[string]interface{}); ok {
+			play.Vars = playVars
+		}
+
+		for k, v := range rawPlay {
+			play.Become.set(k, v)
 		}
 
-		var rawTaskMaps []map[string]interface{}
-		for _, t := range rawTasks {
-			if tm, ok := t.(map[string]interface{}); ok {
-				rawTaskMaps = append(rawTaskMaps, tm)
+		if err := parsePlayLimits(&play, rawPlay); err != nil {
+			return nil, fmt.Errorf("%s: play %s: %w", playbookPath, taskLabel(play.Name, i), err)
+		}
+
+		if rawHandlers, ok := rawPlay["handlers"].([]interface{}); ok {
+			handlers, err := parseTasks(toTaskMaps(rawHandlers), playbookPath, rolesPath)
+			if err != nil {
+				return nil, err
 			}
+
+			play.Handlers = append(play.Handlers, handlers...)
 		}
 
-		parsedTasks, err := parseTasks(rawTaskMaps, playbookPath, rolesPath)
+		loader := newRoleLoader(rolesPath)
+
+		preTasks, err := loader.loadSection("pre_tasks", rawPlay["pre_tasks"], playbookPath)
 		if err != nil {
 			return nil, err
 		}
 
-		for _, task := range parsedTasks {
-			if task.Module == "include_role" || task.Module == "ansible.builtin.include_role" {
-				roleName := safeString(task.RawArgs["name"])
-				if roleName == "" {
-					return nil, fmt.Errorf("include_role task is missing 'name': %+v", task.RawArgs)
-				}
+		roleTasks, err := loader.loadPlayRoles(rawPlay["roles"], playbookPath)
+		if err != nil {
+			return nil, err
+		}
 
-				roleTasks, err := LoadRoleTasks(roleName, rolesPath)
-				if err != nil {
-					return nil, fmt.Errorf("failed to load role %q: %w", roleName, err)
-				}
+		tasks, err := loader.loadSection("tasks", rawPlay["tasks"], playbookPath)
+		if err != nil {
+			return nil, err
+		}
 
-				play.Tasks = append(play.Tasks, roleTasks...)
-				continue
-			}
+		postTasks, err := loader.loadSection("post_tasks", rawPlay["post_tasks"], playbookPath)
+		if err != nil {
+			return nil, err
+		}
+
+		play.PreTasks = inheritBecome(play.Become, preTasks)
+		play.Tasks = inheritBecome(play.Become, append(roleTasks, tasks...))
+		play.PostTasks = inheritBecome(play.Become, postTasks)
+		play.Handlers = inheritBecome(play.Become, append(play.Handlers, loader.handlers...))
 
-			play.Tasks = append(play.Tasks, task)
+		if err := validateNotify(play); err != nil {
+			return nil, err
 		}
 
 		parsedPlays = append(parsedPlays, play)
@@ -85,3 +105,67 @@ func LoadPlaybook(
 
 	return parsedPlays, nil
 }
+
+// parsePlayLimits parses the keywords controlling how many hosts of a play
+// run at once and how many may fail.
+func parsePlayLimits(
+	play *Play,
+	rawPlay map[string]interface{},
+) error {
+	serial, err := parseSerial(rawPlay["serial"])
+	if err != nil {
+		return err
+	}
+	play.Serial = serial
+
+	play.MaxFailPercentage, err = parseMaxFailPercentage(rawPlay["max_fail_percentage"])
+	if err != nil {
+		return err
+	}
+
+	if v, ok := rawPlay["any_errors_fatal"]; ok {
+		fatal, ok := ParseBool(v)
+		if !ok {
+			return fmt.Errorf("any_errors_fatal must be a boolean")
+		}
+		play.AnyErrorsFatal = fatal
+	}
+
+	return nil
+}
+
+// validateNotify ensures every static notify target of a play resolves to a
+// handler name or listen topic.
+func validateNotify(
+	play Play,
+) error {
+	known := make(map[string]struct{})
+	for _, h := range play.Handlers {
+		known[h.Name] = struct{}{}
+		for _, topic := range h.Listen {
+			known[topic] = struct{}{}
+		}
+	}
+
+	all := slices.Concat(play.PreTasks, play.Tasks, play.PostTasks, play.Handlers)
+
+	var err error
+	forEachTask(all, func(task Task) {
+		for _, name := range task.Notify {
+			if err != nil || strings.Contains(name, "{{") {
+				continue
+			}
+
+			if _, ok := known[name]; !ok {
+				err = fmt.Errorf(
+					"%s: task %q notifies unknown handler %q",
+					task.Source,
+					task.Name,
+					name,
+				)
+			}
+		}
+	})
+
+	return err
+}
@@ -76,7 +76,6 @@ func (s *LoadPlaybookPublicTestSuite) TestLoadPlaybook() {
 						"msg": "hello world",
 					},
 					Vars: map[string]interface{}{},
-					Loop: "",
 				}},
 			}},
 		},
@@ -128,7 +127,8 @@ func (s *LoadPlaybookPublicTestSuite) TestLoadPlaybook() {
   hosts: all
   tasks: this is not a list
 `,
-			expected: []ansible.Play{},
+			expectErr:         true,
+			expectErrContains: "tasks must be a list of tasks",
 		},
 		{
 			name: "parseTasks returns error (bad include_tasks)",
@@ -157,6 +157,21 @@ func (s *LoadPlaybookPublicTestSuite) TestLoadPlaybook() {
 			expectErr:         true,
 			expectErrContains: "include_role task is missing 'name'",
 		},
+		{
+			name: "include_role with a loop",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  tasks:
+    - name: include role per item
+      ansible.builtin.include_role:
+        name: not_a_real_role
+      with_items: [a, b]
+`,
+			expectErr:         true,
+			expectErrContains: `include_role "not_a_real_role" does not support loops`,
+		},
 		{
 			name: "include_role invalid role path",
 			playbookYAML: `
@@ -192,7 +207,6 @@ func (s *LoadPlaybookPublicTestSuite) TestLoadPlaybook() {
 						"msg": "from role",
 					},
 					Vars: map[string]interface{}{},
-					Loop: "",
 				}},
 			}},
 			prepare: func(dir string) {
@@ -206,6 +220,677 @@ func (s *LoadPlaybookPublicTestSuite) TestLoadPlaybook() {
 `), 0o644)
 			},
 		},
+		{
+			name: "play vars and include_role vars",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  vars:
+    env: prod
+  tasks:
+    - name: include real role
+      ansible.builtin.include_role:
+        name: myrole
+      vars:
+        items: [a, b]
+`,
+			expected: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Vars: map[string]interface{}{
+					"env": "prod",
+				},
+				Tasks: []ansible.Task{{
+					Name:   "role | main | test",
+					Module: "ansible.builtin.debug",
+					RawArgs: map[string]interface{}{
+						"msg": "from role",
+					},
+					Vars: map[string]interface{}{
+						"items": []interface{}{"a", "b"},
+					},
+					Role: &ansible.Role{
+						Name:     "myrole",
+						Defaults: map[string]interface{}{"port": 80},
+						Vars:     map[string]interface{}{},
+					},
+				}},
+			}},
+			prepare: func(dir string) {
+				roleDir := filepath.Join(dir, "roles", "myrole")
+				_ = os.MkdirAll(filepath.Join(roleDir, "tasks"), 0o755)
+				_ = os.MkdirAll(filepath.Join(roleDir, "defaults"), 0o755)
+				_ = os.WriteFile(filepath.Join(roleDir, "tasks", "main.yml"), []byte(`
+---
+- name: role | main | test
+  ansible.builtin.debug:
+    msg: "from role"
+`), 0o644)
+				_ = os.WriteFile(filepath.Join(roleDir, "defaults", "main.yml"), []byte(`
+---
+port: 80
+`), 0o644)
+			},
+		},
+		{
+			name: "play and role handlers",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  handlers:
+    - name: restart app
+      listen: app changed
+      ansible.builtin.debug:
+        msg: "restart"
+  tasks:
+    - name: change
+      ansible.builtin.debug:
+        msg: "change"
+      notify:
+        - app changed
+        - role handler
+    - name: include real role
+      ansible.builtin.include_role:
+        name: myrole
+`,
+			expected: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:    "change",
+						Module:  "ansible.builtin.debug",
+						RawArgs: map[string]interface{}{"msg": "change"},
+						Vars:    map[string]interface{}{},
+					},
+					{
+						Name:    "role | main | test",
+						Module:  "ansible.builtin.debug",
+						RawArgs: map[string]interface{}{"msg": "from role"},
+						Vars:    map[string]interface{}{},
+					},
+				},
+				Handlers: []ansible.Task{
+					{Name: "restart app"},
+					{Name: "role handler"},
+				},
+			}},
+			prepare: func(dir string) {
+				roleDir := filepath.Join(dir, "roles", "myrole")
+				_ = os.MkdirAll(filepath.Join(roleDir, "tasks"), 0o755)
+				_ = os.MkdirAll(filepath.Join(roleDir, "handlers"), 0o755)
+				_ = os.WriteFile(filepath.Join(roleDir, "tasks", "main.yml"), []byte(`
+---
+- name: role | main | test
+  ansible.builtin.debug:
+    msg: "from role"
+`), 0o644)
+				_ = os.WriteFile(filepath.Join(roleDir, "handlers", "main.yml"), []byte(`
+---
+- name: role handler
+  ansible.builtin.debug:
+    msg: "handled"
+`), 0o644)
+			},
+		},
+		{
+			name: "include_role inside a block",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  tasks:
+    - name: guarded
+      block:
+        - name: include real role
+          ansible.builtin.include_role:
+            name: myrole
+      rescue:
+        - name: include role that does not exist
+          ansible.builtin.include_role:
+            name: not_a_real_role
+`,
+			expectErr:         true,
+			expectErrContains: `failed to load role "not_a_real_role"`,
+			prepare: func(dir string) {
+				roleDir := filepath.Join(dir, "roles", "myrole", "tasks")
+				_ = os.MkdirAll(roleDir, 0o755)
+				_ = os.WriteFile(filepath.Join(roleDir, "main.yml"), []byte(`
+---
+- name: role | main | test
+  ansible.builtin.debug:
+    msg: "from role"
+`), 0o644)
+			},
+		},
+		{
+			name: "role dependencies run first and are deduplicated",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  tasks:
+    - name: include app
+      ansible.builtin.include_role:
+        name: app
+`,
+			expected: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:    "base",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+					},
+					{
+						Name:    "web",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{"port": 8080},
+					},
+					{
+						Name:    "logger",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+					},
+					{
+						Name:    "logger",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+					},
+					{
+						Name:    "app",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+					},
+				},
+			}},
+			prepare: func(dir string) {
+				writeRoles(dir, map[string]string{
+					"app/meta/main.yml": `
+dependencies:
+  - base
+  - role: web
+    vars:
+      port: 8080
+  - logger
+  - logger
+`,
+					"app/tasks/main.yml":    "- {name: app, debug: {}}",
+					"web/meta/main.yml":     "dependencies: [base]",
+					"web/tasks/main.yml":    "- {name: web, debug: {}}",
+					"base/tasks/main.yml":   "- {name: base, debug: {}}",
+					"logger/meta/main.yml":  "allow_duplicates: true",
+					"logger/tasks/main.yml": "- {name: logger, debug: {}}",
+				})
+			},
+		},
+		{
+			name: "role dependency cycle",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  tasks:
+    - name: include a
+      ansible.builtin.include_role:
+        name: a
+`,
+			expectErr:         true,
+			expectErrContains: "role dependency cycle detected: a -> b -> c -> a",
+			prepare: func(dir string) {
+				writeRoles(dir, map[string]string{
+					"a/meta/main.yml":  "dependencies: [b]",
+					"a/tasks/main.yml": "- {name: a, debug: {}}",
+					"b/meta/main.yml":  "dependencies: [c]",
+					"b/tasks/main.yml": "- {name: b, debug: {}}",
+					"c/meta/main.yml":  "dependencies: [a]",
+					"c/tasks/main.yml": "- {name: c, debug: {}}",
+				})
+			},
+		},
+		{
+			name: "invalid role meta",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  tasks:
+    - name: include a
+      ansible.builtin.include_role:
+        name: a
+`,
+			expectErr:         true,
+			expectErrContains: `failed to load role "a": failed to parse meta YAML`,
+			prepare: func(dir string) {
+				writeRoles(dir, map[string]string{
+					"a/meta/main.yml":  "dependencies: [",
+					"a/tasks/main.yml": "- {name: a, debug: {}}",
+				})
+			},
+		},
+		{
+			name: "missing role dependency",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  tasks:
+    - name: include a
+      ansible.builtin.include_role:
+        name: a
+`,
+			expectErr:         true,
+			expectErrContains: `failed to load role "missing"`,
+			prepare: func(dir string) {
+				writeRoles(dir, map[string]string{
+					"a/meta/main.yml":  "dependencies: [missing]",
+					"a/tasks/main.yml": "- {name: a, debug: {}}",
+				})
+			},
+		},
+		{
+			name: "play roles, pre_tasks and post_tasks",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  pre_tasks:
+    - name: pre
+      debug: {}
+  roles:
+    - base
+    - role: web
+      vars:
+        port: 8080
+      when: web_enabled
+      tags: [web]
+    - base
+  tasks:
+    - name: main
+      debug: {}
+  post_tasks:
+    - name: post
+      debug: {}
+  handlers:
+    - name: play handler
+      debug: {}
+`,
+			expected: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				PreTasks: []ansible.Task{{
+					Name:    "pre",
+					Module:  "debug",
+					RawArgs: map[string]interface{}{},
+					Vars:    map[string]interface{}{},
+				}},
+				Tasks: []ansible.Task{
+					{
+						Name:    "base",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+						Role:    &ansible.Role{Name: "base", Defaults: map[string]interface{}{}, Vars: map[string]interface{}{}},
+					},
+					{
+						Name:    "web",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{"port": 8080},
+						When:    []string{"web_enabled"},
+						Tags:    []string{"web"},
+					},
+					{
+						Name:    "main",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+					},
+				},
+				PostTasks: []ansible.Task{{
+					Name:    "post",
+					Module:  "debug",
+					RawArgs: map[string]interface{}{},
+					Vars:    map[string]interface{}{},
+				}},
+				Handlers: []ansible.Task{
+					{Name: "play handler"},
+					{Name: "restart base"},
+				},
+			}},
+			prepare: func(dir string) {
+				writeRoles(dir, map[string]string{
+					"base/tasks/main.yml":    "- {name: base, debug: {}}",
+					"base/handlers/main.yml": "- {name: restart base, debug: {}}",
+					"web/tasks/main.yml":     "- {name: web, debug: {}}",
+				})
+			},
+		},
+		{
+			name: "role included twice registers its handlers once",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  tasks:
+    - ansible.builtin.include_role:
+        name: nginx
+    - ansible.builtin.include_role:
+        name: nginx
+`,
+			expected: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:    "nginx",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+					},
+					{
+						Name:    "nginx",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+					},
+				},
+				Handlers: []ansible.Task{
+					{Name: "restart nginx"},
+				},
+			}},
+			prepare: func(dir string) {
+				writeRoles(dir, map[string]string{
+					"nginx/tasks/main.yml":    "- {name: nginx, debug: {}}",
+					"nginx/handlers/main.yml": "- {name: restart nginx, debug: {}}",
+				})
+			},
+		},
+		{
+			name: "include_role vars reach the role's handlers",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  tasks:
+    - ansible.builtin.include_role:
+        name: api
+      vars:
+        port: 8080
+`,
+			expected: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{{
+					Name:    "api",
+					Module:  "debug",
+					RawArgs: map[string]interface{}{},
+					Vars:    map[string]interface{}{"port": 8080},
+				}},
+				Handlers: []ansible.Task{{
+					Name: "restart api",
+					Vars: map[string]interface{}{"port": 8080, "signal": "HUP"},
+				}},
+			}},
+			prepare: func(dir string) {
+				writeRoles(dir, map[string]string{
+					"api/tasks/main.yml":    "- {name: api, debug: {}}",
+					"api/handlers/main.yml": "- {name: restart api, debug: {}, vars: {signal: HUP}}",
+				})
+			},
+		},
+		{
+			name: "play, role and task become",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  become: yes
+  become_method: su
+  roles:
+    - role: privileged
+      become_user: www
+  tasks:
+    - name: main
+      debug: {}
+    - name: unprivileged
+      become: false
+      debug: {}
+`,
+			expected: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Become: ansible.Become{
+					Enabled: &[]bool{true}[0],
+					Method:  "su",
+				},
+				Tasks: []ansible.Task{
+					{
+						Name:    "privileged",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+						Become: ansible.Become{
+							Enabled: &[]bool{true}[0],
+							User:    "www",
+							Method:  "su",
+						},
+					},
+					{
+						Name:    "main",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+						Become: ansible.Become{
+							Enabled: &[]bool{true}[0],
+							Method:  "su",
+						},
+					},
+					{
+						Name:    "unprivileged",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+						Become: ansible.Become{
+							Enabled: &[]bool{false}[0],
+							Method:  "su",
+						},
+					},
+				},
+			}},
+			prepare: func(dir string) {
+				writeRoles(dir, map[string]string{
+					"privileged/tasks/main.yml": "- {name: privileged, debug: {}}",
+				})
+			},
+		},
+		{
+			name: "roles-only play and empty play",
+			playbookYAML: `
+---
+- name: roles only
+  hosts: all
+  roles:
+    - common
+- name: empty
+  hosts: all
+`,
+			expected: []ansible.Play{
+				{
+					Name:  "roles only",
+					Hosts: "all",
+					Tasks: []ansible.Task{{
+						Name:    "common",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars:    map[string]interface{}{},
+					}},
+				},
+				{
+					Name:  "empty",
+					Hosts: "all",
+				},
+			},
+			prepare: func(dir string) {
+				writeRoles(dir, map[string]string{
+					"common/tasks/main.yml": "- {name: common, debug: {}}",
+				})
+			},
+		},
+		{
+			name: "roles not a list",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  roles: base
+`,
+			expectErr:         true,
+			expectErrContains: "roles must be a list",
+		},
+		{
+			name: "role entry without role",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  roles:
+    - vars:
+        port: 80
+`,
+			expectErr:         true,
+			expectErrContains: "role #1: entry is missing 'role'",
+		},
+		{
+			name: "pre_tasks not a list",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  pre_tasks:
+    name: pre
+`,
+			expectErr:         true,
+			expectErrContains: "pre_tasks must be a list of tasks",
+		},
+		{
+			name: "hosts as a list and strategy",
+			playbookYAML: `
+---
+- name: test play
+  hosts:
+    - web
+    - "!canary"
+  strategy: free
+`,
+			expected: []ansible.Play{{
+				Name:     "test play",
+				Hosts:    "web,!canary",
+				Strategy: "free",
+			}},
+		},
+		{
+			name: "serial and failure limits",
+			playbookYAML: `
+---
+- name: single size
+  hosts: all
+  serial: 2
+  max_fail_percentage: 25
+  any_errors_fatal: true
+- name: list of sizes
+  hosts: all
+  serial: [1, "30%", 0]
+  max_fail_percentage: "50%"
+`,
+			expected: []ansible.Play{
+				{
+					Name:              "single size",
+					Hosts:             "all",
+					Serial:            []ansible.BatchSize{{Value: 2}},
+					MaxFailPercentage: &[]int{25}[0],
+					AnyErrorsFatal:    true,
+				},
+				{
+					Name:  "list of sizes",
+					Hosts: "all",
+					Serial: []ansible.BatchSize{
+						{Value: 1},
+						{Value: 30, Percent: true},
+						{Value: 0},
+					},
+					MaxFailPercentage: &[]int{50}[0],
+				},
+			},
+		},
+		{
+			name: "invalid serial",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  serial: [1, half]
+`,
+			expectErr:         true,
+			expectErrContains: `play "test play": invalid serial "half": expected a host count or a percentage`,
+		},
+		{
+			name: "invalid max_fail_percentage",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  max_fail_percentage: 120
+`,
+			expectErr:         true,
+			expectErrContains: "invalid max_fail_percentage 120: expected a percentage",
+		},
+		{
+			name: "invalid any_errors_fatal",
+			playbookYAML: `
+---
+- hosts: all
+  any_errors_fatal: sometimes
+`,
+			expectErr:         true,
+			expectErrContains: "play #1: any_errors_fatal must be a boolean",
+		},
+		{
+			name: "notify unknown handler",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  tasks:
+    - name: change
+      ansible.builtin.debug:
+        msg: "change"
+      notify: nobody
+`,
+			expectErr:         true,
+			expectErrContains: `task "change" notifies unknown handler "nobody"`,
+		},
+		{
+			name: "invalid play handlers",
+			playbookYAML: `
+---
+- name: test play
+  hosts: all
+  handlers:
+    - name: broken
+  tasks: []
+`,
+			expectErr:         true,
+			expectErrContains: "no module/action detected in task",
+		},
 	}
 
 	for _, tc := range tests {
@@ -241,24 +926,72 @@ func (s *LoadPlaybookPublicTestSuite) TestLoadPlaybook() {
 			for i := range actual {
 				s.Equal(tc.expected[i].Name, actual[i].Name)
 				s.Equal(tc.expected[i].Hosts, actual[i].Hosts)
-				s.Equal(len(tc.expected[i].Tasks), len(actual[i].Tasks))
-
-				for j := range actual[i].Tasks {
-					exp := tc.expected[i].Tasks[j]
-					act := actual[i].Tasks[j]
+				s.Equal(tc.expected[i].Become, actual[i].Become)
+				s.Equal(tc.expected[i].Strategy, actual[i].Strategy)
+				s.Equal(tc.expected[i].Serial, actual[i].Serial)
+				s.Equal(tc.expected[i].MaxFailPercentage, actual[i].MaxFailPercentage)
+				s.Equal(tc.expected[i].AnyErrorsFatal, actual[i].AnyErrorsFatal)
+				if tc.expected[i].Vars != nil {
+					s.Equal(tc.expected[i].Vars, actual[i].Vars)
+				}
+				s.assertTasks(tc.expected[i].PreTasks, actual[i].PreTasks)
+				s.assertTasks(tc.expected[i].Tasks, actual[i].Tasks)
+				s.assertTasks(tc.expected[i].PostTasks, actual[i].PostTasks)
+				s.Equal(len(tc.expected[i].Handlers), len(actual[i].Handlers))
 
-					s.Equal(exp.Name, act.Name)
-					s.Equal(exp.Module, act.Module)
-					s.Equal(exp.Loop, act.Loop)
-					s.Equal(exp.RawArgs, act.RawArgs)
-					s.Equal(exp.Vars, act.Vars)
-					s.NotEmpty(act.Source)
+				for j := range actual[i].Handlers {
+					s.Equal(tc.expected[i].Handlers[j].Name, actual[i].Handlers[j].Name)
+					if tc.expected[i].Handlers[j].Vars != nil {
+						s.Equal(tc.expected[i].Handlers[j].Vars, actual[i].Handlers[j].Vars)
+					}
 				}
 			}
 		})
 	}
 }
 
+func (s *LoadPlaybookPublicTestSuite) assertTasks(
+	expected []ansible.Task,
+	actual []ansible.Task,
+) {
+	s.Require().Equal(len(expected), len(actual))
+
+	for j := range actual {
+		exp := expected[j]
+		act := actual[j]
+
+		s.Equal(exp.Name, act.Name)
+		s.Equal(exp.Module, act.Module)
+		s.Equal(exp.Loop, act.Loop)
+		s.Equal(exp.RawArgs, act.RawArgs)
+		s.Equal(exp.Vars, act.Vars)
+		s.Equal(exp.Become, act.Become)
+		s.NotEmpty(act.Source)
+		if exp.When != nil {
+			s.Equal(exp.When, act.When)
+		}
+		if exp.Tags != nil {
+			s.Equal(exp.Tags, act.Tags)
+		}
+		if exp.Role != nil {
+			s.Require().NotNil(act.Role)
+			s.Equal(exp.Role.Name, act.Role.Name)
+			s.Equal(exp.Role.Defaults, act.Role.Defaults)
+			s.Equal(exp.Role.Vars, act.Role.Vars)
+		}
+	}
+}
+
+// writeRoles writes files, keyed by their path relative to the roles
+// directory, below dir/roles.
+func writeRoles(dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, "roles", name)
+		_ = os.MkdirAll(filepath.Dir(path), 0o755)
+		_ = os.WriteFile(path, []byte(content), 0o644)
+	}
+}
+
 func TestLoadPlaybookPublicTestSuite(t *testing.T) {
 	suite.Run(t, new(LoadPlaybookPublicTestSuite))
 }
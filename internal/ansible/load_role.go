@@ -0,0 +1,95 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadRole loads a role's defaults/main.yml and vars/main.yml. Both files are
+// optional.
+func LoadRole(
+	roleName string,
+	rolesPath string,
+) (*Role, error) {
+	roleDir := filepath.Join(rolesPath, roleName)
+
+	defaults, err := loadVarsFile(filepath.Join(roleDir, "defaults", "main.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load role defaults: %w", err)
+	}
+
+	vars, err := loadVarsFile(filepath.Join(roleDir, "vars", "main.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load role vars: %w", err)
+	}
+
+	return &Role{
+		Name:     roleName,
+		Path:     roleDir,
+		Defaults: defaults,
+		Vars:     vars,
+	}, nil
+}
+
+// loadVarsFile reads a YAML mapping of variables. A missing or empty file
+// yields an empty mapping.
+func loadVarsFile(
+	path string,
+) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return vars, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, &vars); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if vars == nil {
+		vars = make(map[string]interface{})
+	}
+
+	return vars, nil
+}
+
+// setRole attaches role to every task, descending into blocks.
+func setRole(
+	tasks []Task,
+	role *Role,
+) {
+	for i := range tasks {
+		tasks[i].Role = role
+		setRole(tasks[i].Block, role)
+		setRole(tasks[i].Rescue, role)
+		setRole(tasks[i].Always, role)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadRoleHandlers loads handlers from a given role's handlers/main.yml file
+// and attaches the role's defaults and vars to them. A role without a
+// handlers file has no handlers.
+func LoadRoleHandlers(
+	roleName string,
+	rolesPath string,
+) ([]Task, error) {
+	role, err := LoadRole(roleName, rolesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	roleDir := filepath.Join(rolesPath, roleName)
+	handlersPath := filepath.Join(roleDir, "handlers", "main.yml")
+
+	data, err := os.ReadFile(handlersPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read role handlers: %w", err)
+	}
+
+	var rawHandlers []map[string]interface{}
+	if err := yaml.Unmarshal(data, &rawHandlers); err != nil {
+		return nil, fmt.Errorf("failed to parse handlers YAML: %w", err)
+	}
+
+	handlers, err := parseTasks(rawHandlers, handlersPath, rolesPath)
+	if err != nil {
+		return nil, err
+	}
+	setRole(handlers, role)
+
+	return handlers, nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadRoleMeta loads a role's meta/main.yml. A role without a meta file has
+// no dependencies.
+func LoadRoleMeta(
+	roleName string,
+	rolesPath string,
+) (*RoleMeta, error) {
+	metaPath := filepath.Join(rolesPath, roleName, "meta", "main.yml")
+
+	data, err := os.ReadFile(metaPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &RoleMeta{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read role meta: %w", err)
+	}
+
+	var rawMeta map[string]interface{}
+	if err := yaml.Unmarshal(data, &rawMeta); err != nil {
+		return nil, fmt.Errorf("failed to parse meta YAML: %w", err)
+	}
+
+	meta := &RoleMeta{}
+	if allow, ok := rawMeta["allow_duplicates"].(bool); ok {
+		meta.AllowDuplicates = allow
+	}
+
+	rawDeps, _ := rawMeta["dependencies"].([]interface{})
+	for i, rawDep := range rawDeps {
+		dep, err := parseRoleDependency(rawDep)
+		if err != nil {
+			return nil, fmt.Errorf("%s: dependency #%d: %w", metaPath, i+1, err)
+		}
+
+		meta.Dependencies = append(meta.Dependencies, dep)
+	}
+
+	return meta, nil
+}
+
+// parseRoleDependency parses a role dependency or an entry of a play's roles
+// list, given either as a role name or as a mapping with a role (or name)
+// key. Keys that are not task keywords are legacy role parameters and are
+// treated as vars.
+func parseRoleDependency(
+	rawDep interface{},
+) (RoleDependency, error) {
+	switch val := rawDep.(type) {
+	case string:
+		return RoleDependency{
+			Name: val,
+			Vars: map[string]interface{}{},
+		}, nil
+	case map[string]interface{}:
+		dep := RoleDependency{
+			Vars: map[string]interface{}{},
+		}
+
+		for k, v := range val {
+			switch k {
+			case "role", "name":
+				dep.Name = safeString(v)
+			case "vars":
+				if varMap, ok := v.(map[string]interface{}); ok {
+					maps.Copy(dep.Vars, varMap)
+				}
+			case "when":
+				dep.When = toStringList(v)
+			case "tags":
+				dep.Tags = toStringList(v)
+			default:
+				if !dep.Become.set(k, v) && !isTaskKeyword(k) {
+					dep.Vars[k] = v
+				}
+			}
+		}
+
+		if dep.Name == "" {
+			return RoleDependency{}, fmt.Errorf("entry is missing 'role'")
+		}
+
+		return dep, nil
+	default:
+		return RoleDependency{}, fmt.Errorf("entry must be a role name or a mapping")
+	}
+}
+
+// task returns a task carrying the vars, when, tags and become of the
+// dependency, to be inherited by the tasks of the role.
+func (d RoleDependency) task() Task {
+	return Task{
+		Vars:   d.Vars,
+		When:   d.When,
+		Tags:   d.Tags,
+		Become: d.Become,
+	}
+}
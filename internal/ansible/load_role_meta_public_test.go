@@ -0,0 +1,174 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+type LoadRoleMetaPublicTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+}
+
+func (s *LoadRoleMetaPublicTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-role-meta-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+}
+
+func (s *LoadRoleMetaPublicTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func (s *LoadRoleMetaPublicTestSuite) TestLoadRoleMeta() {
+	tests := []struct {
+		name              string
+		roleName          string
+		metaYAML          string
+		expected          *ansible.RoleMeta
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:     "dependencies in every form",
+			roleName: "app",
+			metaYAML: `
+---
+galaxy_info:
+  author: someone
+allow_duplicates: true
+dependencies:
+  - base
+  - role: web
+    vars:
+      port: 8080
+    when: web_enabled
+    tags: [web]
+  - name: legacy
+    http_port: 9090
+    become: true
+`,
+			expected: &ansible.RoleMeta{
+				AllowDuplicates: true,
+				Dependencies: []ansible.RoleDependency{
+					{
+						Name: "base",
+						Vars: map[string]interface{}{},
+					},
+					{
+						Name: "web",
+						Vars: map[string]interface{}{"port": 8080},
+						When: []string{"web_enabled"},
+						Tags: []string{"web"},
+					},
+					{
+						Name:   "legacy",
+						Vars:   map[string]interface{}{"http_port": 9090},
+						Become: ansible.Become{Enabled: &[]bool{true}[0]},
+					},
+				},
+			},
+		},
+		{
+			name:     "empty dependencies",
+			roleName: "empty",
+			metaYAML: `
+dependencies: []
+  # List your role dependencies here
+`,
+			expected: &ansible.RoleMeta{},
+		},
+		{
+			name:     "missing meta",
+			roleName: "missing",
+			expected: &ansible.RoleMeta{},
+		},
+		{
+			name:     "dependency without role",
+			roleName: "norole",
+			metaYAML: `
+dependencies:
+  - vars:
+      port: 80
+`,
+			expectErr:         true,
+			expectErrContains: "dependency #1: entry is missing 'role'",
+		},
+		{
+			name:     "dependency of invalid type",
+			roleName: "badtype",
+			metaYAML: `
+dependencies:
+  - [nested]
+`,
+			expectErr:         true,
+			expectErrContains: "entry must be a role name or a mapping",
+		},
+		{
+			name:     "invalid YAML",
+			roleName: "badyaml",
+			metaYAML: `
+dependencies:
+  - base
+ broken: [
+`,
+			expectErr:         true,
+			expectErrContains: "failed to parse meta YAML",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			if tc.metaYAML != "" {
+				metaDir := filepath.Join(s.tmpDir, tc.roleName, "meta")
+				s.Require().NoError(os.MkdirAll(metaDir, 0o755))
+				s.Require().NoError(
+					os.WriteFile(filepath.Join(metaDir, "main.yml"), []byte(tc.metaYAML), 0o644),
+				)
+			}
+
+			meta, err := ansible.LoadRoleMeta(tc.roleName, s.tmpDir)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, meta)
+		})
+	}
+}
+
+func TestLoadRoleMetaPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(LoadRoleMetaPublicTestSuite))
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+type LoadRolePublicTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+}
+
+func (s *LoadRolePublicTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-role-vars-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+}
+
+func (s *LoadRolePublicTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func (s *LoadRolePublicTestSuite) TestLoadRole() {
+	tests := []struct {
+		name              string
+		roleName          string
+		expectedDefaults  map[string]interface{}
+		expectedVars      map[string]interface{}
+		expectErr         bool
+		expectErrContains string
+		prepare           func(roleDir string)
+	}{
+		{
+			name:     "defaults and vars",
+			roleName: "role1",
+			expectedDefaults: map[string]interface{}{
+				"port": 80,
+			},
+			expectedVars: map[string]interface{}{
+				"service": "nginx",
+			},
+			prepare: func(roleDir string) {
+				_ = os.MkdirAll(filepath.Join(roleDir, "defaults"), 0o755)
+				_ = os.MkdirAll(filepath.Join(roleDir, "vars"), 0o755)
+				_ = os.WriteFile(
+					filepath.Join(roleDir, "defaults", "main.yml"),
+					[]byte("---\nport: 80\n"),
+					0o644,
+				)
+				_ = os.WriteFile(
+					filepath.Join(roleDir, "vars", "main.yml"),
+					[]byte("---\nservice: nginx\n"),
+					0o644,
+				)
+			},
+		},
+		{
+			name:             "comment only files",
+			roleName:         "comments",
+			expectedDefaults: map[string]interface{}{},
+			expectedVars:     map[string]interface{}{},
+			prepare: func(roleDir string) {
+				_ = os.MkdirAll(filepath.Join(roleDir, "defaults"), 0o755)
+				_ = os.WriteFile(
+					filepath.Join(roleDir, "defaults", "main.yml"),
+					[]byte("---\n# defaults file\n"),
+					0o644,
+				)
+			},
+		},
+		{
+			name:             "missing files",
+			roleName:         "missing",
+			expectedDefaults: map[string]interface{}{},
+			expectedVars:     map[string]interface{}{},
+		},
+		{
+			name:              "invalid defaults",
+			roleName:          "baddefaults",
+			expectErr:         true,
+			expectErrContains: "failed to load role defaults",
+			prepare: func(roleDir string) {
+				_ = os.MkdirAll(filepath.Join(roleDir, "defaults"), 0o755)
+				_ = os.WriteFile(
+					filepath.Join(roleDir, "defaults", "main.yml"),
+					[]byte("- not\n- a mapping\n"),
+					0o644,
+				)
+			},
+		},
+		{
+			name:              "unreadable vars",
+			roleName:          "badvars",
+			expectErr:         true,
+			expectErrContains: "failed to load role vars",
+			prepare: func(roleDir string) {
+				_ = os.MkdirAll(filepath.Join(roleDir, "vars", "main.yml"), 0o755)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			roleDir := filepath.Join(s.tmpDir, tc.roleName)
+			if tc.prepare != nil {
+				tc.prepare(roleDir)
+			}
+
+			role, err := ansible.LoadRole(tc.roleName, s.tmpDir)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.roleName, role.Name)
+			s.Equal(roleDir, role.Path)
+			s.Equal(tc.expectedDefaults, role.Defaults)
+			s.Equal(tc.expectedVars, role.Vars)
+		})
+	}
+}
+
+func TestLoadRolePublicTestSuite(t *testing.T) {
+	suite.Run(t, new(LoadRolePublicTestSuite))
+}
@@ -28,11 +28,17 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// LoadRoleTasks loads tasks from a given role's tasks/main.yml file
+// LoadRoleTasks loads tasks from a given role's tasks/main.yml file and
+// attaches the role's defaults and vars to them.
 func LoadRoleTasks(
 	roleName string,
 	rolesPath string,
 ) ([]Task, error) {
+	role, err := LoadRole(roleName, rolesPath)
+	if err != nil {
+		return nil, err
+	}
+
 	roleDir := filepath.Join(rolesPath, roleName)
 	tasksPath := filepath.Join(roleDir, "tasks", "main.yml")
 
@@ -46,5 +52,11 @@ func LoadRoleTasks(
 		return nil, fmt.Errorf("failed to parse tasks YAML: %w", err)
 	}
 
-	return parseTasks(rawTasks, tasksPath, rolesPath)
+	tasks, err := parseTasks(rawTasks, tasksPath, rolesPath)
+	if err != nil {
+		return nil, err
+	}
+	setRole(tasks, role)
+
+	return tasks, nil
 }
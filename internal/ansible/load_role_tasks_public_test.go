@@ -65,7 +65,6 @@ func (s *LoadRoleTasksPublicTestSuite) TestLoadRoleTasks() {
 					"msg": "hi from role1",
 				},
 				Vars: map[string]interface{}{},
-				Loop: "",
 			}},
 			prepare: func(roleDir string) {
 				tasksDir := filepath.Join(roleDir, "tasks")
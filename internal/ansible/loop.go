@@ -0,0 +1,106 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// parseLoop returns the loop of a task and, for with_* loops, the lookup
+// that produces its items. A task may loop at most once.
+func parseLoop(
+	taskMap map[string]interface{},
+) (interface{}, string, error) {
+	var keys []string
+	for k := range taskMap {
+		if k == "loop" || strings.HasPrefix(k, "with_") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	switch len(keys) {
+	case 0:
+		return nil, "", nil
+	case 1:
+		key := keys[0]
+		if key != "loop" {
+			return taskMap[key], strings.TrimPrefix(key, "with_"), nil
+		}
+
+		switch loop := taskMap[key].(type) {
+		case string, []interface{}:
+			return loop, "", nil
+		default:
+			return nil, "", fmt.Errorf("loop must be a template or a list, got %T", loop)
+		}
+	default:
+		return nil, "", fmt.Errorf("duplicate loop in task: %s", strings.Join(keys, ", "))
+	}
+}
+
+// parseLoopControl parses the loop_control keyword of a task.
+func parseLoopControl(
+	raw interface{},
+) (LoopControl, error) {
+	var lc LoopControl
+	if raw == nil {
+		return lc, nil
+	}
+
+	rawMap, ok := raw.(map[string]interface{})
+	if !ok {
+		return lc, fmt.Errorf("loop_control must be a mapping")
+	}
+
+	for k, v := range rawMap {
+		switch k {
+		case "loop_var":
+			lc.LoopVar = safeString(v)
+		case "index_var":
+			lc.IndexVar = safeString(v)
+		case "label":
+			lc.Label = fmt.Sprint(v)
+		case "pause":
+			switch n := v.(type) {
+			case int:
+				lc.Pause = time.Duration(n) * time.Second
+			case float64:
+				lc.Pause = time.Duration(n * float64(time.Second))
+			default:
+				return lc, fmt.Errorf("loop_control pause must be a number of seconds")
+			}
+		case "extended":
+			extended, ok := v.(bool)
+			if !ok {
+				return lc, fmt.Errorf("loop_control extended must be a boolean")
+			}
+			lc.Extended = extended
+		default:
+			return lc, fmt.Errorf("unsupported loop_control option %q", k)
+		}
+	}
+
+	return lc, nil
+}
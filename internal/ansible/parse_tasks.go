@@ -37,7 +37,7 @@ func parseTasks(
 	tasks := make([]Task, 0, len(rawTasks))
 	baseDir := filepath.Dir(sourcePath)
 
-	for _, taskMap := range rawTasks {
+	for i, taskMap := range rawTasks {
 		task := Task{
 			Name:    safeString(taskMap["name"]),
 			Vars:    make(map[string]interface{}),
@@ -47,63 +47,143 @@ func parseTasks(
 
 		for k, v := range taskMap {
 			switch k {
-			case "name":
-				// already handled
 			case "vars":
 				if varMap, ok := v.(map[string]interface{}); ok {
 					task.Vars = varMap
 				}
-			case "loop":
-				if loopStr, ok := v.(string); ok {
-					task.Loop = loopStr
-				}
-			case "include_tasks", "ansible.builtin.include_tasks":
-				includePath, ok := v.(string)
-				if !ok {
-					return nil, fmt.Errorf("include_tasks path must be a string")
-				}
+			case "when":
+				task.When = toStringList(v)
+			case "notify":
+				task.Notify = toStringList(v)
+			case "listen":
+				task.Listen = toStringList(v)
+			case "tags":
+				task.Tags = toStringList(v)
+			case "register":
+				task.Register = safeString(v)
+			default:
+				task.Become.set(k, v)
+			}
+		}
 
-				fullPath := filepath.Join(baseDir, includePath)
-				data, err := os.ReadFile(fullPath)
-				if err != nil {
-					return nil, fmt.Errorf(
-						"failed to read included task file %s: %w",
-						fullPath,
-						err,
-					)
+		loop, lookup, err := parseLoop(taskMap)
+		if err != nil {
+			return nil, fmt.Errorf("%s: task %s: %w", sourcePath, taskLabel(task.Name, i), err)
+		}
+		task.Loop = loop
+		task.LoopWith = lookup
+
+		task.LoopControl, err = parseLoopControl(taskMap["loop_control"])
+		if err != nil {
+			return nil, fmt.Errorf("%s: task %s: %w", sourcePath, taskLabel(task.Name, i), err)
+		}
+
+		if _, ok := taskMap["block"]; ok {
+			block, err := parseBlock(task, taskMap, sourcePath, rolesPath)
+			if err != nil {
+				return nil, fmt.Errorf("%s: task %s: %w", sourcePath, taskLabel(task.Name, i), err)
+			}
+
+			tasks = append(tasks, block)
+			continue
+		}
+
+		moduleName, moduleArgs, err := findModule(taskMap)
+		if err != nil {
+			return nil, fmt.Errorf("%s: task %s: %w", sourcePath, taskLabel(task.Name, i), err)
+		}
+
+		switch moduleName {
+		case "include_tasks", "ansible.builtin.include_tasks",
+			"import_tasks", "ansible.builtin.import_tasks":
+			// included tasks are expanded while parsing, before any loop
+			// could be rendered
+			if task.Loop != nil {
+				return nil, fmt.Errorf(
+					"%s: task %s: %s does not support loops",
+					sourcePath,
+					taskLabel(task.Name, i),
+					moduleName,
+				)
+			}
+
+			includePath, ok := moduleArgs.(string)
+			if !ok {
+				return nil, fmt.Errorf("include_tasks path must be a string")
+			}
+
+			fullPath := filepath.Join(baseDir, includePath)
+			data, err := os.ReadFile(fullPath)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"failed to read included task file %s: %w",
+					fullPath,
+					err,
+				)
+			}
+
+			var includedRaw []map[string]interface{}
+			if err := yaml.Unmarshal(data, &includedRaw); err != nil {
+				return nil, fmt.Errorf(
+					"failed to parse included task file %s: %w",
+					fullPath,
+					err,
+				)
+			}
+
+			includedTasks, err := parseTasks(includedRaw, fullPath, rolesPath)
+			if err != nil {
+				return nil, err
+			}
+
+			tasks = append(tasks, inherit(task, includedTasks)...)
+			continue // skip appending this include as a normal task
+		default:
+			task.Module = moduleName
+			switch val := moduleArgs.(type) {
+			case map[string]interface{}:
+				task.RawArgs = val
+			case string:
+				if isFreeForm(moduleName) {
+					task.RawArgs = parseFreeForm(val)
+					break
 				}
 
-				var includedRaw []map[string]interface{}
-				if err := yaml.Unmarshal(data, &includedRaw); err != nil {
+				task.RawArgs = parseKeyValue(val)
+				if _, ok := task.RawArgs["__value__"]; ok && !takesRawParams(moduleName) {
 					return nil, fmt.Errorf(
-						"failed to parse included task file %s: %w",
-						fullPath,
-						err,
+						"%s: task %s: module %s takes key=value args, got %q",
+						sourcePath, taskLabel(task.Name, i), moduleName, val,
 					)
 				}
-
-				includedTasks, err := parseTasks(includedRaw, fullPath, rolesPath)
-				if err != nil {
-					return nil, err
-				}
-
-				tasks = append(tasks, includedTasks...)
-				goto nextTask // skip appending this include as a normal task
+			case nil:
+				// a module given no value, like "ping:", has no args but
+				// those of the args keyword
 			default:
-				task.Module = k
-				switch val := v.(type) {
-				case map[string]interface{}:
-					task.RawArgs = val
-				default:
-					task.RawArgs = map[string]interface{}{"__value__": val}
+				task.RawArgs = map[string]interface{}{"__value__": val}
+			}
+
+			if extra, ok := taskMap["args"].(map[string]interface{}); ok {
+				for k, v := range extra {
+					if _, set := task.RawArgs[k]; !set {
+						task.RawArgs[k] = v
+					}
 				}
 			}
 		}
 
 		tasks = append(tasks, task)
-
-	nextTask:
 	}
 
 	return tasks, nil
 }
+
+// taskLabel identifies a task in error messages by name, or by position
+// when it is unnamed.
+func taskLabel(name string, index int) string {
+	if name != "" {
+		return fmt.Sprintf("%q", name)
+	}
+
+	return fmt.Sprintf("#%d", index+1)
+}
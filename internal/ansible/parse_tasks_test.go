@@ -24,6 +24,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"gopkg.in/yaml.v3"
@@ -68,7 +69,6 @@ func (s *ParseTasksTestSuite) TestParseTasks() {
 					"msg": "hello",
 				},
 				Vars: map[string]interface{}{},
-				Loop: "",
 			}},
 		},
 		{
@@ -89,7 +89,6 @@ func (s *ParseTasksTestSuite) TestParseTasks() {
 				Vars: map[string]interface{}{
 					"message": "hello",
 				},
-				Loop: "",
 			}},
 		},
 		{
@@ -110,6 +109,502 @@ func (s *ParseTasksTestSuite) TestParseTasks() {
 				Loop: "{{ ['a', 'b'] }}",
 			}},
 		},
+		{
+			name: "task with single when",
+			taskYAML: `
+- name: gated
+  when: enabled | bool
+  ansible.builtin.debug:
+    msg: "on"
+`,
+			expected: []Task{{
+				Name:   "gated",
+				Module: "ansible.builtin.debug",
+				RawArgs: map[string]interface{}{
+					"msg": "on",
+				},
+				Vars: map[string]interface{}{},
+				When: []string{"enabled | bool"},
+			}},
+		},
+		{
+			name: "task with when list",
+			taskYAML: `
+- name: gated
+  ansible.builtin.debug:
+    msg: "on"
+  when:
+    - foo is defined
+    - foo > 1
+`,
+			expected: []Task{{
+				Name:   "gated",
+				Module: "ansible.builtin.debug",
+				RawArgs: map[string]interface{}{
+					"msg": "on",
+				},
+				Vars: map[string]interface{}{},
+				When: []string{"foo is defined", "foo > 1"},
+			}},
+		},
+		{
+			name: "notify and listen",
+			taskYAML: `
+- name: restart app
+  listen: [web services]
+  notify: reload proxy
+  ansible.builtin.debug:
+    msg: restarting
+`,
+			expected: []Task{{
+				Name:   "restart app",
+				Module: "ansible.builtin.debug",
+				RawArgs: map[string]interface{}{
+					"msg": "restarting",
+				},
+				Vars:   map[string]interface{}{},
+				Notify: []string{"reload proxy"},
+				Listen: []string{"web services"},
+			}},
+		},
+		{
+			name: "block with rescue and always inherits keywords",
+			taskYAML: `
+- name: guarded
+  when: enabled
+  tags: [deploy]
+  become: true
+  become_user: app
+  vars:
+    shared: block
+    level: block
+  block:
+    - name: step
+      ansible.builtin.debug:
+        msg: step
+      vars:
+        level: task
+      tags: [step]
+      become_user: root
+  rescue:
+    - name: recover
+      ansible.builtin.debug:
+        msg: recover
+  always:
+    - name: cleanup
+      when: cleanup_enabled
+      ansible.builtin.debug:
+        msg: cleanup
+`,
+			expected: []Task{{
+				Name:    "guarded",
+				RawArgs: map[string]interface{}{},
+				Vars: map[string]interface{}{
+					"shared": "block",
+					"level":  "block",
+				},
+				When: []string{"enabled"},
+				Tags: []string{"deploy"},
+				Become: Become{
+					Enabled: &[]bool{true}[0],
+					User:    "app",
+				},
+				Block: []Task{{
+					Name:    "step",
+					Module:  "ansible.builtin.debug",
+					RawArgs: map[string]interface{}{"msg": "step"},
+					Vars: map[string]interface{}{
+						"shared": "block",
+						"level":  "task",
+					},
+					When: []string{"enabled"},
+					Tags: []string{"step", "deploy"},
+					Become: Become{
+						Enabled: &[]bool{true}[0],
+						User:    "root",
+					},
+				}},
+				Rescue: []Task{{
+					Name:    "recover",
+					Module:  "ansible.builtin.debug",
+					RawArgs: map[string]interface{}{"msg": "recover"},
+					Vars: map[string]interface{}{
+						"shared": "block",
+						"level":  "block",
+					},
+					When: []string{"enabled"},
+					Tags: []string{"deploy"},
+					Become: Become{
+						Enabled: &[]bool{true}[0],
+						User:    "app",
+					},
+				}},
+				Always: []Task{{
+					Name:    "cleanup",
+					Module:  "ansible.builtin.debug",
+					RawArgs: map[string]interface{}{"msg": "cleanup"},
+					Vars: map[string]interface{}{
+						"shared": "block",
+						"level":  "block",
+					},
+					When: []string{"enabled", "cleanup_enabled"},
+					Tags: []string{"deploy"},
+					Become: Become{
+						Enabled: &[]bool{true}[0],
+						User:    "app",
+					},
+				}},
+			}},
+		},
+		{
+			name: "nested block inherits from every ancestor",
+			taskYAML: `
+- when: outer
+  block:
+    - when: inner
+      block:
+        - name: leaf
+          ansible.builtin.debug:
+            msg: leaf
+`,
+			expected: []Task{{
+				RawArgs: map[string]interface{}{},
+				Vars:    map[string]interface{}{},
+				When:    []string{"outer"},
+				Block: []Task{{
+					RawArgs: map[string]interface{}{},
+					Vars:    map[string]interface{}{},
+					When:    []string{"outer", "inner"},
+					Block: []Task{{
+						Name:    "leaf",
+						Module:  "ansible.builtin.debug",
+						RawArgs: map[string]interface{}{"msg": "leaf"},
+						Vars:    map[string]interface{}{},
+						When:    []string{"outer", "inner"},
+					}},
+				}},
+			}},
+		},
+		{
+			name: "block with module",
+			taskYAML: `
+- name: confused
+  ansible.builtin.debug:
+    msg: nope
+  block:
+    - name: step
+      ansible.builtin.debug:
+        msg: step
+`,
+			expectErr:         true,
+			expectErrContains: `task "confused": block cannot also invoke module "ansible.builtin.debug"`,
+		},
+		{
+			name: "block that is not a list",
+			taskYAML: `
+- name: confused
+  block: nope
+`,
+			expectErr:         true,
+			expectErrContains: "block must be a list of tasks",
+		},
+		{
+			name: "block with invalid child",
+			taskYAML: `
+- name: confused
+  block:
+    - name: lonely
+`,
+			expectErr:         true,
+			expectErrContains: `task "lonely": no module/action detected in task`,
+		},
+		{
+			name: "keywords are never picked as the module",
+			taskYAML: `
+- name: keywords
+  register: out
+  tags: [one, two]
+  become: true
+  ignore_errors: true
+  with_items: [a]
+  ansible.builtin.command: uptime
+`,
+			expected: []Task{{
+				Name:   "keywords",
+				Module: "ansible.builtin.command",
+				RawArgs: map[string]interface{}{
+					"__value__": "uptime",
+				},
+				Vars:     map[string]interface{}{},
+				Loop:     []interface{}{"a"},
+				LoopWith: "items",
+				Tags:     []string{"one", "two"},
+				Register: "out",
+				Become: Become{
+					Enabled: &[]bool{true}[0],
+				},
+			}},
+		},
+		{
+			name: "free-form command with options and args",
+			taskYAML: `
+- name: list
+  ansible.builtin.command: ls -l chdir=/tmp
+  args:
+    creates: /tmp/done
+- name: shell without a value
+  shell:
+  args:
+    cmd: echo hello
+- name: free-form only for commands
+  ansible.builtin.debug: msg=hello chdir=/tmp
+- name: key=value args
+  copy: content="hello world" dest=/tmp/x mode=0644
+- name: raw params of meta
+  meta: flush_handlers
+- name: debug var
+  debug: var=r.results[1].stdout
+- name: set fact
+  set_fact: a=b
+`,
+			expected: []Task{
+				{
+					Name:   "list",
+					Module: "ansible.builtin.command",
+					RawArgs: map[string]interface{}{
+						"__value__": "ls -l",
+						"chdir":     "/tmp",
+						"creates":   "/tmp/done",
+					},
+					Vars: map[string]interface{}{},
+				},
+				{
+					Name:   "shell without a value",
+					Module: "shell",
+					RawArgs: map[string]interface{}{
+						"cmd": "echo hello",
+					},
+					Vars: map[string]interface{}{},
+				},
+				{
+					Name:   "free-form only for commands",
+					Module: "ansible.builtin.debug",
+					RawArgs: map[string]interface{}{
+						"msg":   "hello",
+						"chdir": "/tmp",
+					},
+					Vars: map[string]interface{}{},
+				},
+				{
+					Name:   "key=value args",
+					Module: "copy",
+					RawArgs: map[string]interface{}{
+						"content": "hello world",
+						"dest":    "/tmp/x",
+						"mode":    "0644",
+					},
+					Vars: map[string]interface{}{},
+				},
+				{
+					Name:   "raw params of meta",
+					Module: "meta",
+					RawArgs: map[string]interface{}{
+						"__value__": "flush_handlers",
+					},
+					Vars: map[string]interface{}{},
+				},
+				{
+					Name:   "debug var",
+					Module: "debug",
+					RawArgs: map[string]interface{}{
+						"var": "r.results[1].stdout",
+					},
+					Vars: map[string]interface{}{},
+				},
+				{
+					Name:   "set fact",
+					Module: "set_fact",
+					RawArgs: map[string]interface{}{
+						"a": "b",
+					},
+					Vars: map[string]interface{}{},
+				},
+			},
+		},
+		{
+			name: "words that are not key=value",
+			taskYAML: `
+- name: confused
+  file: /tmp/x state=touch
+`,
+			expectErr:         true,
+			expectErrContains: `task "confused": module file takes key=value args, got "/tmp/x state=touch"`,
+		},
+		{
+			name: "become settings",
+			taskYAML: `
+- name: escalate
+  become: yes
+  become_user: app
+  become_method: su
+  become_flags: -l
+  become_exe: /usr/bin/su
+  debug: {}
+`,
+			expected: []Task{{
+				Name:    "escalate",
+				Module:  "debug",
+				RawArgs: map[string]interface{}{},
+				Vars:    map[string]interface{}{},
+				Become: Become{
+					Enabled: &[]bool{true}[0],
+					User:    "app",
+					Method:  "su",
+					Flags:   "-l",
+					Exe:     "/usr/bin/su",
+				},
+			}},
+		},
+		{
+			name: "loop forms",
+			taskYAML: `
+- name: literal list
+  loop: [a, b]
+  debug: {}
+- name: with_dict
+  with_dict: "{{ users }}"
+  loop_control:
+    loop_var: user
+    index_var: idx
+    label: "{{ user.key }}"
+    pause: 0.5
+    extended: true
+  debug: {}
+`,
+			expected: []Task{
+				{
+					Name:    "literal list",
+					Module:  "debug",
+					RawArgs: map[string]interface{}{},
+					Vars:    map[string]interface{}{},
+					Loop:    []interface{}{"a", "b"},
+				},
+				{
+					Name:     "with_dict",
+					Module:   "debug",
+					RawArgs:  map[string]interface{}{},
+					Vars:     map[string]interface{}{},
+					Loop:     "{{ users }}",
+					LoopWith: "dict",
+					LoopControl: LoopControl{
+						LoopVar:  "user",
+						IndexVar: "idx",
+						Label:    "{{ user.key }}",
+						Pause:    500 * time.Millisecond,
+						Extended: true,
+					},
+				},
+			},
+		},
+		{
+			name: "duplicate loop",
+			taskYAML: `
+- name: twice
+  loop: [a]
+  with_items: [b]
+  debug: {}
+`,
+			expectErr:         true,
+			expectErrContains: `task "twice": duplicate loop in task: loop, with_items`,
+		},
+		{
+			name: "loop of invalid type",
+			taskYAML: `
+- name: mapping
+  loop: {a: b}
+  debug: {}
+`,
+			expectErr:         true,
+			expectErrContains: "loop must be a template or a list",
+		},
+		{
+			name: "unsupported loop_control option",
+			taskYAML: `
+- name: bad control
+  loop: [a]
+  loop_control:
+    bogus: true
+  debug: {}
+`,
+			expectErr:         true,
+			expectErrContains: `unsupported loop_control option "bogus"`,
+		},
+		{
+			name: "loop on a block",
+			taskYAML: `
+- name: looped block
+  loop: [a]
+  block:
+    - debug: {}
+`,
+			expectErr:         true,
+			expectErrContains: "blocks do not support loops",
+		},
+		{
+			name: "action keyword",
+			taskYAML: `
+- name: action
+  action: ansible.builtin.command uptime
+`,
+			expected: []Task{{
+				Name:   "action",
+				Module: "ansible.builtin.command",
+				RawArgs: map[string]interface{}{
+					"__value__": "uptime",
+				},
+				Vars: map[string]interface{}{},
+			}},
+		},
+		{
+			name: "action keyword with mapping and args",
+			taskYAML: `
+- name: action
+  action:
+    module: ansible.builtin.debug
+    msg: hello
+  args:
+    msg: ignored
+    verbosity: 1
+`,
+			expected: []Task{{
+				Name:   "action",
+				Module: "ansible.builtin.debug",
+				RawArgs: map[string]interface{}{
+					"msg":       "hello",
+					"verbosity": 1,
+				},
+				Vars: map[string]interface{}{},
+			}},
+		},
+		{
+			name: "no module",
+			taskYAML: `
+- name: lonely
+  register: out
+`,
+			expectErr:         true,
+			expectErrContains: `source.yml: task "lonely": no module/action detected in task`,
+		},
+		{
+			name: "multiple modules",
+			taskYAML: `
+- ansible.builtin.debug:
+    msg: one
+  ansible.builtin.command: uptime
+`,
+			expectErr:         true,
+			expectErrContains: "task #1: conflicting action statements: ansible.builtin.command, ansible.builtin.debug",
+		},
 		{
 			name: "include_tasks with bad value",
 			taskYAML: `
@@ -120,6 +615,16 @@ func (s *ParseTasksTestSuite) TestParseTasks() {
 			expectErr:         true,
 			expectErrContains: "include_tasks path must be a string",
 		},
+		{
+			name: "include_tasks with a loop",
+			taskYAML: `
+- name: looped include
+  ansible.builtin.include_tasks: included.yml
+  loop: [a, b]
+`,
+			expectErr:         true,
+			expectErrContains: `task "looped include": ansible.builtin.include_tasks does not support loops`,
+		},
 		{
 			name: "include_tasks missing file",
 			taskYAML: `
@@ -142,7 +647,6 @@ func (s *ParseTasksTestSuite) TestParseTasks() {
 					"msg": "hi from included",
 				},
 				Vars: map[string]interface{}{},
-				Loop: "",
 			}},
 			prepare: func(dir string) {
 				err := os.WriteFile(filepath.Join(dir, "included.yml"), []byte(`
@@ -206,7 +710,6 @@ func (s *ParseTasksTestSuite) TestParseTasks() {
 					"__value__": "echo hello",
 				},
 				Vars: map[string]interface{}{},
-				Loop: "",
 			}},
 		},
 	}
@@ -244,12 +747,37 @@ func (s *ParseTasksTestSuite) TestParseTasks() {
 				s.Equal(exp.RawArgs, act.RawArgs)
 				s.Equal(exp.Vars, act.Vars)
 				s.Equal(exp.Loop, act.Loop)
+				s.Equal(exp.LoopWith, act.LoopWith)
+				s.Equal(exp.LoopControl, act.LoopControl)
+				s.Equal(exp.When, act.When)
+				s.Equal(exp.Notify, act.Notify)
+				s.Equal(exp.Listen, act.Listen)
+				s.Equal(exp.Tags, act.Tags)
+				s.Equal(exp.Register, act.Register)
+				s.Equal(exp.Become, act.Become)
+				s.Equal(exp.Block, s.withoutSource(act.Block))
+				s.Equal(exp.Rescue, s.withoutSource(act.Rescue))
+				s.Equal(exp.Always, s.withoutSource(act.Always))
 				s.NotEmpty(act.Source)
 			}
 		})
 	}
 }
 
+// withoutSource clears the Source of nested tasks so they compare equal to
+// expectations that cannot know the temporary directory.
+func (s *ParseTasksTestSuite) withoutSource(tasks []Task) []Task {
+	for i := range tasks {
+		s.NotEmpty(tasks[i].Source)
+		tasks[i].Source = ""
+		tasks[i].Block = s.withoutSource(tasks[i].Block)
+		tasks[i].Rescue = s.withoutSource(tasks[i].Rescue)
+		tasks[i].Always = s.withoutSource(tasks[i].Always)
+	}
+
+	return tasks
+}
+
 func TestParseTasksSuite(t *testing.T) {
 	suite.Run(t, new(ParseTasksTestSuite))
 }
@@ -20,9 +20,51 @@
 
 package ansible
 
+import (
+	"fmt"
+)
+
 func safeString(v interface{}) string {
 	if s, ok := v.(string); ok {
 		return s
 	}
 	return ""
 }
+
+// toStringList normalizes a scalar or list YAML value into a list of strings.
+func toStringList(v interface{}) []string {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	default:
+		return []string{fmt.Sprint(val)}
+	}
+}
+
+// toTaskMaps keeps the mapping entries of a raw YAML task list.
+func toTaskMaps(raw []interface{}) []map[string]interface{} {
+	var out []map[string]interface{}
+	for _, t := range raw {
+		if tm, ok := t.(map[string]interface{}); ok {
+			out = append(out, tm)
+		}
+	}
+
+	return out
+}
+
+// forEachTask calls fn for every task, descending into blocks.
+func forEachTask(tasks []Task, fn func(Task)) {
+	for _, task := range tasks {
+		fn(task)
+		forEachTask(task.Block, fn)
+		forEachTask(task.Rescue, fn)
+		forEachTask(task.Always, fn)
+	}
+}
@@ -72,6 +72,48 @@ func (s *PlaybookTestSuite) TestSafeString() {
 	}
 }
 
+func (s *PlaybookTestSuite) TestToStringList() {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected []string
+	}{
+		{
+			name:     "Nil input",
+			input:    nil,
+			expected: nil,
+		},
+		{
+			name:     "String input",
+			input:    "foo is defined",
+			expected: []string{"foo is defined"},
+		},
+		{
+			name:     "Bool input",
+			input:    true,
+			expected: []string{"true"},
+		},
+		{
+			name:     "List input",
+			input:    []interface{}{"a", 1, false},
+			expected: []string{"a", "1", "false"},
+		},
+		{
+			name:     "Empty list input",
+			input:    []interface{}{},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			got := toStringList(tc.input)
+
+			s.Equal(tc.expected, got)
+		})
+	}
+}
+
 func TestPlaybookTestSuite(t *testing.T) {
 	suite.Run(t, new(PlaybookTestSuite))
 }
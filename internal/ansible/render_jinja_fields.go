@@ -22,6 +22,7 @@ package ansible
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/kluctl/kluctl/lib/go-jinja2"
 )
@@ -32,29 +33,93 @@ func RenderJinjaFields(
 	in map[string]interface{},
 	context map[string]interface{},
 	renderer *jinja2.Jinja2,
+) (map[string]interface{}, error) {
+	vars, err := resolveVars(in, context, renderer)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderFields(in, vars, renderer)
+}
+
+// renderFields renders the fields of in against an already resolved context.
+func renderFields(
+	in map[string]interface{},
+	context map[string]interface{},
+	renderer *jinja2.Jinja2,
 ) (map[string]interface{}, error) {
 	out := make(map[string]interface{})
 
 	for k, v := range in {
-		switch val := v.(type) {
-		case string:
-			rendered, err := renderer.RenderString(val, jinja2.WithGlobals(context))
-			if err != nil {
-				return nil, fmt.Errorf("failed to render field %q: %w", k, err)
-			}
-			out[k] = rendered
+		rendered, err := renderValue(v, context, renderer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render field %q: %w", k, err)
+		}
+		out[k] = rendered
+	}
+
+	return out, nil
+}
 
-		case map[string]interface{}:
-			renderedMap, err := RenderJinjaFields(val, context, renderer)
+// RenderValue renders every string in v, descending into maps and lists.
+// Like Ansible, a string made of a single expression that renders to a
+// list, a dict or a boolean, such as "{{ some_list }}", evaluates to that
+// value instead of its text. Other values are returned untouched.
+func RenderValue(
+	v interface{},
+	context map[string]interface{},
+	renderer *jinja2.Jinja2,
+) (interface{}, error) {
+	vars, err := resolveVars(v, context, renderer)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderValue(v, vars, renderer)
+}
+
+// renderValue renders v once against an already resolved context.
+func renderValue(
+	v interface{},
+	context map[string]interface{},
+	renderer *jinja2.Jinja2,
+) (interface{}, error) {
+	switch val := v.(type) {
+	case string:
+		rendered, err := renderString(val, context, renderer)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := singleExpression(val); ok && isNativeText(rendered) {
+			return evaluateExpression(val, context, renderer)
+		}
+		return rendered, nil
+
+	case map[string]interface{}:
+		return renderFields(val, context, renderer)
+
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			rendered, err := renderValue(item, context, renderer)
 			if err != nil {
 				return nil, err
 			}
-			out[k] = renderedMap
-
-		default:
-			out[k] = v // Leave untouched
+			out[i] = rendered
 		}
+		return out, nil
+
+	case Unsafe:
+		return val.Value, nil
+
+	default:
+		return v, nil // Leave untouched
 	}
+}
 
-	return out, nil
+// isNativeText reports whether rendered text is a list, a dict or a boolean
+// that Ansible turns back into a value.
+func isNativeText(s string) bool {
+	return strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{") || s == "True" || s == "False"
 }
@@ -108,6 +108,41 @@ func (s *RenderJinjaFieldsPublicTestSuite) TestRenderJinjaFields() {
 				"msg": "hello ",
 			},
 		},
+		{
+			name: "vars defined in terms of other vars",
+			input: map[string]interface{}{
+				"msg": "{{ greeting }}",
+			},
+			vars: map[string]interface{}{
+				"greeting": "hello {{ name }}",
+				"name":     "{{ first }} {{ last }}",
+				"first":    "void",
+				"last":     "span",
+			},
+			expected: map[string]interface{}{
+				"msg": "hello void span",
+			},
+		},
+		{
+			name: "lists with interpolation",
+			input: map[string]interface{}{
+				"items": []interface{}{
+					"{{ foo }}",
+					map[string]interface{}{"inner": "{{ foo }}"},
+					7,
+				},
+			},
+			vars: map[string]interface{}{
+				"foo": "bar",
+			},
+			expected: map[string]interface{}{
+				"items": []interface{}{
+					"bar",
+					map[string]interface{}{"inner": "bar"},
+					7,
+				},
+			},
+		},
 		{
 			name: "invalid syntax returns error",
 			input: map[string]interface{}{
@@ -137,6 +172,18 @@ func (s *RenderJinjaFieldsPublicTestSuite) TestRenderJinjaFields() {
 				"msg": "fallback",
 			},
 		},
+		{
+			name: "bool filter",
+			input: map[string]interface{}{
+				"enabled": "{{ flag | bool }}",
+			},
+			vars: map[string]interface{}{
+				"flag": "on",
+			},
+			expected: map[string]interface{}{
+				"enabled": true,
+			},
+		},
 		{
 			name: "invalid filter raises error",
 			input: map[string]interface{}{
@@ -146,6 +193,64 @@ func (s *RenderJinjaFieldsPublicTestSuite) TestRenderJinjaFields() {
 			expectErr:         true,
 			expectErrContains: "does_not_exist",
 		},
+		{
+			name: "single expressions keep lists, dicts and booleans",
+			input: map[string]interface{}{
+				"list":   "{{ ports }}",
+				"dict":   "{{ {'a': 1} }}",
+				"bool":   "{{ ports | length > 1 }}",
+				"number": "{{ ports[0] }}",
+				"text":   "ports: {{ ports }}",
+			},
+			vars: map[string]interface{}{
+				"ports": []interface{}{80, 443},
+			},
+			expected: map[string]interface{}{
+				"list":   []interface{}{float64(80), float64(443)},
+				"dict":   map[string]interface{}{"a": float64(1)},
+				"bool":   true,
+				"number": "80",
+				"text":   "ports: [80, 443]",
+			},
+		},
+		{
+			name: "self-referencing variable returns error",
+			input: map[string]interface{}{
+				"msg": "{{ loop_var }}",
+			},
+			vars: map[string]interface{}{
+				"loop_var": "{{ loop_var }}",
+			},
+			expectErr:         true,
+			expectErrContains: "recursive loop detected",
+		},
+		{
+			name: "rendered output is not rendered again",
+			input: map[string]interface{}{
+				"msg": "{{ raw }}",
+			},
+			vars: map[string]interface{}{
+				"raw": "{{ '{{ 7 * 7 }}' }}",
+			},
+			expected: map[string]interface{}{
+				"msg": "{{ 7 * 7 }}",
+			},
+		},
+		{
+			name: "unsafe values are never rendered",
+			input: map[string]interface{}{
+				"msg":  "{{ r.stdout }}",
+				"text": "out: {{ out }}",
+			},
+			vars: map[string]interface{}{
+				"r":   ansible.Unsafe{Value: map[string]interface{}{"stdout": "{{ 7 * 7 }}"}},
+				"out": "{{ r.stdout }}",
+			},
+			expected: map[string]interface{}{
+				"msg":  "{{ 7 * 7 }}",
+				"text": "out: {{ 7 * 7 }}",
+			},
+		},
 		{
 			name: "jinja for loop renders multiple lines",
 			input: map[string]interface{}{
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+)
+
+// boolFilter is Ansible's bool filter, which Jinja2 lacks: booleans pass
+// through, and "yes", "on", "1", "true" and 1 are true regardless of case.
+var boolFilter = jinja2.WithFilter("bool:to_bool", `
+def to_bool(a):
+    if a is None or isinstance(a, bool):
+        return a
+    if isinstance(a, str):
+        a = a.lower()
+    return a in ('yes', 'on', '1', 'true', 1)
+`)
+
+// renderString renders template once against an already resolved context,
+// with the filters Ansible adds to Jinja2.
+func renderString(
+	template string,
+	context map[string]interface{},
+	renderer *jinja2.Jinja2,
+	opts ...jinja2.Jinja2Opt,
+) (string, error) {
+	opts = append([]jinja2.Jinja2Opt{jinja2.WithGlobals(context), boolFilter}, opts...)
+	return renderer.RenderString(template, opts...)
+}
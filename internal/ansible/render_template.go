@@ -0,0 +1,67 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+)
+
+// RenderTemplate renders the template file at path once, as the template
+// module does: a block tag trims the newline that follows it and the
+// trailing newline of the file is kept. The template may include or import
+// other templates from its directory.
+func RenderTemplate(
+	path string,
+	context map[string]interface{},
+	renderer *jinja2.Jinja2,
+) (string, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template %s: %w", path, err)
+	}
+
+	vars, err := resolveVars(string(source), context, renderer)
+	if err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", path, err)
+	}
+
+	rendered, err := renderer.RenderFile(
+		filepath.Base(path),
+		jinja2.WithGlobals(vars),
+		boolFilter,
+		jinja2.WithSearchDir(filepath.Dir(path)),
+		jinja2.WithTrimBlocks(true),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", path, err)
+	}
+
+	if strings.HasSuffix(string(source), "\n") && !strings.HasSuffix(rendered, "\n") {
+		rendered += "\n"
+	}
+
+	return rendered, nil
+}
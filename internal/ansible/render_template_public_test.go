@@ -0,0 +1,168 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+type RenderTemplatePublicTestSuite struct {
+	suite.Suite
+
+	renderer *jinja2.Jinja2
+	tmpDir   string
+}
+
+func (s *RenderTemplatePublicTestSuite) SetupSuite() {
+	r, err := jinja2.NewJinja2("test-template", 1, jinja2.WithStrict(false))
+	s.Require().NoError(err)
+	s.renderer = r
+}
+
+func (s *RenderTemplatePublicTestSuite) TearDownSuite() {
+	s.renderer.Close()
+}
+
+func (s *RenderTemplatePublicTestSuite) SetupTest() {
+	s.tmpDir = s.T().TempDir()
+}
+
+func (s *RenderTemplatePublicTestSuite) TestRenderTemplate() {
+	tests := []struct {
+		name              string
+		files             map[string]string
+		vars              map[string]interface{}
+		expected          string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "renders once and keeps the trailing newline",
+			files: map[string]string{
+				"main.j2": "port={{ port }}\nraw={{ raw }}\n",
+			},
+			vars: map[string]interface{}{
+				"port": 8080,
+				"raw":  "{{ '{{ not rendered }}' }}",
+			},
+			expected: "port=8080\nraw={{ not rendered }}\n",
+		},
+		{
+			name: "blocks trim their newline",
+			files: map[string]string{
+				"main.j2": "{% for u in users %}\nuser {{ u }}\n{% endfor %}\n",
+			},
+			vars: map[string]interface{}{
+				"users": []interface{}{"a", "b"},
+			},
+			expected: "user a\nuser b\n",
+		},
+		{
+			name: "json is not turned into a value",
+			files: map[string]string{
+				"main.j2": `{"port": {{ port }}}`,
+			},
+			vars: map[string]interface{}{
+				"port": 80,
+			},
+			expected: `{"port": 80}`,
+		},
+		{
+			name: "includes from the template directory",
+			files: map[string]string{
+				"main.j2": "head\n{% include 'part.j2' %}\n",
+				"part.j2": "part {{ name }}",
+			},
+			vars: map[string]interface{}{
+				"name": "one",
+			},
+			expected: "head\npart one\n",
+		},
+		{
+			name: "vars defined in terms of other vars",
+			files: map[string]string{
+				"main.j2": "listen {{ port }}\n",
+			},
+			vars: map[string]interface{}{
+				"port":      "{{ base_port + 80 }}",
+				"base_port": 8000,
+			},
+			expected: "listen 8080\n",
+		},
+		{
+			name: "included templates see derived vars",
+			files: map[string]string{
+				"main.j2": "{% include 'part.j2' %}\n",
+				"part.j2": "part {{ name }}",
+			},
+			vars: map[string]interface{}{
+				"name":  "{{ first }}",
+				"first": "one",
+			},
+			expected: "part one\n",
+		},
+		{
+			name: "syntax error",
+			files: map[string]string{
+				"main.j2": "{% if %}",
+			},
+			expectErr:         true,
+			expectErrContains: "failed to render template",
+		},
+		{
+			name:              "missing template",
+			expectErr:         true,
+			expectErrContains: "failed to read template",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			dir := filepath.Join(s.tmpDir, filepath.Base(s.T().Name()))
+			s.Require().NoError(os.MkdirAll(dir, 0o755))
+			for name, content := range tc.files {
+				s.Require().NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+			}
+
+			rendered, err := ansible.RenderTemplate(filepath.Join(dir, "main.j2"), tc.vars, s.renderer)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, rendered)
+		})
+	}
+}
+
+func TestRenderTemplatePublicTestSuite(t *testing.T) {
+	suite.Run(t, new(RenderTemplatePublicTestSuite))
+}
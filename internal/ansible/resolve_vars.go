@@ -0,0 +1,160 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+)
+
+var (
+	// templateBlock matches the expressions and statements of a template.
+	templateBlock = regexp.MustCompile(`(?s)\{\{.*?\}\}|\{%.*?%\}`)
+	// stringLiteral matches the quoted strings of an expression.
+	stringLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"`)
+	// reference matches the names an expression refers to, skipping
+	// attributes such as the stdout of r.stdout.
+	reference = regexp.MustCompile(`(?:^|[^.\w])([A-Za-z_]\w*)`)
+	// includesTemplates matches the statements that render other templates.
+	includesTemplates = regexp.MustCompile(`\{%[-+]?\s*(?:include|import|from|extends)\s`)
+)
+
+// resolveVars returns the context the templates in v render against. Like
+// Ansible's lazy variable lookups, every variable they refer to is rendered
+// from its definition once, after the variables that definition refers to.
+// Values marked Unsafe are never rendered, and the rendered output is never
+// rendered again.
+func resolveVars(
+	v interface{},
+	context map[string]interface{},
+	renderer *jinja2.Jinja2,
+) (map[string]interface{}, error) {
+	r := &resolver{
+		context:   context,
+		renderer:  renderer,
+		resolved:  make(map[string]interface{}),
+		resolving: make(map[string]bool),
+	}
+
+	names := references(v)
+	if source, ok := v.(string); ok && includesTemplates.MatchString(source) {
+		// the templates it pulls in may refer to any variable
+		names = slices.Collect(maps.Keys(context))
+	}
+
+	return r.vars(names)
+}
+
+// resolver renders variable definitions on demand, remembering the values
+// it already rendered.
+type resolver struct {
+	context   map[string]interface{}
+	renderer  *jinja2.Jinja2
+	resolved  map[string]interface{}
+	resolving map[string]bool
+}
+
+// vars returns the context with the given names resolved.
+func (r *resolver) vars(
+	names []string,
+) (map[string]interface{}, error) {
+	out := StripUnsafe(r.context)
+	for _, name := range names {
+		if _, ok := r.context[name]; !ok {
+			continue
+		}
+
+		value, err := r.resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = value
+	}
+
+	return out, nil
+}
+
+// resolve renders the definition of name.
+func (r *resolver) resolve(
+	name string,
+) (interface{}, error) {
+	if value, ok := r.resolved[name]; ok {
+		return value, nil
+	}
+
+	definition := r.context[name]
+	if u, ok := definition.(Unsafe); ok {
+		return u.Value, nil
+	}
+
+	if r.resolving[name] {
+		return nil, fmt.Errorf("recursive loop detected in template string: %s", name)
+	}
+	r.resolving[name] = true
+	defer delete(r.resolving, name)
+
+	context, err := r.vars(references(definition))
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := renderValue(definition, context, r.renderer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	r.resolved[name] = value
+
+	return value, nil
+}
+
+// references returns the names the templates in v refer to, descending
+// into maps and lists.
+func references(
+	v interface{},
+) []string {
+	var names []string
+
+	switch val := v.(type) {
+	case string:
+		for _, block := range templateBlock.FindAllString(val, -1) {
+			block = stringLiteral.ReplaceAllString(block, "''")
+			for _, m := range reference.FindAllStringSubmatch(block, -1) {
+				names = append(names, m[1])
+			}
+		}
+
+	case map[string]interface{}:
+		for _, item := range val {
+			names = append(names, references(item)...)
+		}
+
+	case []interface{}:
+		for _, item := range val {
+			names = append(names, references(item)...)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// roleLoader expands roles, with their dependencies, for a single play.
+type roleLoader struct {
+	rolesPath string
+	// seen holds the name and parameters of every role already expanded in
+	// the play, to deduplicate dependencies.
+	seen map[string]struct{}
+	// handlers collects the handlers of every role expanded in the play.
+	handlers []Task
+	// seenHandlers holds the role and name of every handler collected, so a
+	// role included more than once registers its handlers once.
+	seenHandlers map[handlerKey]struct{}
+}
+
+// handlerKey identifies a role handler by role and handler name.
+type handlerKey struct {
+	role string
+	name string
+}
+
+// loadSection parses a play task list such as tasks or pre_tasks and
+// expands the roles it includes. A missing section yields no tasks.
+func (l *roleLoader) loadSection(
+	key string,
+	raw interface{},
+	playbookPath string,
+) ([]Task, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	rawTasks, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: %s must be a list of tasks", playbookPath, key)
+	}
+
+	parsedTasks, err := parseTasks(toTaskMaps(rawTasks), playbookPath, l.rolesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.expandIncludeRoles(parsedTasks)
+}
+
+// loadPlayRoles expands the roles keyword of a play. Entries take the same
+// form as role dependencies and a role listed twice with the same
+// parameters runs once.
+func (l *roleLoader) loadPlayRoles(
+	raw interface{},
+	playbookPath string,
+) ([]Task, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	rawRoles, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: roles must be a list", playbookPath)
+	}
+
+	var tasks []Task
+	for i, rawRole := range rawRoles {
+		ref, err := parseRoleDependency(rawRole)
+		if err != nil {
+			return nil, fmt.Errorf("%s: role #%d: %w", playbookPath, i+1, err)
+		}
+
+		roleTasks, roleHandlers, err := l.load(ref.Name, ref.Vars, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, inherit(ref.task(), roleTasks)...)
+		l.handlers = append(l.handlers, withVars(ref.Vars, roleHandlers)...)
+	}
+
+	return tasks, nil
+}
+
+// newRoleLoader creates a roleLoader for a play.
+func newRoleLoader(
+	rolesPath string,
+) *roleLoader {
+	return &roleLoader{
+		rolesPath:    rolesPath,
+		seen:         make(map[string]struct{}),
+		seenHandlers: make(map[handlerKey]struct{}),
+	}
+}
+
+// expandIncludeRoles replaces include_role tasks, including those nested in
+// blocks, with the tasks of the role.
+func (l *roleLoader) expandIncludeRoles(
+	parsedTasks []Task,
+) ([]Task, error) {
+	tasks := make([]Task, 0, len(parsedTasks))
+
+	for _, task := range parsedTasks {
+		if task.IsBlock() {
+			for _, section := range []*[]Task{&task.Block, &task.Rescue, &task.Always} {
+				expanded, err := l.expandIncludeRoles(*section)
+				if err != nil {
+					return nil, err
+				}
+
+				*section = expanded
+			}
+
+			tasks = append(tasks, task)
+			continue
+		}
+
+		if task.Module == "include_role" || task.Module == "ansible.builtin.include_role" {
+			roleName := safeString(task.RawArgs["name"])
+			if roleName == "" {
+				return nil, fmt.Errorf("include_role task is missing 'name': %+v", task.RawArgs)
+			}
+			if task.Loop != nil {
+				return nil, fmt.Errorf("%s: include_role %q does not support loops", task.Source, roleName)
+			}
+
+			roleTasks, roleHandlers, err := l.load(roleName, task.Vars, nil, false)
+			if err != nil {
+				return nil, err
+			}
+
+			// vars and keywords on the include apply to every task of the role
+			tasks = append(tasks, inherit(task, roleTasks)...)
+			l.handlers = append(l.handlers, withVars(task.Vars, roleHandlers)...)
+			continue
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// load returns the tasks of a role, preceded by those of its dependencies,
+// and the handlers not yet registered by an earlier include of the same
+// roles. chain holds the roles depending on this one, for cycle detection.
+// When dedupe is set a role already expanded with the same parameters is
+// skipped, unless it allows duplicates.
+func (l *roleLoader) load(
+	roleName string,
+	params map[string]interface{},
+	chain []string,
+	dedupe bool,
+) ([]Task, []Task, error) {
+	if slices.Contains(chain, roleName) {
+		return nil, nil, fmt.Errorf(
+			"role dependency cycle detected: %s",
+			strings.Join(append(chain, roleName), " -> "),
+		)
+	}
+
+	meta, err := LoadRoleMeta(roleName, l.rolesPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load role %q: %w", roleName, err)
+	}
+
+	key := roleKey(roleName, params)
+	if _, seen := l.seen[key]; seen && dedupe && !meta.AllowDuplicates {
+		return nil, nil, nil
+	}
+	l.seen[key] = struct{}{}
+
+	var tasks, handlers []Task
+	for _, dep := range meta.Dependencies {
+		depTasks, depHandlers, err := l.load(
+			dep.Name,
+			dep.Vars,
+			append(slices.Clone(chain), roleName),
+			true,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		tasks = append(tasks, inherit(dep.task(), depTasks)...)
+		handlers = append(handlers, withVars(dep.Vars, depHandlers)...)
+	}
+
+	roleTasks, err := LoadRoleTasks(roleName, l.rolesPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load role %q: %w", roleName, err)
+	}
+
+	roleHandlers, err := LoadRoleHandlers(roleName, l.rolesPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load role %q: %w", roleName, err)
+	}
+
+	for i, handler := range roleHandlers {
+		key := handlerKey{role: roleName, name: taskLabel(handler.Name, i)}
+		if _, seen := l.seenHandlers[key]; seen {
+			continue
+		}
+		l.seenHandlers[key] = struct{}{}
+
+		handlers = append(handlers, handler)
+	}
+
+	return append(tasks, roleTasks...), handlers, nil
+}
+
+// withVars scopes the vars of a role reference or include onto the role's
+// handlers. Its when, tags and become stay with the tasks, since a handler
+// runs whenever it is notified.
+func withVars(
+	vars map[string]interface{},
+	handlers []Task,
+) []Task {
+	return inherit(Task{Vars: vars}, handlers)
+}
+
+// roleKey identifies a role invocation by name and parameters.
+func roleKey(
+	roleName string,
+	params map[string]interface{},
+) string {
+	if len(params) == 0 {
+		return roleName
+	}
+
+	// json.Marshal sorts map keys, giving a stable key for equal params
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		encoded = []byte(fmt.Sprintf("%v", params))
+	}
+
+	return roleName + string(encoded)
+}
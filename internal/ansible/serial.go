@@ -0,0 +1,86 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseSerial parses the serial keyword of a play: a host count, a
+// percentage such as "30%", or a list of them.
+func parseSerial(
+	v interface{},
+) ([]BatchSize, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	values, ok := v.([]interface{})
+	if !ok {
+		values = []interface{}{v}
+	}
+
+	sizes := make([]BatchSize, 0, len(values))
+	for _, value := range values {
+		size, err := parseBatchSize(value)
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, size)
+	}
+
+	return sizes, nil
+}
+
+// parseBatchSize parses a host count or a percentage.
+func parseBatchSize(
+	v interface{},
+) (BatchSize, error) {
+	s := strings.TrimSpace(fmt.Sprint(v))
+	percent := strings.HasSuffix(s, "%")
+
+	n, err := strconv.Atoi(strings.TrimSuffix(s, "%"))
+	if err != nil || n < 0 || percent && n > 100 {
+		return BatchSize{}, fmt.Errorf("invalid serial %q: expected a host count or a percentage", s)
+	}
+
+	return BatchSize{Value: n, Percent: percent}, nil
+}
+
+// parseMaxFailPercentage parses the max_fail_percentage keyword of a play,
+// with or without a trailing "%".
+func parseMaxFailPercentage(
+	v interface{},
+) (*int, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	s := strings.TrimSuffix(strings.TrimSpace(fmt.Sprint(v)), "%")
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 100 {
+		return nil, fmt.Errorf("invalid max_fail_percentage %v: expected a percentage", v)
+	}
+
+	return &n, nil
+}
@@ -20,6 +20,10 @@
 
 package ansible
 
+import (
+	"time"
+)
+
 // Playbook represents a full Ansible playbook — a list of plays.
 type Playbook []Play
 
@@ -29,8 +33,42 @@ type Play struct {
 	Name string
 	// Hosts defines the target hosts for this play (e.g., "all")
 	Hosts string
-	// Tasks is the ordered list of tasks to run in this play
+	// Vars holds the play-level vars
+	Vars map[string]interface{}
+	// Become holds the play-level privilege escalation settings, inherited
+	// by every task of the play
+	Become Become
+	// Strategy is the name of the strategy the play runs with (e.g.,
+	// "free"). Empty means the executor's default.
+	Strategy string
+	// Serial splits the hosts into batches that run through the whole play
+	// one after another. The last size repeats until every host had its
+	// turn. Empty runs every host in a single batch.
+	Serial []BatchSize
+	// MaxFailPercentage aborts the play once more than this percentage of
+	// the hosts of a batch failed. Nil means any number of hosts may fail.
+	MaxFailPercentage *int
+	// AnyErrorsFatal aborts the play as soon as a task fails on any host.
+	AnyErrorsFatal bool
+	// PreTasks is the ordered list of tasks to run before the roles
+	PreTasks []Task
+	// Tasks is the ordered list of tasks to run in this play, starting with
+	// the tasks of the play's roles
 	Tasks []Task
+	// PostTasks is the ordered list of tasks to run after Tasks
+	PostTasks []Task
+	// Handlers is the ordered list of handlers available to notify, from the
+	// play and from its roles
+	Handlers []Task
+}
+
+// BatchSize is the size of a serial batch.
+type BatchSize struct {
+	// Value is a number of hosts, or a percentage of the play's hosts when
+	// Percent is set. Zero means every remaining host.
+	Value int
+	// Percent makes Value a percentage.
+	Percent bool
 }
 
 // Task represents an individual Ansible task.
@@ -43,9 +81,107 @@ type Task struct {
 	RawArgs map[string]interface{}
 	// Vars holds optional vars specific to this task
 	Vars map[string]interface{}
-	// Loop holds the raw loop expression from the task (e.g., "{{ some_list }}").
-	// Loop contains the raw loop expression from the task (e.g., "{{ some_list }}").
-	Loop string
+	// Loop holds the raw loop of the task: a template such as
+	// "{{ some_list }}" or a literal list. It is nil when the task does not
+	// loop.
+	Loop interface{}
+	// LoopWith is the lookup of a with_* loop (e.g., "items" for with_items)
+	// and is empty for loop.
+	LoopWith string
+	// LoopControl holds the task's loop_control settings.
+	LoopControl LoopControl
+	// When holds the conditional expressions that must all be true for the
+	// task to run.
+	When []string
+	// Notify lists the handler names or listen topics to notify when the
+	// task reports a change.
+	Notify []string
+	// Listen lists the topics a handler responds to, in addition to its name.
+	Listen []string
+	// Tags lists the tags that select the task.
+	Tags []string
+	// Register is the variable the task's result is stored in, or empty.
+	Register string
+	// Become holds the task's privilege escalation settings.
+	Become Become
+	// Block holds the tasks of a block. A task with a Block runs no module.
+	Block []Task
+	// Rescue holds the tasks run when a task in Block fails.
+	Rescue []Task
+	// Always holds the tasks run after Block and Rescue, whatever the outcome.
+	Always []Task
 	// Source is the absolute or relative file path where this task was defined.
 	Source string
+	// Role is the role the task belongs to, or nil for play-level tasks.
+	Role *Role
+}
+
+// IsBlock reports whether the task is a block of tasks rather than a module call.
+func (t Task) IsBlock() bool {
+	return t.Block != nil
+}
+
+// Role holds the variables a role contributes to its tasks.
+type Role struct {
+	// Name is the name of the role (e.g., "retr0h.role-1")
+	Name string
+	// Path is the directory the role was loaded from.
+	Path string
+	// Defaults holds the role's defaults/main.yml, the lowest precedence vars.
+	Defaults map[string]interface{}
+	// Vars holds the role's vars/main.yml.
+	Vars map[string]interface{}
+}
+
+// RoleMeta holds the parts of a role's meta/main.yml that affect execution.
+type RoleMeta struct {
+	// Dependencies lists the roles to run before this role.
+	Dependencies []RoleDependency
+	// AllowDuplicates lets the role run more than once per play with the
+	// same parameters.
+	AllowDuplicates bool
+}
+
+// RoleDependency is a single entry of a role's dependencies.
+type RoleDependency struct {
+	// Name is the name of the role depended on.
+	Name string
+	// Vars holds the parameters passed to the dependency.
+	Vars map[string]interface{}
+	// When holds conditionals applied to every task of the dependency.
+	When []string
+	// Tags holds tags applied to every task of the dependency.
+	Tags []string
+	// Become holds privilege escalation settings applied to every task of
+	// the dependency.
+	Become Become
+}
+
+// LoopControl customizes how a looped task binds and reports its items.
+type LoopControl struct {
+	// LoopVar is the variable each item is bound to; "item" when empty.
+	LoopVar string
+	// IndexVar, when set, is the variable holding the zero-based item index.
+	IndexVar string
+	// Label is a template shown in place of the item in task output.
+	Label string
+	// Pause is the time to wait between items.
+	Pause time.Duration
+	// Extended exposes the ansible_loop variable with details about the loop.
+	Extended bool
+}
+
+// Become holds privilege escalation settings. Unset fields are inherited from
+// the enclosing block.
+type Become struct {
+	// Enabled toggles privilege escalation. Nil means unset.
+	Enabled *bool
+	// User is the user to become (e.g., "root").
+	User string
+	// Method is the escalation method (e.g., "sudo").
+	Method string
+	// Flags holds extra flags passed to the escalation method.
+	Flags string
+	// Exe is the escalation executable, when it differs from the method.
+	Exe string
 }
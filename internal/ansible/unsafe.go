@@ -0,0 +1,53 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ansible
+
+import (
+	"encoding/json"
+)
+
+// Unsafe marks a variable whose value must never be rendered as a template,
+// such as a registered result or the facts a module returned. Like Ansible's
+// AnsibleUnsafe, it keeps data read from a managed host from running as
+// template code.
+type Unsafe struct {
+	Value interface{}
+}
+
+// MarshalJSON encodes the wrapped value, so templates see it as it is.
+func (u Unsafe) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.Value)
+}
+
+// StripUnsafe returns a copy of vars with every Unsafe value unwrapped.
+func StripUnsafe(
+	vars map[string]interface{},
+) map[string]interface{} {
+	out := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		if u, ok := v.(Unsafe); ok {
+			v = u.Value
+		}
+		out[k] = v
+	}
+
+	return out
+}
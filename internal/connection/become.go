@@ -0,0 +1,512 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package connection
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// becomeTimeout bounds the wait for the become method to prompt for a
+// password or to report success.
+var becomeTimeout = 10 * time.Second
+
+// suPrompt matches the password prompt of su, e.g. "Password: ".
+var suPrompt = regexp.MustCompile(`(?i)password ?: ?$`)
+
+// Become holds privilege escalation settings.
+type Become struct {
+	// Method is the escalation method, "sudo" or "su". Empty means sudo.
+	Method string
+	// User is the user to become. Empty means root.
+	User string
+	// Flags holds the flags passed to the method. Empty means "-H -S -n"
+	// for sudo.
+	Flags string
+	// Exe is the escalation executable. Empty means the method name.
+	Exe string
+	// Password answers the password prompt of the method.
+	Password string
+}
+
+// WithBecome wraps conn so commands and file transfers run as another
+// user. The settings are resolved by become on first use; a nil Become
+// leaves conn unchanged.
+func WithBecome(
+	conn Connection,
+	become func() (*Become, error),
+) Connection {
+	return &becomeConn{
+		conn:    conn,
+		resolve: become,
+	}
+}
+
+// becomeConn runs commands through a become method.
+type becomeConn struct {
+	conn    Connection
+	once    sync.Once
+	resolve func() (*Become, error)
+	become  *Become
+	err     error
+}
+
+// settings resolves the become settings once.
+func (c *becomeConn) settings() (*Become, error) {
+	c.once.Do(func() {
+		c.become, c.err = c.resolve()
+		if c.err == nil && c.become != nil {
+			c.err = c.become.validate()
+		}
+	})
+
+	return c.become, c.err
+}
+
+// Exec runs cmd as the become user.
+func (c *becomeConn) Exec(
+	cmd *Command,
+) (*ExecResult, error) {
+	b, err := c.settings()
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return c.conn.Exec(cmd)
+	}
+
+	return b.exec(c.conn, cmd)
+}
+
+// Put writes src to a temporary file as the login user, then copies it to
+// dst as the become user, so dst is owned by the become user. The temporary
+// file is never readable by everyone: a become user other than root needs
+// setfacl on the host, or a login user allowed to chown.
+func (c *becomeConn) Put(
+	src io.Reader,
+	dst string,
+	mode fs.FileMode,
+) error {
+	b, err := c.settings()
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return c.conn.Put(src, dst, mode)
+	}
+
+	result, err := c.conn.Exec(&Command{Cmd: "mktemp"})
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %w", dst, err)
+	}
+	if result.RC != 0 {
+		return fmt.Errorf("failed to put %s: %s", dst, strings.TrimSpace(string(result.Stderr)))
+	}
+	tmp := strings.TrimSpace(string(result.Stdout))
+	defer func() { _, _ = c.conn.Exec(&Command{Cmd: "rm -f " + ShellQuote(tmp)}) }()
+
+	if err := c.conn.Put(src, tmp, 0o600); err != nil {
+		return err
+	}
+	if user := b.user(); user != "root" {
+		if err := handOff(c.conn, tmp, user); err != nil {
+			return fmt.Errorf("failed to put %s: %w", dst, err)
+		}
+	}
+
+	script := fmt.Sprintf(
+		`dst=$(mktemp %s) && cat %s > "$dst" && chmod %o "$dst" && mv -f "$dst" %s || { rm -f "$dst"; exit 1; }`,
+		ShellQuote(path.Join(path.Dir(dst), ".voidspan-XXXXXX")),
+		ShellQuote(tmp),
+		mode.Perm(),
+		ShellQuote(dst),
+	)
+	result, err = b.exec(c.conn, &Command{Cmd: script})
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %w", dst, err)
+	}
+	if result.RC != 0 {
+		return fmt.Errorf("failed to put %s: %s", dst, strings.TrimSpace(string(result.Stderr)))
+	}
+
+	return nil
+}
+
+// handOff lets user read the temporary file tmp of the login user, through
+// an ACL entry or, when the login user may chown, by giving it to user.
+func handOff(
+	conn Connection,
+	tmp string,
+	user string,
+) error {
+	result, err := conn.Exec(&Command{
+		Cmd: fmt.Sprintf(
+			"setfacl -m %s %s 2>/dev/null || chown %s %s",
+			ShellQuote("u:"+user+":r"),
+			ShellQuote(tmp),
+			ShellQuote(user),
+			ShellQuote(tmp),
+		),
+	})
+	if err != nil {
+		return err
+	}
+	if result.RC != 0 {
+		return fmt.Errorf(
+			"cannot make the temporary file readable by %s without setfacl or chown: %s",
+			user,
+			strings.TrimSpace(string(result.Stderr)),
+		)
+	}
+
+	return nil
+}
+
+// Fetch reads src as the become user.
+func (c *becomeConn) Fetch(
+	src string,
+	dst io.Writer,
+) error {
+	b, err := c.settings()
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return c.conn.Fetch(src, dst)
+	}
+
+	result, err := b.exec(c.conn, &Command{Cmd: "cat " + ShellQuote(src), Stdout: dst})
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", src, err)
+	}
+	if result.RC != 0 {
+		return fmt.Errorf("failed to fetch %s: %s", src, strings.TrimSpace(string(result.Stderr)))
+	}
+
+	return nil
+}
+
+// Close closes the wrapped connection.
+func (c *becomeConn) Close() error {
+	return c.conn.Close()
+}
+
+// validate reports an unsupported become method.
+func (b *Become) validate() error {
+	switch b.method() {
+	case "sudo", "su":
+		return nil
+	default:
+		return fmt.Errorf("unsupported become method %q", b.Method)
+	}
+}
+
+func (b *Become) method() string {
+	if b.Method == "" {
+		return "sudo"
+	}
+	return b.Method
+}
+
+func (b *Become) user() string {
+	if b.User == "" {
+		return "root"
+	}
+	return b.User
+}
+
+func (b *Become) exe() string {
+	if b.Exe == "" {
+		return b.method()
+	}
+	return b.Exe
+}
+
+// command returns the command line running inner as the become user,
+// preceded by an echo of marker, and the prompt matcher of the method.
+// Like Ansible, sudo is given a prompt carrying key when a password is
+// set and runs non-interactively otherwise.
+func (b *Become) command(
+	inner string,
+	marker string,
+	key string,
+) (string, func(string) bool) {
+	shell := "/bin/sh -c " + ShellQuote("echo "+marker+"; "+inner)
+
+	if b.method() == "su" {
+		line := strings.Join(nonEmpty(b.exe(), b.Flags, ShellQuote(b.user()), "-c", ShellQuote(shell)), " ")
+		return line, suPrompt.MatchString
+	}
+
+	flags := b.Flags
+	if flags == "" {
+		flags = "-H -S -n"
+	}
+
+	prompt := "[sudo via voidspan, key=" + key + "] password:"
+	if b.Password != "" {
+		flags = strings.Join(nonEmpty(strings.Fields(strings.ReplaceAll(" "+flags+" ", " -n ", " "))...), " ")
+		flags += " -p " + ShellQuote(prompt)
+	}
+
+	line := strings.Join(nonEmpty(b.exe(), flags, "-u", ShellQuote(b.user()), shell), " ")
+	return line, func(s string) bool { return strings.HasSuffix(s, prompt) }
+}
+
+// exec runs cmd as the become user over conn. It answers the password
+// prompt, then passes cmd.Stdin on once the success marker is printed, and
+// strips everything up to the marker from the output.
+func (b *Become) exec(
+	conn Connection,
+	cmd *Command,
+) (*ExecResult, error) {
+	key, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	marker := "BECOME-SUCCESS-" + key
+
+	line, prompt := b.command(remoteCommand(cmd), marker, key)
+	w := &becomeWatcher{
+		marker:   marker,
+		prompt:   prompt,
+		password: b.Password,
+		stdin:    cmd.Stdin,
+		forward:  cmd.Stdout,
+		events:   make(chan becomeEvent, 4),
+		done:     make(chan struct{}),
+	}
+
+	result, err := conn.Exec(&Command{
+		Cmd:    line,
+		Stdin:  w,
+		Stdout: w.writer(true),
+		Stderr: w.writer(false),
+		TTY:    b.method() == "su",
+	})
+	close(w.done)
+	if err != nil {
+		return nil, err
+	}
+
+	i := bytes.Index(result.Stdout, []byte(marker+"\n"))
+	if i < 0 {
+		return nil, fmt.Errorf("failed to become %s: %s", b.user(), w.failure(result))
+	}
+	result.Stdout = result.Stdout[i+len(marker)+1:]
+
+	return result, nil
+}
+
+// becomeEvent is what the watcher saw in the output of the become method.
+type becomeEvent int
+
+const (
+	// eventPrompt means the method prompted for a password.
+	eventPrompt becomeEvent = iota
+	// eventSuccess means the method succeeded and the command started.
+	eventSuccess
+)
+
+// becomeWatcher scans the output of a become method for its password
+// prompt and success marker, and gates the command's stdin accordingly.
+type becomeWatcher struct {
+	marker   string
+	prompt   func(string) bool
+	password string
+	stdin    io.Reader
+	// forward receives the command's standard output after the marker.
+	forward io.Writer
+	events  chan becomeEvent
+	done    chan struct{}
+
+	mu sync.Mutex
+	// seen holds the output before the marker.
+	seen      strings.Builder
+	succeeded bool
+	// prompts counts the password prompts seen.
+	prompts int
+
+	// pending holds bytes to send before reading on.
+	pending []byte
+	// passing is set once stdin is passed on to the command.
+	passing bool
+	closed  bool
+}
+
+// writer returns a writer scanning a stream of output.
+func (w *becomeWatcher) writer(
+	stdout bool,
+) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		if w.succeeded {
+			if stdout && w.forward != nil {
+				_, _ = w.forward.Write(p)
+			}
+			return len(p), nil
+		}
+
+		w.seen.Write(p)
+		seen := w.seen.String()
+
+		if i := strings.Index(seen, w.marker+"\n"); i >= 0 {
+			w.succeeded = true
+			w.send(eventSuccess)
+			if rest := seen[i+len(w.marker)+1:]; stdout && w.forward != nil && rest != "" {
+				_, _ = io.WriteString(w.forward, rest)
+			}
+			return len(p), nil
+		}
+
+		if w.prompt(strings.TrimRight(seen, " ")) {
+			w.prompts++
+			// keep later prompts from matching this one
+			w.seen.Reset()
+			w.send(eventPrompt)
+		}
+
+		return len(p), nil
+	})
+}
+
+// send passes an event to Read without blocking the output.
+func (w *becomeWatcher) send(
+	event becomeEvent,
+) {
+	select {
+	case w.events <- event:
+	default:
+	}
+}
+
+// Read feeds the password when prompted and the command's stdin after
+// success. It ends the input when the password is missing or rejected,
+// when the method neither prompts nor succeeds in time, or once the
+// command has exited.
+func (w *becomeWatcher) Read(
+	p []byte,
+) (int, error) {
+	for {
+		if len(w.pending) > 0 {
+			n := copy(p, w.pending)
+			w.pending = w.pending[n:]
+			return n, nil
+		}
+		if w.closed {
+			return 0, io.EOF
+		}
+		if w.passing {
+			if w.stdin == nil {
+				return 0, io.EOF
+			}
+			return w.stdin.Read(p)
+		}
+
+		select {
+		case event := <-w.events:
+			switch {
+			case event == eventSuccess:
+				w.passing = true
+			case w.password == "", w.promptCount() > 1:
+				// nothing to answer, or the password was rejected
+				w.closed = true
+			default:
+				w.pending = []byte(w.password + "\n")
+			}
+		case <-time.After(becomeTimeout):
+			w.closed = true
+		case <-w.done:
+			w.closed = true
+		}
+	}
+}
+
+func (w *becomeWatcher) promptCount() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.prompts
+}
+
+// failure describes why the become method did not succeed.
+func (w *becomeWatcher) failure(
+	result *ExecResult,
+) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch {
+	case w.prompts > 0 && w.password == "":
+		return "missing become password"
+	case w.prompts > 0:
+		return "incorrect become password"
+	}
+
+	if msg := strings.TrimSpace(string(result.Stderr)); msg != "" {
+		return msg
+	}
+	if msg := strings.TrimSpace(string(result.Stdout)); msg != "" {
+		return msg
+	}
+	return fmt.Sprintf("exit status %d", result.RC)
+}
+
+// writerFunc adapts a function to io.Writer.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+// randomKey returns a random hex key identifying one become command.
+func randomKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate become key: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// nonEmpty returns the non-empty strings of s.
+func nonEmpty(
+	s ...string,
+) []string {
+	out := make([]string, 0, len(s))
+	for _, v := range s {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
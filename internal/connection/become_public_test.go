@@ -0,0 +1,275 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package connection_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// fakeSudo mimics sudo -S: it prompts on stderr when given -p, allows three
+// attempts, and fails without prompting when run with -n.
+const fakeSudo = `#!/bin/sh
+prompt=""
+nopasswd=""
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -p) prompt=$2; shift 2 ;;
+    -u) echo "$2" > "$(dirname "$0")/user"; shift 2 ;;
+    -n) nopasswd=1; shift ;;
+    -*) shift ;;
+    *) break ;;
+  esac
+done
+[ -e "$(dirname "$0")/nopasswd" ] && exec "$@"
+if [ -n "$nopasswd" ]; then
+  echo "sudo: a password is required" >&2
+  exit 1
+fi
+for attempt in 1 2 3; do
+  printf '%s' "$prompt" >&2
+  IFS= read -r pw || exit 1
+  [ "$pw" = secret ] && exec "$@"
+  echo "Sorry, try again." >&2
+done
+exit 1
+`
+
+// fakeSu mimics su: it prompts once on the terminal.
+const fakeSu = `#!/bin/sh
+[ -t 0 ] || { echo "su: must be run from a terminal"; exit 1; }
+echo "$1" > "$(dirname "$0")/user"
+printf 'Password: '
+IFS= read -r pw || exit 1
+if [ "$pw" != secret ]; then
+  echo "su: Authentication failure"
+  exit 1
+fi
+exec /bin/sh -c "$3"
+`
+
+type BecomePublicTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+}
+
+func (s *BecomePublicTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-become-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "sudo"), []byte(fakeSudo), 0o755))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "su"), []byte(fakeSu), 0o755))
+}
+
+func (s *BecomePublicTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func (s *BecomePublicTestSuite) TestExec() {
+	tests := []struct {
+		name              string
+		become            *connection.Become
+		nopasswd          bool
+		cmd               *connection.Command
+		expectStdout      string
+		expectUser        string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:         "sudo with password",
+			become:       &connection.Become{Password: "secret"},
+			cmd:          &connection.Command{Cmd: "echo hello"},
+			expectStdout: "hello\n",
+			expectUser:   "root",
+		},
+		{
+			name:     "sudo without password",
+			become:   &connection.Become{User: "deploy"},
+			nopasswd: true,
+			cmd: &connection.Command{
+				Cmd: `echo "$GREETING from $(pwd)"`,
+				Env: map[string]string{"GREETING": "hi"},
+				Dir: "/",
+			},
+			expectStdout: "hi from /\n",
+			expectUser:   "deploy",
+		},
+		{
+			name:   "sudo passes stdin after the password",
+			become: &connection.Become{Password: "secret"},
+			cmd: &connection.Command{
+				Cmd:   "tr a-z A-Z",
+				Stdin: strings.NewReader("hello"),
+			},
+			expectStdout: "HELLO",
+		},
+		{
+			name:              "sudo with incorrect password",
+			become:            &connection.Become{Password: "wrong"},
+			cmd:               &connection.Command{Cmd: "echo hello"},
+			expectErr:         true,
+			expectErrContains: "failed to become root: incorrect become password",
+		},
+		{
+			name:              "sudo requiring a password",
+			become:            &connection.Become{},
+			cmd:               &connection.Command{Cmd: "echo hello"},
+			expectErr:         true,
+			expectErrContains: "failed to become root: sudo: a password is required",
+		},
+		{
+			name:         "su with password",
+			become:       &connection.Become{Method: "su", User: "deploy", Password: "secret"},
+			cmd:          &connection.Command{Cmd: "echo hello"},
+			expectStdout: "hello\n",
+			expectUser:   "deploy",
+		},
+		{
+			name:              "su with incorrect password",
+			become:            &connection.Become{Method: "su", Password: "wrong"},
+			cmd:               &connection.Command{Cmd: "echo hello"},
+			expectErr:         true,
+			expectErrContains: "failed to become root: incorrect become password",
+		},
+		{
+			name:              "su without password",
+			become:            &connection.Become{Method: "su"},
+			cmd:               &connection.Command{Cmd: "echo hello"},
+			expectErr:         true,
+			expectErrContains: "failed to become root: missing become password",
+		},
+		{
+			name:              "unsupported method",
+			become:            &connection.Become{Method: "doas"},
+			cmd:               &connection.Command{Cmd: "echo hello"},
+			expectErr:         true,
+			expectErrContains: `unsupported become method "doas"`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			_ = os.Remove(filepath.Join(s.tmpDir, "user"))
+			_ = os.Remove(filepath.Join(s.tmpDir, "nopasswd"))
+			if tc.nopasswd {
+				s.Require().NoError(os.WriteFile(filepath.Join(s.tmpDir, "nopasswd"), nil, 0o644))
+			}
+
+			become := *tc.become
+			become.Exe = filepath.Join(s.tmpDir, become.Method)
+			if tc.become.Method == "" {
+				become.Exe = filepath.Join(s.tmpDir, "sudo")
+			}
+
+			conn := connection.WithBecome(
+				connection.NewLocal(),
+				func() (*connection.Become, error) { return &become, nil },
+			)
+			result, err := conn.Exec(tc.cmd)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(0, result.RC)
+			s.Equal(tc.expectStdout, string(result.Stdout))
+			if tc.expectUser != "" {
+				user, err := os.ReadFile(filepath.Join(s.tmpDir, "user"))
+				s.Require().NoError(err)
+				s.Equal(tc.expectUser+"\n", string(user))
+			}
+		})
+	}
+}
+
+func (s *BecomePublicTestSuite) TestPutAndFetch() {
+	become := &connection.Become{
+		Exe:      filepath.Join(s.tmpDir, "sudo"),
+		Password: "secret",
+	}
+	conn := connection.WithBecome(
+		connection.NewLocal(),
+		func() (*connection.Become, error) { return become, nil },
+	)
+
+	dst := filepath.Join(s.tmpDir, "file.txt")
+	s.Require().NoError(conn.Put(strings.NewReader("content"), dst, 0o640))
+
+	info, err := os.Stat(dst)
+	s.Require().NoError(err)
+	s.Equal(os.FileMode(0o640), info.Mode().Perm())
+
+	var buf bytes.Buffer
+	s.Require().NoError(conn.Fetch(dst, &buf))
+	s.Equal("content", buf.String())
+
+	err = conn.Fetch(filepath.Join(s.tmpDir, "missing"), &buf)
+	s.Error(err)
+	s.Contains(err.Error(), "failed to fetch")
+}
+
+func (s *BecomePublicTestSuite) TestPutUnknownUser() {
+	become := &connection.Become{
+		Exe:      filepath.Join(s.tmpDir, "sudo"),
+		User:     "voidspan-no-such-user",
+		Password: "secret",
+	}
+	conn := connection.WithBecome(
+		connection.NewLocal(),
+		func() (*connection.Become, error) { return become, nil },
+	)
+
+	dst := filepath.Join(s.tmpDir, "file.txt")
+	err := conn.Put(strings.NewReader("content"), dst, 0o640)
+	s.Error(err)
+	s.Contains(err.Error(), "cannot make the temporary file readable by voidspan-no-such-user")
+	s.NoFileExists(dst)
+}
+
+func (s *BecomePublicTestSuite) TestWithoutBecome() {
+	conn := connection.WithBecome(
+		connection.NewLocal(),
+		func() (*connection.Become, error) { return nil, nil },
+	)
+
+	result, err := conn.Exec(&connection.Command{Cmd: "echo hello"})
+	s.Require().NoError(err)
+	s.Equal("hello\n", string(result.Stdout))
+}
+
+func TestBecomePublicTestSuite(t *testing.T) {
+	suite.Run(t, new(BecomePublicTestSuite))
+}
@@ -0,0 +1,446 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Open opens a connection to host using the connection type named by the
+// ansible_connection variable. Without it, localhost uses a local
+// connection and other hosts use ssh.
+func Open(
+	host string,
+	vars map[string]interface{},
+) (Connection, error) {
+	transport, _ := vars["ansible_connection"].(string)
+	if transport == "" {
+		transport = "ssh"
+		if isLocalhost(host) {
+			transport = "local"
+		}
+	}
+
+	switch transport {
+	case "local":
+		return NewLocal(), nil
+	case "ssh":
+		cfg, err := sshConfig(host, vars)
+		if err != nil {
+			return nil, err
+		}
+		return DialSSH(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported connection type %q", transport)
+	}
+}
+
+// sshConfig builds the settings of an SSH connection to host from the
+// ansible_* connection variables. ProxyJump, UserKnownHostsFile and
+// StrictHostKeyChecking are read from the -J and -o options of
+// ansible_ssh_common_args and ansible_ssh_extra_args.
+func sshConfig(
+	host string,
+	vars map[string]interface{},
+) (SSHConfig, error) {
+	cfg := SSHConfig{
+		Host:            host,
+		Port:            22,
+		HostKeyChecking: true,
+		Timeout:         10 * time.Second,
+	}
+
+	if v := stringVar(vars, "ansible_host", "ansible_ssh_host"); v != "" {
+		cfg.Host = v
+	}
+	if v := stringVar(vars, "ansible_port", "ansible_ssh_port"); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return SSHConfig{}, fmt.Errorf("invalid ansible_port %q", v)
+		}
+		cfg.Port = port
+	}
+	if v := stringVar(vars, "ansible_timeout", "ansible_ssh_timeout"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil {
+			return SSHConfig{}, fmt.Errorf("invalid ansible_timeout %q", v)
+		}
+		cfg.Timeout = time.Duration(seconds) * time.Second
+	}
+
+	cfg.User = stringVar(vars, "ansible_user", "ansible_ssh_user")
+	if cfg.User == "" {
+		cfg.User = currentUser()
+	}
+	cfg.Password = stringVar(vars, "ansible_password", "ansible_ssh_pass", "ansible_ssh_password")
+
+	home, _ := os.UserHomeDir()
+	if v := stringVar(vars, "ansible_ssh_private_key_file", "ansible_private_key_file"); v != "" {
+		cfg.IdentityFiles = []string{expandHome(v, home)}
+	} else {
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			file := filepath.Join(home, ".ssh", name)
+			if _, err := os.Stat(file); err == nil {
+				cfg.IdentityFiles = append(cfg.IdentityFiles, file)
+			}
+		}
+	}
+
+	cfg.KnownHostsFiles = []string{
+		filepath.Join(home, ".ssh", "known_hosts"),
+		"/etc/ssh/ssh_known_hosts",
+	}
+	if v := os.Getenv("ANSIBLE_HOST_KEY_CHECKING"); v != "" {
+		cfg.HostKeyChecking = isTrue(v)
+	}
+	if v := stringVar(vars, "ansible_host_key_checking", "ansible_ssh_host_key_checking"); v != "" {
+		cfg.HostKeyChecking = isTrue(v)
+	}
+
+	args, err := SplitArgs(stringVar(vars, "ansible_ssh_common_args") + " " +
+		stringVar(vars, "ansible_ssh_extra_args"))
+	if err != nil {
+		return SSHConfig{}, fmt.Errorf("invalid ssh args: %w", err)
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		var option string
+		switch {
+		case arg == "-J" && i+1 < len(args):
+			i++
+			option = "ProxyJump=" + args[i]
+		case strings.HasPrefix(arg, "-J"):
+			option = "ProxyJump=" + arg[2:]
+		case arg == "-o" && i+1 < len(args):
+			i++
+			option = args[i]
+		case strings.HasPrefix(arg, "-o"):
+			option = arg[2:]
+		default:
+			continue
+		}
+
+		key, value, _ := strings.Cut(option, "=")
+		switch strings.ToLower(key) {
+		case "proxyjump":
+			cfg.ProxyJump = nil
+			if value != "none" {
+				cfg.ProxyJump = strings.Split(value, ",")
+			}
+		case "userknownhostsfile":
+			cfg.KnownHostsFiles = nil
+			for _, file := range strings.Fields(value) {
+				cfg.KnownHostsFiles = append(cfg.KnownHostsFiles, expandHome(file, home))
+			}
+		case "stricthostkeychecking":
+			cfg.HostKeyChecking = isTrue(value)
+		case "proxycommand":
+			return SSHConfig{}, fmt.Errorf("unsupported ssh option ProxyCommand, use ProxyJump")
+		}
+	}
+
+	return cfg, nil
+}
+
+// ShellQuote quotes s for a POSIX shell.
+func ShellQuote(
+	s string,
+) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// SplitArgs splits a command line into arguments the way a POSIX shell
+// does, honoring single quotes, double quotes and backslashes.
+func SplitArgs(
+	line string,
+) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				escaped = true
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// stringVar returns the first of the named variables that is set, as a
+// string.
+func stringVar(
+	vars map[string]interface{},
+	names ...string,
+) string {
+	for _, name := range names {
+		if v, ok := vars[name]; ok && v != nil {
+			if s := fmt.Sprint(v); s != "" {
+				return s
+			}
+		}
+	}
+
+	return ""
+}
+
+// isTrue reports whether a boolean setting is enabled, accepting the
+// spellings of Ansible and OpenSSH.
+func isTrue(
+	v string,
+) bool {
+	switch strings.ToLower(v) {
+	case "false", "no", "off", "0", "n":
+		return false
+	}
+
+	return true
+}
+
+// expandHome replaces a leading ~ in path with home.
+func expandHome(
+	path string,
+	home string,
+) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, path[1:])
+	}
+
+	return path
+}
+
+// currentUser returns the name of the user voidspan runs as.
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+
+	return os.Getenv("USER")
+}
+
+// sortedKeys returns the keys of an environment in sorted order.
+func sortedKeys(
+	env map[string]string,
+) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// isLocalhost reports whether host names the local machine.
+func isLocalhost(
+	host string,
+) bool {
+	switch host {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+
+	return false
+}
+
+// Pool hands out one connection per host and keeps it open until Close.
+// Connections are opened on first use, so tasks that never touch a host do
+// not connect to it.
+type Pool struct {
+	mu    sync.Mutex
+	open  func(host string, vars map[string]interface{}) (Connection, error)
+	conns map[string]*pooled
+}
+
+// NewPool creates a Pool opening connections with Open.
+func NewPool() *Pool {
+	return &Pool{
+		open:  Open,
+		conns: make(map[string]*pooled),
+	}
+}
+
+// Get returns the connection to host. The connection is opened on first
+// use with the variables returned by vars, so templated connection
+// variables are only rendered for hosts a task actually connects to.
+func (p *Pool) Get(
+	host string,
+	vars func() (map[string]interface{}, error),
+) Connection {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, ok := p.conns[host]
+	if !ok {
+		c = &pooled{
+			open: func() (Connection, error) {
+				v, err := vars()
+				if err != nil {
+					return nil, err
+				}
+				return p.open(host, v)
+			},
+		}
+		p.conns[host] = c
+	}
+
+	return c
+}
+
+// Close closes every connection that was opened and returns the first
+// error.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var first error
+	for host, c := range p.conns {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+		delete(p.conns, host)
+	}
+
+	return first
+}
+
+// errClosed is returned by connections used after their pool was closed.
+var errClosed = errors.New("connection pool is closed")
+
+// pooled is a connection opened on first use.
+type pooled struct {
+	once sync.Once
+	open func() (Connection, error)
+	conn Connection
+	err  error
+}
+
+// get opens the connection once and returns it.
+func (c *pooled) get() (Connection, error) {
+	c.once.Do(func() {
+		c.conn, c.err = c.open()
+		if c.err != nil {
+			c.err = fmt.Errorf("failed to connect: %w", c.err)
+		}
+	})
+
+	return c.conn, c.err
+}
+
+// Exec opens the connection if needed and runs cmd.
+func (c *pooled) Exec(
+	cmd *Command,
+) (*ExecResult, error) {
+	conn, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.Exec(cmd)
+}
+
+// Put opens the connection if needed and writes src to dst.
+func (c *pooled) Put(
+	src io.Reader,
+	dst string,
+	mode fs.FileMode,
+) error {
+	conn, err := c.get()
+	if err != nil {
+		return err
+	}
+
+	return conn.Put(src, dst, mode)
+}
+
+// Fetch opens the connection if needed and reads src into dst.
+func (c *pooled) Fetch(
+	src string,
+	dst io.Writer,
+) error {
+	conn, err := c.get()
+	if err != nil {
+		return err
+	}
+
+	return conn.Fetch(src, dst)
+}
+
+// Close closes the connection if it was opened and keeps it from being
+// opened later.
+func (c *pooled) Close() error {
+	c.once.Do(func() {
+		c.err = errClosed
+	})
+	if c.conn == nil {
+		return nil
+	}
+
+	return c.conn.Close()
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package connection_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+type ConnectionPublicTestSuite struct {
+	suite.Suite
+}
+
+func (s *ConnectionPublicTestSuite) TestOpen() {
+	tests := []struct {
+		name              string
+		host              string
+		vars              map[string]interface{}
+		expectLocal       bool
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:        "localhost",
+			host:        "localhost",
+			vars:        map[string]interface{}{},
+			expectLocal: true,
+		},
+		{
+			name:        "loopback address",
+			host:        "127.0.0.1",
+			vars:        map[string]interface{}{},
+			expectLocal: true,
+		},
+		{
+			name:        "ansible_connection local",
+			host:        "web1",
+			vars:        map[string]interface{}{"ansible_connection": "local"},
+			expectLocal: true,
+		},
+		{
+			name:              "unsupported connection",
+			host:              "web1",
+			vars:              map[string]interface{}{"ansible_connection": "winrm"},
+			expectErr:         true,
+			expectErrContains: `unsupported connection type "winrm"`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			got, err := connection.Open(tc.host, tc.vars)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			_, isLocal := got.(*connection.Local)
+			s.Equal(tc.expectLocal, isLocal)
+		})
+	}
+}
+
+func (s *ConnectionPublicTestSuite) TestPool() {
+	pool := connection.NewPool()
+
+	local := pool.Get("localhost", vars(map[string]interface{}{}))
+	s.Same(local, pool.Get("localhost", vars(map[string]interface{}{})))
+
+	result, err := local.Exec(&connection.Command{Cmd: "echo ok"})
+	s.Require().NoError(err)
+	s.Equal("ok\n", string(result.Stdout))
+
+	// opening fails on first use rather than on Get
+	broken := pool.Get("web1", vars(map[string]interface{}{"ansible_connection": "winrm"}))
+	_, err = broken.Exec(&connection.Command{Cmd: "true"})
+	s.Error(err)
+	s.Contains(err.Error(), `failed to connect: unsupported connection type "winrm"`)
+
+	failing := pool.Get("app1", func() (map[string]interface{}, error) {
+		return nil, fmt.Errorf("undefined variable")
+	})
+	_, err = failing.Exec(&connection.Command{Cmd: "true"})
+	s.Error(err)
+	s.Contains(err.Error(), "failed to connect: undefined variable")
+
+	unused := pool.Get("db1", vars(map[string]interface{}{"ansible_connection": "local"}))
+	s.Require().NoError(pool.Close())
+
+	_, err = unused.Exec(&connection.Command{Cmd: "true"})
+	s.Error(err)
+	s.Contains(err.Error(), "connection pool is closed")
+}
+
+func (s *ConnectionPublicTestSuite) TestSplitArgs() {
+	tests := []struct {
+		name              string
+		line              string
+		expected          []string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:     "splits on whitespace",
+			line:     " ls  -l\t/tmp ",
+			expected: []string{"ls", "-l", "/tmp"},
+		},
+		{
+			name:     "honors quotes and backslashes",
+			line:     `echo 'a b' "c \"d\"" e\ f`,
+			expected: []string{"echo", "a b", `c "d"`, "e f"},
+		},
+		{
+			name:     "keeps empty quoted args",
+			line:     `printf ''`,
+			expected: []string{"printf", ""},
+		},
+		{
+			name:              "unterminated quote",
+			line:              `echo 'a`,
+			expectErr:         true,
+			expectErrContains: "unterminated quote",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			args, err := connection.SplitArgs(tc.line)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, args)
+		})
+	}
+}
+
+func (s *ConnectionPublicTestSuite) TestShellQuote() {
+	for _, arg := range []string{"", "plain", "a b", "it's", `"$HOME" \n`} {
+		args, err := connection.SplitArgs("echo " + connection.ShellQuote(arg))
+
+		s.Require().NoError(err)
+		s.Equal([]string{"echo", arg}, args)
+	}
+}
+
+// vars returns a vars func for Pool.Get returning v.
+func vars(
+	v map[string]interface{},
+) func() (map[string]interface{}, error) {
+	return func() (map[string]interface{}, error) {
+		return v, nil
+	}
+}
+
+func TestConnectionPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(ConnectionPublicTestSuite))
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package connection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConnectionTestSuite struct {
+	suite.Suite
+
+	home string
+}
+
+func (s *ConnectionTestSuite) SetupTest() {
+	s.home = s.T().TempDir()
+	s.T().Setenv("HOME", s.home)
+	s.T().Setenv("ANSIBLE_HOST_KEY_CHECKING", "")
+}
+
+func (s *ConnectionTestSuite) TestSSHConfig() {
+	knownHosts := []string{
+		filepath.Join(s.home, ".ssh", "known_hosts"),
+		"/etc/ssh/ssh_known_hosts",
+	}
+
+	tests := []struct {
+		name              string
+		vars              map[string]interface{}
+		env               map[string]string
+		expected          SSHConfig
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "connection variables",
+			vars: map[string]interface{}{
+				"ansible_host":                 "10.0.0.5",
+				"ansible_port":                 2222,
+				"ansible_user":                 "deploy",
+				"ansible_password":             "secret",
+				"ansible_ssh_private_key_file": "~/.ssh/deploy",
+				"ansible_timeout":              "30",
+			},
+			expected: SSHConfig{
+				Host:            "10.0.0.5",
+				Port:            2222,
+				User:            "deploy",
+				Password:        "secret",
+				IdentityFiles:   []string{filepath.Join(s.home, ".ssh", "deploy")},
+				KnownHostsFiles: knownHosts,
+				HostKeyChecking: true,
+				Timeout:         30 * time.Second,
+			},
+		},
+		{
+			name: "ssh args",
+			vars: map[string]interface{}{
+				"ansible_ssh_common_args": "-o ProxyJump=jump1,admin@jump2:2200 -oUserKnownHostsFile='~/hosts /tmp/hosts'",
+				"ansible_ssh_extra_args":  "-o StrictHostKeyChecking=no",
+			},
+			expected: SSHConfig{
+				Host:            "web1",
+				Port:            22,
+				User:            currentUser(),
+				KnownHostsFiles: []string{filepath.Join(s.home, "hosts"), "/tmp/hosts"},
+				ProxyJump:       []string{"jump1", "admin@jump2:2200"},
+				Timeout:         10 * time.Second,
+			},
+		},
+		{
+			name: "-J and ProxyJump none",
+			vars: map[string]interface{}{
+				"ansible_ssh_common_args": "-J bastion",
+				"ansible_ssh_extra_args":  "-o ProxyJump=none -Jother",
+			},
+			expected: SSHConfig{
+				Host:            "web1",
+				Port:            22,
+				User:            currentUser(),
+				KnownHostsFiles: knownHosts,
+				HostKeyChecking: true,
+				ProxyJump:       []string{"other"},
+				Timeout:         10 * time.Second,
+			},
+		},
+		{
+			name: "host key checking from environment",
+			vars: map[string]interface{}{},
+			env:  map[string]string{"ANSIBLE_HOST_KEY_CHECKING": "False"},
+			expected: SSHConfig{
+				Host:            "web1",
+				Port:            22,
+				User:            currentUser(),
+				KnownHostsFiles: knownHosts,
+				Timeout:         10 * time.Second,
+			},
+		},
+		{
+			name: "unterminated quote",
+			vars: map[string]interface{}{
+				"ansible_ssh_extra_args": `-o "ProxyJump=bastion`,
+			},
+			expectErr:         true,
+			expectErrContains: "invalid ssh args: unterminated quote",
+		},
+		{
+			name: "invalid timeout",
+			vars: map[string]interface{}{
+				"ansible_timeout": "soon",
+			},
+			expectErr:         true,
+			expectErrContains: `invalid ansible_timeout "soon"`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			for k, v := range tc.env {
+				s.T().Setenv(k, v)
+			}
+
+			got, err := sshConfig("web1", tc.vars)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, got)
+		})
+	}
+}
+
+func (s *ConnectionTestSuite) TestSSHConfigDefaultIdentities() {
+	sshDir := filepath.Join(s.home, ".ssh")
+	s.Require().NoError(os.MkdirAll(sshDir, 0o700))
+	for _, name := range []string{"id_rsa", "id_ed25519"} {
+		s.Require().NoError(os.WriteFile(filepath.Join(sshDir, name), nil, 0o600))
+	}
+
+	got, err := sshConfig("web1", map[string]interface{}{})
+	s.Require().NoError(err)
+
+	s.Equal([]string{
+		filepath.Join(sshDir, "id_ed25519"),
+		filepath.Join(sshDir, "id_rsa"),
+	}, got.IdentityFiles)
+}
+
+func (s *ConnectionTestSuite) TestParseHop() {
+	tests := []struct {
+		name              string
+		spec              string
+		expected          sshHop
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:     "host",
+			spec:     "bastion",
+			expected: sshHop{user: "deploy", addr: "bastion:22"},
+		},
+		{
+			name:     "user host and port",
+			spec:     "admin@bastion:2200",
+			expected: sshHop{user: "admin", addr: "bastion:2200"},
+		},
+		{
+			name:     "IPv6",
+			spec:     "[::1]:2200",
+			expected: sshHop{user: "deploy", addr: "[::1]:2200"},
+		},
+		{
+			name:              "empty host",
+			spec:              "admin@",
+			expectErr:         true,
+			expectErrContains: `invalid jump host ""`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			got, err := parseHop(tc.spec, "deploy")
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, got)
+		})
+	}
+}
+
+func TestConnectionTestSuite(t *testing.T) {
+	suite.Run(t, new(ConnectionTestSuite))
+}
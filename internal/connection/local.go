@@ -0,0 +1,170 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package connection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Local runs commands and transfers files on the machine voidspan runs on.
+type Local struct{}
+
+// NewLocal creates a local connection.
+func NewLocal() *Local {
+	return &Local{}
+}
+
+// Exec runs the command with /bin/sh -c in the environment of the current
+// process, extended with cmd.Env. Stdin is fed from a separate goroutine,
+// so Exec returns once the command exits even if Stdin blocks.
+func (l *Local) Exec(
+	cmd *Command,
+) (*ExecResult, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.Command("/bin/sh", "-c", cmd.Cmd)
+	c.Dir = cmd.Dir
+
+	c.Env = os.Environ()
+	for _, k := range sortedKeys(cmd.Env) {
+		c.Env = append(c.Env, k+"="+cmd.Env[k])
+	}
+
+	var err error
+	if cmd.TTY {
+		err = runTTY(c, cmd.Stdin, teeWriter(&stdout, cmd.Stdout))
+	} else {
+		err = runPiped(c, cmd.Stdin, teeWriter(&stdout, cmd.Stdout), teeWriter(&stderr, cmd.Stderr))
+	}
+
+	result := &ExecResult{}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to run command: %w", err)
+		}
+		result.RC = exitErr.ExitCode()
+	}
+	result.Stdout = stdout.Bytes()
+	result.Stderr = stderr.Bytes()
+
+	return result, nil
+}
+
+// runPiped runs c with its output copied to stdout and stderr.
+func runPiped(
+	c *exec.Cmd,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) error {
+	c.Stdout = stdout
+	c.Stderr = stderr
+
+	if stdin == nil {
+		return c.Run()
+	}
+
+	pipe, err := c.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := c.Start(); err != nil {
+		return err
+	}
+	go func() {
+		_, _ = io.Copy(pipe, stdin)
+		_ = pipe.Close()
+	}()
+
+	return c.Wait()
+}
+
+// teeWriter returns a writer writing to buf and, when set, to w.
+func teeWriter(
+	buf io.Writer,
+	w io.Writer,
+) io.Writer {
+	if w == nil {
+		return buf
+	}
+
+	return io.MultiWriter(buf, w)
+}
+
+// Put writes src to a temporary file next to dst and renames it into place,
+// so dst is never left partially written.
+func (l *Local) Put(
+	src io.Reader,
+	dst string,
+	mode fs.FileMode,
+) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".voidspan-*")
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %w", dst, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to put %s: %w", dst, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to put %s: %w", dst, err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to put %s: %w", dst, err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("failed to put %s: %w", dst, err)
+	}
+
+	return nil
+}
+
+// Fetch copies the file src to dst.
+func (l *Local) Fetch(
+	src string,
+	dst io.Writer,
+) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", src, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := io.Copy(dst, f); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", src, err)
+	}
+
+	return nil
+}
+
+// Close does nothing; a local connection holds no resources.
+func (l *Local) Close() error {
+	return nil
+}
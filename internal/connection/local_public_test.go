@@ -0,0 +1,160 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package connection_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+type LocalPublicTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+	conn   *connection.Local
+}
+
+func (s *LocalPublicTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-local-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+	s.conn = connection.NewLocal()
+}
+
+func (s *LocalPublicTestSuite) TearDownTest() {
+	_ = s.conn.Close()
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func (s *LocalPublicTestSuite) TestExec() {
+	tests := []struct {
+		name         string
+		cmd          *connection.Command
+		expectRC     int
+		expectStdout string
+		expectStderr string
+	}{
+		{
+			name:         "stdout and stderr",
+			cmd:          &connection.Command{Cmd: "echo out; echo err >&2"},
+			expectStdout: "out\n",
+			expectStderr: "err\n",
+		},
+		{
+			name:     "non-zero exit",
+			cmd:      &connection.Command{Cmd: "exit 3"},
+			expectRC: 3,
+		},
+		{
+			name: "stdin",
+			cmd: &connection.Command{
+				Cmd:   "tr a-z A-Z",
+				Stdin: strings.NewReader("hello"),
+			},
+			expectStdout: "HELLO",
+		},
+		{
+			name: "env",
+			cmd: &connection.Command{
+				Cmd: `printf "%s %s" "$GREETING" "$NAME"`,
+				Env: map[string]string{"GREETING": "hi", "NAME": "voidspan"},
+			},
+			expectStdout: "hi voidspan",
+		},
+		{
+			name:         "dir",
+			cmd:          &connection.Command{Cmd: "basename $(pwd)"},
+			expectStdout: "work\n",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			if tc.name == "dir" {
+				tc.cmd.Dir = filepath.Join(s.tmpDir, "work")
+				s.Require().NoError(os.MkdirAll(tc.cmd.Dir, 0o755))
+			}
+
+			got, err := s.conn.Exec(tc.cmd)
+
+			s.Require().NoError(err)
+			s.Equal(tc.expectRC, got.RC)
+			s.Equal(tc.expectStdout, string(got.Stdout))
+			s.Equal(tc.expectStderr, string(got.Stderr))
+		})
+	}
+}
+
+func (s *LocalPublicTestSuite) TestExecMissingDir() {
+	_, err := s.conn.Exec(&connection.Command{
+		Cmd: "true",
+		Dir: filepath.Join(s.tmpDir, "missing"),
+	})
+
+	s.Error(err)
+	s.Contains(err.Error(), "failed to run command")
+}
+
+func (s *LocalPublicTestSuite) TestPutAndFetch() {
+	dst := filepath.Join(s.tmpDir, "file.txt")
+	s.Require().NoError(os.WriteFile(dst, []byte("old"), 0o644))
+
+	err := s.conn.Put(strings.NewReader("new content"), dst, 0o600)
+	s.Require().NoError(err)
+
+	info, err := os.Stat(dst)
+	s.Require().NoError(err)
+	s.Equal(os.FileMode(0o600), info.Mode().Perm())
+
+	var buf bytes.Buffer
+	s.Require().NoError(s.conn.Fetch(dst, &buf))
+	s.Equal("new content", buf.String())
+
+	entries, err := os.ReadDir(s.tmpDir)
+	s.Require().NoError(err)
+	s.Len(entries, 1)
+}
+
+func (s *LocalPublicTestSuite) TestPutMissingDir() {
+	err := s.conn.Put(strings.NewReader("x"), filepath.Join(s.tmpDir, "missing", "f"), 0o644)
+
+	s.Error(err)
+	s.Contains(err.Error(), "failed to put")
+}
+
+func (s *LocalPublicTestSuite) TestFetchMissingFile() {
+	var buf bytes.Buffer
+	err := s.conn.Fetch(filepath.Join(s.tmpDir, "missing"), &buf)
+
+	s.Error(err)
+	s.Contains(err.Error(), "failed to fetch")
+}
+
+func TestLocalPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(LocalPublicTestSuite))
+}
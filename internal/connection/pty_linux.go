@@ -0,0 +1,114 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+//go:build linux
+
+package connection
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// runTTY runs c in a new pseudo-terminal with echo and output newline
+// translation disabled, feeding it stdin and copying its output to stdout.
+func runTTY(
+	c *exec.Cmd,
+	stdin io.Reader,
+	stdout io.Writer,
+) error {
+	master, slave, err := openPTY()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = master.Close() }()
+
+	c.Stdin, c.Stdout, c.Stderr = slave, slave, slave
+	c.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if err := c.Start(); err != nil {
+		_ = slave.Close()
+		return err
+	}
+	_ = slave.Close()
+
+	go func() {
+		if stdin != nil {
+			_, _ = io.Copy(master, stdin)
+		}
+		_, _ = io.WriteString(master, ttyEOF)
+	}()
+
+	// reading the master fails with EIO once the command closes the
+	// terminal
+	copied := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(stdout, master)
+		close(copied)
+	}()
+
+	err = c.Wait()
+	<-copied
+
+	return err
+}
+
+// openPTY opens a new pseudo-terminal pair.
+func openPTY() (*os.File, *os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open pseudo-terminal: %w", err)
+	}
+
+	fd := int(master.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		_ = master.Close()
+		return nil, nil, fmt.Errorf("failed to unlock pseudo-terminal: %w", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		_ = master.Close()
+		return nil, nil, fmt.Errorf("failed to get pseudo-terminal number: %w", err)
+	}
+
+	slave, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		_ = master.Close()
+		return nil, nil, fmt.Errorf("failed to open pseudo-terminal: %w", err)
+	}
+
+	termios, err := unix.IoctlGetTermios(int(slave.Fd()), unix.TCGETS)
+	if err == nil {
+		termios.Lflag &^= unix.ECHO
+		termios.Oflag &^= unix.ONLCR
+		err = unix.IoctlSetTermios(int(slave.Fd()), unix.TCSETS, termios)
+	}
+	if err != nil {
+		_ = master.Close()
+		_ = slave.Close()
+		return nil, nil, fmt.Errorf("failed to configure pseudo-terminal: %w", err)
+	}
+
+	return master, slave, nil
+}
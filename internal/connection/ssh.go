@@ -0,0 +1,399 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package connection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHConfig holds the settings of an SSH connection.
+type SSHConfig struct {
+	// Host is the address of the host.
+	Host string
+	// Port is the SSH port of the host.
+	Port int
+	// User is the user to log in as, on the host and on jump hosts without
+	// an explicit user.
+	User string
+	// Password, when set, is offered for password authentication.
+	Password string
+	// IdentityFiles lists the private keys offered for public key
+	// authentication, in addition to the keys of the SSH agent.
+	IdentityFiles []string
+	// KnownHostsFiles lists the known_hosts files host keys are checked
+	// against.
+	KnownHostsFiles []string
+	// HostKeyChecking enables host key verification.
+	HostKeyChecking bool
+	// ProxyJump lists the jump hosts, as [user@]host[:port], connected
+	// through in order.
+	ProxyJump []string
+	// Timeout bounds connecting to each host.
+	Timeout time.Duration
+}
+
+// SSH runs commands and transfers files on a host over SSH. Files are
+// transferred through the remote shell, so the host needs no SFTP server.
+type SSH struct {
+	client *ssh.Client
+	// clients holds the client of every hop, jump hosts first.
+	clients []*ssh.Client
+}
+
+// DialSSH connects to the host of cfg, through its jump hosts if any.
+func DialSSH(
+	cfg SSHConfig,
+) (*SSH, error) {
+	auth, closeAgent, err := cfg.authMethods()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent()
+
+	hostKeys, err := cfg.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	hops := make([]sshHop, 0, len(cfg.ProxyJump)+1)
+	for _, jump := range cfg.ProxyJump {
+		hop, err := parseHop(jump, cfg.User)
+		if err != nil {
+			return nil, err
+		}
+		hops = append(hops, hop)
+	}
+	hops = append(hops, sshHop{user: cfg.User, addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))})
+
+	conn := &SSH{}
+	for _, hop := range hops {
+		var netConn net.Conn
+		if conn.client == nil {
+			netConn, err = net.DialTimeout("tcp", hop.addr, cfg.Timeout)
+		} else {
+			netConn, err = conn.client.Dial("tcp", hop.addr)
+		}
+		if err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed to dial %s: %w", hop.addr, err)
+		}
+
+		c, chans, reqs, err := ssh.NewClientConn(netConn, hop.addr, &ssh.ClientConfig{
+			User:            hop.user,
+			Auth:            auth,
+			HostKeyCallback: hostKeys,
+			Timeout:         cfg.Timeout,
+		})
+		if err != nil {
+			_ = netConn.Close()
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed to connect to %s: %w", hop.addr, err)
+		}
+
+		conn.client = ssh.NewClient(c, chans, reqs)
+		conn.clients = append(conn.clients, conn.client)
+	}
+
+	return conn, nil
+}
+
+// sshHop is a host on the way to the target, the target included.
+type sshHop struct {
+	user string
+	addr string
+}
+
+// parseHop parses a jump host given as [user@]host[:port].
+func parseHop(
+	spec string,
+	defaultUser string,
+) (sshHop, error) {
+	hop := sshHop{user: defaultUser}
+	if at := strings.LastIndex(spec, "@"); at >= 0 {
+		hop.user, spec = spec[:at], spec[at+1:]
+	}
+
+	host, port := spec, "22"
+	if h, p, err := net.SplitHostPort(spec); err == nil {
+		host, port = h, p
+	}
+	if host == "" {
+		return sshHop{}, fmt.Errorf("invalid jump host %q", spec)
+	}
+	hop.addr = net.JoinHostPort(host, port)
+
+	return hop, nil
+}
+
+// authMethods returns the password and public key authentication methods
+// of cfg. Public keys come from the identity files, then from the SSH agent
+// at SSH_AUTH_SOCK. The returned func closes the agent connection, which
+// must stay open until authentication is done.
+func (cfg SSHConfig) authMethods() ([]ssh.AuthMethod, func(), error) {
+	var signers []ssh.Signer
+	for _, file := range cfg.IdentityFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read private key: %w", err)
+		}
+
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse private key %s: %w", file, err)
+		}
+		signers = append(signers, signer)
+	}
+
+	closeAgent := func() {}
+	var agentClient agent.ExtendedAgent
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if agentConn, err := net.Dial("unix", sock); err == nil {
+			agentClient = agent.NewClient(agentConn)
+			closeAgent = func() { _ = agentConn.Close() }
+		}
+	}
+
+	publicKeys := ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		if agentClient == nil {
+			return signers, nil
+		}
+
+		agentSigners, err := agentClient.Signers()
+		if err != nil {
+			return signers, nil
+		}
+		return append(signers, agentSigners...), nil
+	})
+
+	methods := []ssh.AuthMethod{publicKeys}
+	if cfg.Password != "" {
+		methods = append(methods, ssh.Password(cfg.Password))
+	}
+
+	return methods, closeAgent, nil
+}
+
+// hostKeyCallback checks host keys against the known_hosts files, or
+// accepts any key when host key checking is disabled. Missing known_hosts
+// files are skipped, so no host is known without one.
+func (cfg SSHConfig) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if !cfg.HostKeyChecking {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	var files []string
+	for _, file := range cfg.KnownHostsFiles {
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+
+	callback, err := knownhosts.New(files...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if err := callback(hostname, remote, key); err != nil {
+			var keyErr *knownhosts.KeyError
+			if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+				return fmt.Errorf("host key verification failed: %s is not a known host", hostname)
+			}
+			return fmt.Errorf("host key verification failed: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// Exec runs the command with /bin/sh -c in the login environment of the
+// user, extended with cmd.Env.
+func (c *SSH) Exec(
+	cmd *Command,
+) (*ExecResult, error) {
+	var stdout, stderr bytes.Buffer
+	rc, err := c.run(
+		remoteCommand(cmd),
+		cmd.Stdin,
+		teeWriter(&stdout, cmd.Stdout),
+		teeWriter(&stderr, cmd.Stderr),
+		cmd.TTY,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run command: %w", err)
+	}
+
+	return &ExecResult{
+		RC:     rc,
+		Stdout: stdout.Bytes(),
+		Stderr: stderr.Bytes(),
+	}, nil
+}
+
+// Put streams src to a temporary file next to dst on the host and renames
+// it into place, so dst is never left partially written.
+func (c *SSH) Put(
+	src io.Reader,
+	dst string,
+	mode fs.FileMode,
+) error {
+	script := fmt.Sprintf(
+		`tmp=$(mktemp %s) && cat > "$tmp" && chmod %o "$tmp" && mv -f "$tmp" %s || { rm -f "$tmp"; exit 1; }`,
+		ShellQuote(path.Join(path.Dir(dst), ".voidspan-XXXXXX")),
+		mode.Perm(),
+		ShellQuote(dst),
+	)
+
+	var stderr bytes.Buffer
+	rc, err := c.run(script, src, io.Discard, &stderr, false)
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %w", dst, err)
+	}
+	if rc != 0 {
+		return fmt.Errorf("failed to put %s: %s", dst, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// Fetch streams the file src on the host to dst.
+func (c *SSH) Fetch(
+	src string,
+	dst io.Writer,
+) error {
+	var stderr bytes.Buffer
+	rc, err := c.run("cat "+ShellQuote(src), nil, dst, &stderr, false)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", src, err)
+	}
+	if rc != 0 {
+		return fmt.Errorf("failed to fetch %s: %s", src, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// Close closes the connection to the host and to its jump hosts.
+func (c *SSH) Close() error {
+	var first error
+	for i := len(c.clients) - 1; i >= 0; i-- {
+		if err := c.clients[i].Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	c.clients = nil
+
+	return first
+}
+
+// run runs a command line in a new session and returns its exit code. Stdin
+// is fed from a separate goroutine, so run returns once the command exits
+// even if stdin blocks. With tty, the session gets a pseudo-terminal with
+// echo and output newline translation disabled.
+func (c *SSH) run(
+	command string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+	tty bool,
+) (int, error) {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open session: %w", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	if tty {
+		modes := ssh.TerminalModes{ssh.ECHO: 0, ssh.ONLCR: 0}
+		if err := session.RequestPty("xterm", 24, 80, modes); err != nil {
+			return 0, fmt.Errorf("failed to request pseudo-terminal: %w", err)
+		}
+		if stdin == nil {
+			stdin = strings.NewReader("")
+		}
+		stdin = io.MultiReader(stdin, strings.NewReader(ttyEOF))
+	}
+
+	var pipe io.WriteCloser
+	if stdin != nil {
+		if pipe, err = session.StdinPipe(); err != nil {
+			return 0, fmt.Errorf("failed to open stdin: %w", err)
+		}
+	}
+
+	if err := session.Start(command); err != nil {
+		return 0, err
+	}
+	if pipe != nil {
+		go func() {
+			_, _ = io.Copy(pipe, stdin)
+			_ = pipe.Close()
+		}()
+	}
+
+	if err := session.Wait(); err != nil {
+		var exitErr *ssh.ExitError
+		if !errors.As(err, &exitErr) {
+			return 0, err
+		}
+		return exitErr.ExitStatus(), nil
+	}
+
+	return 0, nil
+}
+
+// remoteCommand returns the command line running cmd on a remote shell.
+// The environment is set with env rather than SSH requests, which most
+// servers reject.
+func remoteCommand(
+	cmd *Command,
+) string {
+	var b strings.Builder
+	if cmd.Dir != "" {
+		b.WriteString("cd " + ShellQuote(cmd.Dir) + " && ")
+	}
+	if len(cmd.Env) > 0 {
+		b.WriteString("env")
+		for _, k := range sortedKeys(cmd.Env) {
+			b.WriteString(" " + ShellQuote(k+"="+cmd.Env[k]))
+		}
+		b.WriteString(" ")
+	}
+	b.WriteString("/bin/sh -c " + ShellQuote(cmd.Cmd))
+
+	return b.String()
+}
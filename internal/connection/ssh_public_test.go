@@ -0,0 +1,598 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package connection_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+type SSHPublicTestSuite struct {
+	suite.Suite
+
+	tmpDir     string
+	clientKey  ed25519.PrivateKey
+	keyFile    string
+	knownHosts string
+	server     *sshServer
+}
+
+func (s *SSHPublicTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-ssh-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+
+	// keep the user's keys, agent and known hosts out of the tests
+	s.T().Setenv("HOME", dir)
+	s.T().Setenv("SSH_AUTH_SOCK", "")
+	s.T().Setenv("ANSIBLE_HOST_KEY_CHECKING", "")
+
+	_, s.clientKey, err = ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+	block, err := ssh.MarshalPrivateKey(s.clientKey, "")
+	s.Require().NoError(err)
+	s.keyFile = filepath.Join(dir, "id_test")
+	s.Require().NoError(os.WriteFile(s.keyFile, pem.EncodeToMemory(block), 0o600))
+
+	s.server = s.newServer()
+	s.knownHosts = filepath.Join(dir, "known_hosts")
+	s.trust(s.server)
+}
+
+func (s *SSHPublicTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+// vars returns connection variables reaching srv as user deploy with the
+// client key.
+func (s *SSHPublicTestSuite) vars(
+	srv *sshServer,
+) map[string]interface{} {
+	return map[string]interface{}{
+		"ansible_host":                 "127.0.0.1",
+		"ansible_port":                 srv.port,
+		"ansible_user":                 "deploy",
+		"ansible_ssh_private_key_file": s.keyFile,
+		"ansible_ssh_common_args":      "-o UserKnownHostsFile=" + s.knownHosts,
+	}
+}
+
+// trust adds the host key of srv to the known_hosts file.
+func (s *SSHPublicTestSuite) trust(
+	srv *sshServer,
+) {
+	f, err := os.OpenFile(s.knownHosts, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	s.Require().NoError(err)
+	defer func() { _ = f.Close() }()
+
+	_, err = fmt.Fprintln(f, knownhosts.Line([]string{srv.addr}, srv.hostKey.PublicKey()))
+	s.Require().NoError(err)
+}
+
+func (s *SSHPublicTestSuite) TestExec() {
+	conn, err := connection.Open("web1", s.vars(s.server))
+	s.Require().NoError(err)
+	defer func() { _ = conn.Close() }()
+
+	workDir := filepath.Join(s.tmpDir, "work dir")
+	s.Require().NoError(os.MkdirAll(workDir, 0o755))
+
+	tests := []struct {
+		name         string
+		cmd          *connection.Command
+		expectRC     int
+		expectStdout string
+		expectStderr string
+	}{
+		{
+			name:         "stdout and stderr",
+			cmd:          &connection.Command{Cmd: "echo out; echo err >&2"},
+			expectStdout: "out\n",
+			expectStderr: "err\n",
+		},
+		{
+			name:     "non-zero exit",
+			cmd:      &connection.Command{Cmd: "exit 3"},
+			expectRC: 3,
+		},
+		{
+			name: "stdin",
+			cmd: &connection.Command{
+				Cmd:   "tr a-z A-Z",
+				Stdin: strings.NewReader("hello"),
+			},
+			expectStdout: "HELLO",
+		},
+		{
+			name: "env with quotes",
+			cmd: &connection.Command{
+				Cmd: `printf "%s" "$GREETING"`,
+				Env: map[string]string{"GREETING": "it's here"},
+			},
+			expectStdout: "it's here",
+		},
+		{
+			name:         "dir",
+			cmd:          &connection.Command{Cmd: "pwd", Dir: workDir},
+			expectStdout: workDir + "\n",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			got, err := conn.Exec(tc.cmd)
+
+			s.Require().NoError(err)
+			s.Equal(tc.expectRC, got.RC)
+			s.Equal(tc.expectStdout, string(got.Stdout))
+			s.Equal(tc.expectStderr, string(got.Stderr))
+		})
+	}
+}
+
+func (s *SSHPublicTestSuite) TestPutAndFetch() {
+	conn, err := connection.Open("web1", s.vars(s.server))
+	s.Require().NoError(err)
+	defer func() { _ = conn.Close() }()
+
+	dst := filepath.Join(s.tmpDir, "it's a file")
+	s.Require().NoError(conn.Put(strings.NewReader("remote content"), dst, 0o640))
+
+	info, err := os.Stat(dst)
+	s.Require().NoError(err)
+	s.Equal(os.FileMode(0o640), info.Mode().Perm())
+
+	var buf bytes.Buffer
+	s.Require().NoError(conn.Fetch(dst, &buf))
+	s.Equal("remote content", buf.String())
+
+	err = conn.Put(strings.NewReader("x"), filepath.Join(s.tmpDir, "missing", "f"), 0o644)
+	s.Error(err)
+	s.Contains(err.Error(), "failed to put")
+
+	err = conn.Fetch(filepath.Join(s.tmpDir, "missing"), &buf)
+	s.Error(err)
+	s.Contains(err.Error(), "No such file or directory")
+}
+
+func (s *SSHPublicTestSuite) TestBecome() {
+	conn, err := connection.Open("web1", s.vars(s.server))
+	s.Require().NoError(err)
+
+	sudo := filepath.Join(s.tmpDir, "sudo")
+	s.Require().NoError(os.WriteFile(sudo, []byte(fakeSudo), 0o755))
+
+	become := connection.WithBecome(conn, func() (*connection.Become, error) {
+		return &connection.Become{Exe: sudo, Password: "secret"}, nil
+	})
+	defer func() { _ = become.Close() }()
+
+	got, err := become.Exec(&connection.Command{
+		Cmd:   "tr a-z A-Z",
+		Stdin: strings.NewReader("hello"),
+	})
+	s.Require().NoError(err)
+	s.Equal("HELLO", string(got.Stdout))
+
+	dst := filepath.Join(s.tmpDir, "owned")
+	s.Require().NoError(become.Put(strings.NewReader("as root"), dst, 0o600))
+
+	var buf bytes.Buffer
+	s.Require().NoError(become.Fetch(dst, &buf))
+	s.Equal("as root", buf.String())
+}
+
+func (s *SSHPublicTestSuite) TestOpen() {
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+	block, err := ssh.MarshalPrivateKey(otherKey, "")
+	s.Require().NoError(err)
+	otherKeyFile := filepath.Join(s.tmpDir, "id_other")
+	s.Require().NoError(os.WriteFile(otherKeyFile, pem.EncodeToMemory(block), 0o600))
+
+	emptyKnownHosts := filepath.Join(s.tmpDir, "empty_known_hosts")
+	s.Require().NoError(os.WriteFile(emptyKnownHosts, nil, 0o600))
+
+	tests := []struct {
+		name              string
+		vars              map[string]interface{}
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "private key",
+			vars: map[string]interface{}{},
+		},
+		{
+			name: "password",
+			vars: map[string]interface{}{
+				"ansible_ssh_private_key_file": otherKeyFile,
+				"ansible_password":             "secret",
+			},
+		},
+		{
+			name: "host key checking disabled",
+			vars: map[string]interface{}{
+				"ansible_ssh_common_args":   "-o UserKnownHostsFile=" + emptyKnownHosts,
+				"ansible_host_key_checking": false,
+			},
+		},
+		{
+			name: "StrictHostKeyChecking=no",
+			vars: map[string]interface{}{
+				"ansible_ssh_common_args": "-o UserKnownHostsFile=" + emptyKnownHosts,
+				"ansible_ssh_extra_args":  "-o StrictHostKeyChecking=no",
+			},
+		},
+		{
+			name: "unknown host key",
+			vars: map[string]interface{}{
+				"ansible_ssh_common_args": "-o UserKnownHostsFile=" + emptyKnownHosts,
+			},
+			expectErr:         true,
+			expectErrContains: "host key verification failed",
+		},
+		{
+			name: "wrong key",
+			vars: map[string]interface{}{
+				"ansible_ssh_private_key_file": otherKeyFile,
+			},
+			expectErr:         true,
+			expectErrContains: "unable to authenticate",
+		},
+		{
+			name: "missing key file",
+			vars: map[string]interface{}{
+				"ansible_ssh_private_key_file": filepath.Join(s.tmpDir, "missing"),
+			},
+			expectErr:         true,
+			expectErrContains: "failed to read private key",
+		},
+		{
+			name: "invalid port",
+			vars: map[string]interface{}{
+				"ansible_port": "ssh",
+			},
+			expectErr:         true,
+			expectErrContains: `invalid ansible_port "ssh"`,
+		},
+		{
+			name: "proxy command",
+			vars: map[string]interface{}{
+				"ansible_ssh_common_args": `-o ProxyCommand="ssh -W %h:%p bastion"`,
+			},
+			expectErr:         true,
+			expectErrContains: "unsupported ssh option ProxyCommand",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			vars := s.vars(s.server)
+			for k, v := range tc.vars {
+				vars[k] = v
+			}
+
+			conn, err := connection.Open("web1", vars)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			defer func() { _ = conn.Close() }()
+
+			result, err := conn.Exec(&connection.Command{Cmd: "echo ok"})
+			s.Require().NoError(err)
+			s.Equal("ok\n", string(result.Stdout))
+		})
+	}
+}
+
+func (s *SSHPublicTestSuite) TestProxyJump() {
+	bastion := s.newServer()
+	s.trust(bastion)
+
+	vars := s.vars(s.server)
+	vars["ansible_ssh_common_args"] = fmt.Sprintf(
+		"-J deploy@%s -o UserKnownHostsFile=%s",
+		bastion.addr,
+		s.knownHosts,
+	)
+
+	conn, err := connection.Open("web1", vars)
+	s.Require().NoError(err)
+	defer func() { _ = conn.Close() }()
+
+	result, err := conn.Exec(&connection.Command{Cmd: "echo through"})
+	s.Require().NoError(err)
+	s.Equal("through\n", string(result.Stdout))
+
+	s.Equal([]string{s.server.addr}, bastion.forwarded())
+	s.Equal(0, bastion.sessions())
+	s.Equal(1, s.server.sessions())
+}
+
+func (s *SSHPublicTestSuite) TestAgent() {
+	keyring := agent.NewKeyring()
+	s.Require().NoError(keyring.Add(agent.AddedKey{PrivateKey: s.clientKey}))
+
+	sock := filepath.Join(s.tmpDir, "agent.sock")
+	listener, err := net.Listen("unix", sock)
+	s.Require().NoError(err)
+	defer func() { _ = listener.Close() }()
+	go func() {
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = agent.ServeAgent(keyring, c)
+				_ = c.Close()
+			}()
+		}
+	}()
+	s.T().Setenv("SSH_AUTH_SOCK", sock)
+
+	vars := s.vars(s.server)
+	delete(vars, "ansible_ssh_private_key_file")
+
+	conn, err := connection.Open("web1", vars)
+	s.Require().NoError(err)
+	defer func() { _ = conn.Close() }()
+
+	result, err := conn.Exec(&connection.Command{Cmd: "echo agent"})
+	s.Require().NoError(err)
+	s.Equal("agent\n", string(result.Stdout))
+}
+
+func (s *SSHPublicTestSuite) TestPoolReusesConnection() {
+	pool := connection.NewPool()
+	defer func() { _ = pool.Close() }()
+
+	for range 3 {
+		conn := pool.Get("web1", vars(s.vars(s.server)))
+		_, err := conn.Exec(&connection.Command{Cmd: "true"})
+		s.Require().NoError(err)
+	}
+
+	s.Equal(1, s.server.logins())
+	s.Equal(3, s.server.sessions())
+}
+
+// sshServer is an in-process SSH server standing in for hosts and jump
+// hosts. It runs exec requests with /bin/sh and forwards direct-tcpip
+// channels.
+type sshServer struct {
+	addr    string
+	port    string
+	hostKey ssh.Signer
+
+	mu         sync.Mutex
+	loginCount int
+	execCount  int
+	dials      []string
+}
+
+// newServer starts a server accepting the client key, or the password
+// "secret", for any user.
+func (s *SSHPublicTestSuite) newServer() *sshServer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+	hostKey, err := ssh.NewSignerFromKey(key)
+	s.Require().NoError(err)
+
+	clientKey, err := ssh.NewPublicKey(s.clientKey.Public())
+	s.Require().NoError(err)
+
+	srv := &sshServer{hostKey: hostKey}
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+		PasswordCallback: func(_ ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, errors.New("wrong password")
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = listener.Close() })
+
+	srv.addr = listener.Addr().String()
+	_, srv.port, _ = net.SplitHostPort(srv.addr)
+
+	go func() {
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(c, config)
+		}
+	}()
+
+	return srv
+}
+
+func (srv *sshServer) serve(
+	c net.Conn,
+	config *ssh.ServerConfig,
+) {
+	conn, chans, reqs, err := ssh.NewServerConn(c, config)
+	if err != nil {
+		_ = c.Close()
+		return
+	}
+	defer func() { _ = conn.Close() }()
+	go ssh.DiscardRequests(reqs)
+
+	srv.mu.Lock()
+	srv.loginCount++
+	srv.mu.Unlock()
+
+	for newCh := range chans {
+		switch newCh.ChannelType() {
+		case "session":
+			go srv.session(newCh)
+		case "direct-tcpip":
+			go srv.forward(newCh)
+		default:
+			_ = newCh.Reject(ssh.UnknownChannelType, "unsupported channel type")
+		}
+	}
+}
+
+func (srv *sshServer) session(
+	newCh ssh.NewChannel,
+) {
+	ch, reqs, err := newCh.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = ch.Close() }()
+
+	for req := range reqs {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+
+		var payload struct{ Command string }
+		_ = ssh.Unmarshal(req.Payload, &payload)
+		_ = req.Reply(true, nil)
+
+		srv.mu.Lock()
+		srv.execCount++
+		srv.mu.Unlock()
+
+		cmd := exec.Command("/bin/sh", "-c", payload.Command)
+		cmd.Stdin = ch
+		cmd.Stdout = ch
+		cmd.Stderr = ch.Stderr()
+
+		status := 0
+		if err := cmd.Run(); err != nil {
+			status = 255
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				status = exitErr.ExitCode()
+			}
+		}
+
+		_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+		return
+	}
+}
+
+func (srv *sshServer) forward(
+	newCh ssh.NewChannel,
+) {
+	var payload struct {
+		Host     string
+		Port     uint32
+		OrigHost string
+		OrigPort uint32
+	}
+	if err := ssh.Unmarshal(newCh.ExtraData(), &payload); err != nil {
+		_ = newCh.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	addr := net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port)))
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		_ = newCh.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	ch, reqs, err := newCh.Accept()
+	if err != nil {
+		_ = target.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	srv.mu.Lock()
+	srv.dials = append(srv.dials, addr)
+	srv.mu.Unlock()
+
+	go func() {
+		_, _ = io.Copy(target, ch)
+		_ = target.Close()
+	}()
+	_, _ = io.Copy(ch, target)
+	_ = ch.Close()
+}
+
+func (srv *sshServer) logins() int {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.loginCount
+}
+
+func (srv *sshServer) sessions() int {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.execCount
+}
+
+func (srv *sshServer) forwarded() []string {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.dials
+}
+
+func TestSSHPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(SSHPublicTestSuite))
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package connection
+
+import (
+	"io"
+	"io/fs"
+)
+
+// Connection runs commands and transfers files on a single host.
+type Connection interface {
+	// Exec runs a command on the host and waits for it to finish. A command
+	// exiting non-zero is not an error; its exit code is in the result.
+	Exec(cmd *Command) (*ExecResult, error)
+	// Put writes the content of src to the file dst on the host with mode.
+	Put(src io.Reader, dst string, mode fs.FileMode) error
+	// Fetch writes the content of the file src on the host to dst.
+	Fetch(src string, dst io.Writer) error
+	// Close releases the resources held by the connection.
+	Close() error
+}
+
+// ttyEOF ends the input of a pseudo-terminal in canonical mode. The first
+// end-of-file character flushes a pending partial line, the second signals
+// end of file.
+const ttyEOF = "\x04\x04"
+
+// Command is a command run on a host.
+type Command struct {
+	// Cmd is the command line, run by /bin/sh -c.
+	Cmd string
+	// Stdin, when set, is fed to the command's standard input.
+	Stdin io.Reader
+	// Env holds environment variables set for the command.
+	Env map[string]string
+	// Dir is the working directory of the command. Empty means the user's
+	// home directory on remote hosts and the current directory locally.
+	Dir string
+	// Stdout, when set, also receives the standard output as it is produced.
+	Stdout io.Writer
+	// Stderr, when set, also receives the standard error as it is produced.
+	Stderr io.Writer
+	// TTY runs the command in a pseudo-terminal, which su needs to prompt
+	// for a password. Standard error is merged into standard output, and
+	// the end of Stdin is sent as end-of-file characters.
+	TTY bool
+}
+
+// ExecResult is the outcome of a command.
+type ExecResult struct {
+	// RC is the exit code of the command.
+	RC int
+	// Stdout holds the standard output of the command.
+	Stdout []byte
+	// Stderr holds the standard error of the command.
+	Stderr []byte
+}
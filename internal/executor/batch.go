@@ -0,0 +1,165 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+// batches splits hosts into the serial batches of a play. The last batch
+// size repeats until every host is in a batch, and a percentage is taken of
+// all hosts, rounded down but at least one.
+func batches(
+	hosts []string,
+	serial []ansible.BatchSize,
+) [][]string {
+	if len(serial) == 0 {
+		return [][]string{hosts}
+	}
+
+	total := len(hosts)
+	var out [][]string
+	for i := 0; len(hosts) > 0; i++ {
+		size := serial[min(i, len(serial)-1)]
+
+		n := size.Value
+		if size.Percent {
+			n = max(total*size.Value/100, 1)
+		}
+		if n <= 0 || n > len(hosts) {
+			n = len(hosts)
+		}
+
+		out = append(out, hosts[:n])
+		hosts = hosts[n:]
+	}
+
+	return out
+}
+
+// runBatches runs a play on its hosts batch by batch, and stops once the
+// play is aborted or every host of a batch failed.
+func (e *Executor) runBatches(
+	run *playRun,
+	strategy strategy,
+) {
+	plan := batches(run.hosts, run.play.Serial)
+
+	for i, batch := range plan {
+		if len(plan) > 1 {
+			_, _ = fmt.Fprintf(e.out, "  ▸ Batch %d/%d (hosts: %s)\n", i+1, len(plan), strings.Join(batch, ","))
+		}
+
+		run.batch = batch
+		run.failed = make(map[string]bool)
+
+		healthy := e.runBatch(run, strategy, batch)
+		if run.aborted != "" {
+			_, _ = fmt.Fprintf(e.out, "  aborting: %s\n", run.aborted)
+			return
+		}
+		if len(healthy) == 0 && i < len(plan)-1 {
+			_, _ = fmt.Fprintln(e.out, "  aborting: every host of the batch failed")
+			return
+		}
+	}
+}
+
+// runBatch runs the pre_tasks, tasks and post_tasks of the play on the hosts
+// of a batch and returns the hosts that are still healthy afterwards.
+func (e *Executor) runBatch(
+	run *playRun,
+	strategy strategy,
+	hosts []string,
+) []string {
+	for _, section := range [][]ansible.Task{run.play.PreTasks, run.play.Tasks, run.play.PostTasks} {
+		hosts = strategy.run(e, run, section, hosts)
+		e.checkFailures(run)
+		if len(hosts) == 0 || run.isAborted() {
+			return nil
+		}
+	}
+
+	return hosts
+}
+
+// runSection runs the top-level tasks of a play section on hosts and
+// returns the hosts that are still healthy afterwards. The failure limits
+// of the play are checked after every task, so a block's rescue runs before
+// its failures count, and no task starts once the play is aborted.
+func (e *Executor) runSection(
+	run *playRun,
+	tasks []ansible.Task,
+	hosts []string,
+) []string {
+	for _, task := range tasks {
+		if run.isAborted() {
+			return nil
+		}
+
+		hosts = e.runTasks(run, []ansible.Task{task}, hosts)
+		e.checkFailures(run)
+	}
+
+	if run.isAborted() {
+		return nil
+	}
+
+	return hosts
+}
+
+// checkFailures aborts the play when a host of the batch failed and the play
+// sets any_errors_fatal, or when more than max_fail_percentage of the hosts
+// of the batch failed.
+func (e *Executor) checkFailures(
+	run *playRun,
+) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	failed := len(run.failed)
+	if run.aborted != "" || failed == 0 {
+		return
+	}
+
+	switch limit := run.play.MaxFailPercentage; {
+	case run.play.AnyErrorsFatal:
+		run.aborted = "a task failed and any_errors_fatal is set"
+	case limit != nil && failed*100 > *limit*len(run.batch):
+		run.aborted = fmt.Sprintf(
+			"%d of %d hosts failed, exceeding max_fail_percentage of %d%%",
+			failed,
+			len(run.batch),
+			*limit,
+		)
+	}
+}
+
+// isAborted reports whether the play was aborted.
+func (run *playRun) isAborted() bool {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	return run.aborted != ""
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"fmt"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// become returns a func resolving the privilege escalation settings of a
+// task. Like Ansible, the ansible_become* variables override the become
+// keywords. It returns nil when become is disabled.
+func (e *Executor) become(
+	task ansible.Task,
+	taskVars map[string]interface{},
+) func() (*connection.Become, error) {
+	return func() (*connection.Become, error) {
+		connVars, err := e.connectionVars(taskVars)()
+		if err != nil {
+			return nil, err
+		}
+
+		enabled := task.Become.Enabled != nil && *task.Become.Enabled
+		if v, ok := connVars["ansible_become"]; ok {
+			if enabled, ok = ansible.ParseBool(v); !ok {
+				return nil, fmt.Errorf("invalid ansible_become %v", v)
+			}
+		}
+		if !enabled {
+			return nil, nil
+		}
+
+		keywords := map[string]string{
+			"ansible_become_method": task.Become.Method,
+			"ansible_become_user":   task.Become.User,
+			"ansible_become_flags":  task.Become.Flags,
+			"ansible_become_exe":    task.Become.Exe,
+		}
+		settings := make(map[string]string, len(keywords))
+		for k, keyword := range keywords {
+			v, err := ansible.RenderValue(keyword, taskVars, e.renderer)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render %s: %w", k, err)
+			}
+			settings[k] = fmt.Sprint(v)
+			if v, ok := connVars[k]; ok {
+				settings[k] = fmt.Sprint(v)
+			}
+		}
+
+		password := e.becomePassword
+		for _, k := range []string{"ansible_become_pass", "ansible_become_password"} {
+			if v, ok := connVars[k]; ok {
+				password = fmt.Sprint(v)
+			}
+		}
+
+		return &connection.Become{
+			Method:   settings["ansible_become_method"],
+			User:     settings["ansible_become_user"],
+			Flags:    settings["ansible_become_flags"],
+			Exe:      settings["ansible_become_exe"],
+			Password: password,
+		}, nil
+	}
+}
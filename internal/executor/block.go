@@ -0,0 +1,83 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"slices"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+// runBlock runs the tasks of a block on hosts, its rescue section on the
+// hosts where one of them failed and its always section on every host that
+// entered the block. It returns the hosts that are still healthy afterwards.
+func (e *Executor) runBlock(
+	run *playRun,
+	block ansible.Task,
+	hosts []string,
+) []string {
+	healthy := e.runTasks(run, block.Block, hosts)
+
+	if failed := without(hosts, healthy); len(failed) > 0 && len(block.Rescue) > 0 {
+		run.mu.Lock()
+		for _, host := range failed {
+			run.stats.rescue(host)
+			delete(run.failed, host)
+		}
+		run.mu.Unlock()
+		rescued := e.runTasks(run, block.Rescue, failed)
+		healthy = keep(hosts, append(healthy, rescued...))
+	}
+
+	afterAlways := e.runTasks(run, block.Always, hosts)
+
+	return keep(healthy, afterAlways)
+}
+
+// keep returns the hosts that are also in subset, in the order of hosts.
+func keep(
+	hosts []string,
+	subset []string,
+) []string {
+	kept := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if slices.Contains(subset, host) {
+			kept = append(kept, host)
+		}
+	}
+
+	return kept
+}
+
+// without returns the hosts that are not in excluded, in order.
+func without(
+	hosts []string,
+	excluded []string,
+) []string {
+	var remaining []string
+	for _, host := range hosts {
+		if !slices.Contains(excluded, host) {
+			remaining = append(remaining, host)
+		}
+	}
+
+	return remaining
+}
@@ -0,0 +1,506 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"sync"
+
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+	"github.com/retr0h/voidspan/internal/connection"
+	"github.com/retr0h/voidspan/internal/inventory"
+	"github.com/retr0h/voidspan/internal/module"
+)
+
+// defaultForks is the number of hosts a task runs on at once unless
+// WithForks sets it.
+const defaultForks = 5
+
+// Executor walks parsed plays and runs each task through the Go
+// implementation of its module.
+type Executor struct {
+	renderer  *jinja2.Jinja2
+	modules   *module.Registry
+	out       io.Writer
+	inventory *inventory.Inventory
+	extraVars map[string]interface{}
+	// becomePassword answers become password prompts when no
+	// ansible_become_password is set.
+	becomePassword string
+	// forks is the number of hosts a task runs on at once.
+	forks int
+	// strategy is the name of the strategy of plays that set none.
+	strategy string
+	// verbosity is passed to modules, such as debug with verbosity.
+	verbosity int
+
+	// mu guards facts, which tasks on different hosts update concurrently.
+	mu sync.Mutex
+	// facts holds the set_fact and registered variables of each host.
+	facts map[string]map[string]interface{}
+}
+
+// Option configures an Executor.
+type Option func(*Executor)
+
+// WithExtraVars sets variables that take precedence over all others.
+func WithExtraVars(extraVars map[string]interface{}) Option {
+	return func(e *Executor) {
+		e.extraVars = extraVars
+	}
+}
+
+// WithInventory sets the inventory plays are run against. Without it plays
+// run against the implicit localhost.
+func WithInventory(inv *inventory.Inventory) Option {
+	return func(e *Executor) {
+		e.inventory = inv
+	}
+}
+
+// WithBecomePassword sets the password used to become another user, as
+// given with --ask-become-pass.
+func WithBecomePassword(password string) Option {
+	return func(e *Executor) {
+		e.becomePassword = password
+	}
+}
+
+// WithForks sets the number of hosts a task runs on at once. It defaults to
+// defaultForks; values below 1 run one host at a time.
+func WithForks(forks int) Option {
+	return func(e *Executor) {
+		e.forks = forks
+	}
+}
+
+// WithStrategy sets the strategy of plays that do not set one. It defaults
+// to linear.
+func WithStrategy(name string) Option {
+	return func(e *Executor) {
+		e.strategy = name
+	}
+}
+
+// WithVerbosity sets the verbosity modules see, as set by -v, -vv, ...
+func WithVerbosity(level int) Option {
+	return func(e *Executor) {
+		e.verbosity = level
+	}
+}
+
+// New creates an Executor that renders task args with renderer, resolves
+// modules from modules and writes progress to out.
+func New(
+	renderer *jinja2.Jinja2,
+	modules *module.Registry,
+	out io.Writer,
+	opts ...Option,
+) *Executor {
+	e := &Executor{
+		renderer:  renderer,
+		modules:   modules,
+		out:       out,
+		inventory: inventory.Implicit(),
+		forks:     defaultForks,
+		strategy:  defaultStrategy,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.forks = max(e.forks, 1)
+
+	return e
+}
+
+// playRun holds the state of a play while it runs.
+type playRun struct {
+	play ansible.Play
+	// hosts lists every host the play targets.
+	hosts []string
+	// conns holds the connections to the play's hosts.
+	conns *connection.Pool
+	// forks holds a token for every task running on a host.
+	forks chan struct{}
+	// pinned is set when hosts hold a fork for a whole section, so tasks
+	// take none.
+	pinned bool
+
+	// batch lists the hosts of the serial batch that is running.
+	batch []string
+
+	// mu guards the output and the fields below, which hosts running
+	// through tasks on their own share.
+	mu    sync.Mutex
+	stats Stats
+	// notified holds, per host, the indexes of the notified handlers.
+	notified map[string]map[int]bool
+	// failed holds the hosts of the batch with an unrescued failure.
+	failed map[string]bool
+	// aborted, when set, is why the play was aborted.
+	aborted string
+}
+
+// Run executes the plays in order and returns the per-host task counters.
+func (e *Executor) Run(
+	plays []ansible.Play,
+) Stats {
+	e.facts = make(map[string]map[string]interface{})
+
+	stats := Stats{}
+	for _, play := range plays {
+		e.runPlay(play, stats)
+	}
+
+	e.printRecap(stats)
+
+	return stats
+}
+
+// runPlay runs the pre_tasks, tasks and post_tasks of a play on the hosts
+// it targets, one serial batch after another, flushing notified handlers
+// after each section. The strategy of the play decides how tasks advance
+// across hosts, at most e.forks at once, and a host stops at its first
+// failure.
+func (e *Executor) runPlay(
+	play ansible.Play,
+	stats Stats,
+) {
+	_, _ = fmt.Fprintf(e.out, "▶ Play: %s (hosts: %s)\n", play.Name, play.Hosts)
+
+	hosts, err := e.inventory.ListHosts(play.Hosts)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.out, "  skipping: %v\n", err)
+		return
+	}
+	if len(hosts) == 0 {
+		_, _ = fmt.Fprintln(e.out, "  skipping: no hosts matched")
+		return
+	}
+
+	name := play.Strategy
+	if name == "" {
+		name = e.strategy
+	}
+	strategy, ok := strategies[name]
+	if !ok {
+		_, _ = fmt.Fprintf(e.out, "  skipping: unknown strategy %q\n", name)
+		return
+	}
+
+	run := &playRun{
+		play:     play,
+		hosts:    hosts,
+		stats:    stats,
+		conns:    connection.NewPool(),
+		forks:    make(chan struct{}, e.forks),
+		notified: make(map[string]map[int]bool),
+	}
+	defer func() { _ = run.conns.Close() }()
+	for _, host := range hosts {
+		run.notified[host] = make(map[int]bool)
+	}
+
+	e.runBatches(run, strategy)
+}
+
+// runTasks runs tasks in order on hosts and returns the hosts that are still
+// healthy afterwards.
+func (e *Executor) runTasks(
+	run *playRun,
+	tasks []ansible.Task,
+	hosts []string,
+) []string {
+	for _, task := range tasks {
+		if len(hosts) == 0 {
+			break
+		}
+
+		if task.IsBlock() {
+			hosts = e.runBlock(run, task, hosts)
+			continue
+		}
+
+		if isMeta(task.Module) {
+			hosts = e.runMeta(run, task, hosts)
+			continue
+		}
+
+		hosts = e.runOnHosts(run, "Task", task, hosts)
+	}
+
+	return hosts
+}
+
+// runOnHosts runs a task or handler on hosts, taking one of the play's
+// forks per host. The results are recorded and printed under kind, in the
+// order of hosts, once every host is done, so the output does not depend on
+// which host finishes first. It returns the hosts where the task succeeded.
+func (e *Executor) runOnHosts(
+	run *playRun,
+	kind string,
+	task ansible.Task,
+	hosts []string,
+) []string {
+	results := make([]*Result, len(hosts))
+
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		if !run.pinned {
+			run.forks <- struct{}{}
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				if !run.pinned {
+					<-run.forks
+				}
+				wg.Done()
+			}()
+			results[i] = e.runTask(run, host, task)
+		}()
+	}
+	wg.Wait()
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	_, _ = fmt.Fprintf(e.out, "  ▸ %s: %s\n", kind, task.Name)
+	healthy := make([]string, 0, len(hosts))
+	for _, result := range results {
+		if e.record(run, task, result) {
+			healthy = append(healthy, result.Host)
+		}
+	}
+
+	return healthy
+}
+
+// record records and prints the result of a task or handler on a host and
+// notifies handlers on change. It reports whether the task succeeded. The
+// caller holds run.mu.
+func (e *Executor) record(
+	run *playRun,
+	task ansible.Task,
+	result *Result,
+) bool {
+	host := result.Host
+	run.stats.record(result)
+	e.printResult(result)
+
+	switch result.Status {
+	case StatusFailed:
+		run.failed[host] = true
+		return false
+	case StatusChanged:
+		notify(run.play.Handlers, task.Notify, run.notified[host])
+	}
+
+	return true
+}
+
+// runTask runs a task on a host, once per item when the task loops.
+func (e *Executor) runTask(
+	run *playRun,
+	host string,
+	task ansible.Task,
+) *Result {
+	taskVars := e.taskVars(run, host, task)
+
+	var result *Result
+	if task.Loop != nil {
+		result = e.runLoop(run, host, task, taskVars)
+	} else {
+		result = e.runOnce(run, host, task, taskVars)
+	}
+
+	if task.Register != "" {
+		e.setFacts(host, map[string]interface{}{task.Register: resultData(result)})
+	}
+
+	return result
+}
+
+// runOnce evaluates the task conditionals against taskVars, renders the task
+// args, resolves the module and runs it.
+func (e *Executor) runOnce(
+	run *playRun,
+	host string,
+	task ansible.Task,
+	taskVars map[string]interface{},
+) *Result {
+	result := &Result{
+		Host: host,
+		Task: task.Name,
+	}
+
+	for _, cond := range task.When {
+		ok, err := ansible.EvaluateConditional(cond, taskVars, e.renderer)
+		if err != nil {
+			result.Status = StatusFailed
+			result.Msg = err.Error()
+			return result
+		}
+
+		if !ok {
+			result.Status = StatusSkipped
+			result.Msg = fmt.Sprintf("conditional result was false: %s", cond)
+			return result
+		}
+	}
+
+	m, ok := e.modules.Lookup(task.Module)
+	if !ok {
+		result.Status = StatusFailed
+		result.Msg = fmt.Sprintf("couldn't resolve module %q", task.Module)
+		return result
+	}
+
+	args, err := ansible.RenderJinjaFields(task.RawArgs, taskVars, e.renderer)
+	if err != nil {
+		result.Status = StatusFailed
+		result.Msg = err.Error()
+		return result
+	}
+
+	mr, err := m.Run(&module.Context{
+		Host: host,
+		Task: task.Name,
+		Vars: ansible.StripUnsafe(taskVars),
+		Conn: connection.WithBecome(
+			run.conns.Get(host, e.connectionVars(taskVars)),
+			e.become(task, taskVars),
+		),
+		SearchPaths: searchPaths(task),
+		Verbosity:   e.verbosity,
+		Evaluate: func(expr string) (interface{}, error) {
+			return ansible.EvaluateExpression("{{ "+expr+" }}", taskVars, e.renderer)
+		},
+		Conditional: func(cond string) (bool, error) {
+			return ansible.EvaluateConditional(cond, taskVars, e.renderer)
+		},
+		Template: func(path string, extra map[string]interface{}) (string, error) {
+			vars := maps.Clone(taskVars)
+			maps.Copy(vars, extra)
+			return ansible.RenderTemplate(path, vars, e.renderer)
+		},
+	}, args)
+	if err != nil {
+		result.Status = StatusFailed
+		result.Msg = err.Error()
+		return result
+	}
+
+	result.Msg = mr.Msg
+	result.Data = mr.Data
+
+	if !mr.Failed && len(mr.Facts) > 0 {
+		e.setFacts(host, mr.Facts)
+	}
+
+	switch {
+	case mr.Failed:
+		result.Status = StatusFailed
+	case mr.Skipped:
+		result.Status = StatusSkipped
+	case mr.Changed:
+		result.Status = StatusChanged
+	default:
+		result.Status = StatusOK
+	}
+
+	return result
+}
+
+// searchPaths returns the local directories relative files of a task are
+// looked up in: the directory of its role, then the directory of the file
+// the task was defined in.
+func searchPaths(
+	task ansible.Task,
+) []string {
+	var paths []string
+	if task.Role != nil && task.Role.Path != "" {
+		paths = append(paths, task.Role.Path)
+	}
+	if task.Source != "" {
+		paths = append(paths, filepath.Dir(task.Source))
+	}
+
+	return paths
+}
+
+// printResult writes a task result, with one line per loop item.
+func (e *Executor) printResult(
+	result *Result,
+) {
+	for _, item := range result.Items {
+		if item.Msg == "" {
+			_, _ = fmt.Fprintf(e.out, "    %s: [%s] => (item=%s)\n", item.Status, item.Host, item.Label)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(
+			e.out,
+			"    %s: [%s] => (item=%s) => %s\n",
+			item.Status,
+			item.Host,
+			item.Label,
+			item.Msg,
+		)
+	}
+
+	if len(result.Items) > 0 && result.Status != StatusFailed {
+		return
+	}
+
+	if result.Msg == "" {
+		_, _ = fmt.Fprintf(e.out, "    %s: [%s]\n", result.Status, result.Host)
+		return
+	}
+
+	_, _ = fmt.Fprintf(e.out, "    %s: [%s] => %s\n", result.Status, result.Host, result.Msg)
+}
+
+// printRecap writes the per-host counters.
+func (e *Executor) printRecap(
+	stats Stats,
+) {
+	_, _ = fmt.Fprintln(e.out, "▶ Recap")
+
+	for _, host := range stats.Hosts() {
+		hs := stats[host]
+		_, _ = fmt.Fprintf(
+			e.out,
+			"  %s : ok=%d changed=%d failed=%d skipped=%d rescued=%d\n",
+			host,
+			hs.OK,
+			hs.Changed,
+			hs.Failed,
+			hs.Skipped,
+			hs.Rescued,
+		)
+	}
+}
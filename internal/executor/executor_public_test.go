@@ -0,0 +1,1924 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+	"github.com/retr0h/voidspan/internal/connection"
+	"github.com/retr0h/voidspan/internal/executor"
+	"github.com/retr0h/voidspan/internal/inventory"
+	"github.com/retr0h/voidspan/internal/module"
+)
+
+type ExecutorPublicTestSuite struct {
+	suite.Suite
+
+	renderer *jinja2.Jinja2
+	modules  *module.Registry
+}
+
+func (s *ExecutorPublicTestSuite) SetupSuite() {
+	r, err := jinja2.NewJinja2("test-executor", 1, jinja2.WithStrict(false))
+	s.Require().NoError(err)
+	s.renderer = r
+
+	s.modules = module.NewDefaultRegistry()
+	err = s.modules.Register(
+		"voidspan.test.change",
+		module.Func(func(ctx *module.Context, _ map[string]interface{}) (*module.Result, error) {
+			return &module.Result{Changed: true, Msg: ctx.Task}, nil
+		}),
+	)
+	s.Require().NoError(err)
+
+	err = s.modules.Register(
+		"voidspan.test.fact",
+		module.Func(func(_ *module.Context, args map[string]interface{}) (*module.Result, error) {
+			return &module.Result{Facts: args}, nil
+		}),
+	)
+
+	err = s.modules.Register(
+		"voidspan.test.exec",
+		module.Func(func(ctx *module.Context, args map[string]interface{}) (*module.Result, error) {
+			cmd, _ := args["cmd"].(string)
+			result, err := ctx.Conn.Exec(&connection.Command{Cmd: cmd})
+			if err != nil {
+				return nil, err
+			}
+			return &module.Result{Msg: strings.TrimSpace(string(result.Stdout))}, nil
+		}),
+	)
+	s.Require().NoError(err)
+}
+
+func (s *ExecutorPublicTestSuite) TearDownSuite() {
+	s.renderer.Close()
+}
+
+func (s *ExecutorPublicTestSuite) TestRun() {
+	roleDir := s.T().TempDir()
+	s.Require().NoError(os.MkdirAll(filepath.Join(roleDir, "files"), 0o755))
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(roleDir, "files", "hello.sh"),
+		[]byte("#!/bin/sh\necho \"hello $1\"\n"),
+		0o644,
+	))
+	s.Require().NoError(os.MkdirAll(filepath.Join(roleDir, "templates"), 0o755))
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(roleDir, "templates", "app.conf.j2"),
+		[]byte("# {{ ansible_managed }}\n{% for p in ports %}\nlisten {{ p }}\n{% endfor %}\n"),
+		0o644,
+	))
+	destDir := s.T().TempDir()
+
+	role := &ansible.Role{
+		Name: "myrole",
+		Path: roleDir,
+		Defaults: map[string]interface{}{
+			"defaults": "defaults",
+			"play":     "defaults",
+		},
+		Vars: map[string]interface{}{
+			"role": "role",
+			"task": "role",
+		},
+	}
+
+	inv := inventory.New()
+	inv.AddGroup("web").Vars["port"] = 80
+	inv.AddHost("web1", "web").Vars["port"] = 8080
+	inv.AddHost("web2", "web")
+	inv.AddHost("db1", "db")
+	inv.AddChild(inventory.AllGroup, "web")
+	inv.AddChild(inventory.AllGroup, "db")
+
+	tests := []struct {
+		name           string
+		opts           []executor.Option
+		plays          []ansible.Play
+		expected       executor.Stats
+		expectFailed   bool
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			name: "debug renders vars",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{{
+					Name:   "say hello",
+					Module: "ansible.builtin.debug",
+					RawArgs: map[string]interface{}{
+						"msg": "hello {{ who }}",
+					},
+					Vars: map[string]interface{}{
+						"who": "world",
+					},
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 1},
+			},
+			expectContains: []string{
+				"▶ Play: test play (hosts: all)",
+				"  ▸ Task: say hello",
+				"    ok: [localhost] => hello world",
+				"  localhost : ok=1 changed=0 failed=0 skipped=0 rescued=0",
+			},
+		},
+		{
+			name: "variable precedence",
+			opts: []executor.Option{
+				executor.WithExtraVars(map[string]interface{}{"extra": "extra"}),
+			},
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Vars: map[string]interface{}{
+					"play": "play",
+					"role": "play",
+				},
+				Tasks: []ansible.Task{
+					{
+						Name:   "set facts",
+						Module: "voidspan.test.fact",
+						RawArgs: map[string]interface{}{
+							"fact":  "fact",
+							"extra": "fact",
+						},
+					},
+					{
+						Name:   "show",
+						Module: "debug",
+						RawArgs: map[string]interface{}{
+							"msg": "{{ defaults }} {{ play }} {{ role }} {{ task }} {{ fact }} {{ extra }}",
+						},
+						Vars: map[string]interface{}{
+							"task": "task",
+							"fact": "task",
+						},
+						Role: role,
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 2},
+			},
+			expectContains: []string{
+				"    ok: [localhost] => defaults play role task fact extra",
+			},
+		},
+		{
+			name: "short module name",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{{
+					Name:    "default msg",
+					Module:  "debug",
+					RawArgs: map[string]interface{}{},
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 1},
+			},
+			expectContains: []string{
+				"    ok: [localhost] => Hello world!",
+			},
+		},
+		{
+			name: "false when skips the task",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:    "gated",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						Vars: map[string]interface{}{
+							"enabled": true,
+							"count":   1,
+						},
+						When: []string{"enabled", "count > 1"},
+					},
+					{
+						Name:    "ungated",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+						When:    []string{"missing is not defined"},
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 1, Skipped: 1},
+			},
+			expectContains: []string{
+				"    skipped: [localhost] => conditional result was false: count > 1",
+				"    ok: [localhost] => Hello world!",
+			},
+		},
+		{
+			name: "invalid when fails the task",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{{
+					Name:    "broken conditional",
+					Module:  "debug",
+					RawArgs: map[string]interface{}{},
+					When:    []string{"foo =="},
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				`    failed: [localhost] => failed to evaluate conditional "foo =="`,
+			},
+		},
+		{
+			name: "loop over a template runs once per item",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Vars: map[string]interface{}{
+					"users": []interface{}{"alice", "bob"},
+				},
+				Tasks: []ansible.Task{{
+					Name:    "greet",
+					Module:  "debug",
+					RawArgs: map[string]interface{}{"msg": "hello {{ item }}"},
+					Loop:    "{{ users }}",
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 1},
+			},
+			expectContains: []string{
+				"  ▸ Task: greet\n" +
+					"    ok: [localhost] => (item=alice) => hello alice\n" +
+					"    ok: [localhost] => (item=bob) => hello bob\n",
+			},
+		},
+		{
+			name: "loop_control and literal list",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{{
+					Name:   "users",
+					Module: "debug",
+					RawArgs: map[string]interface{}{
+						"msg": "{{ idx }} {{ user.name }} {{ ansible_loop.index }}/{{ ansible_loop.length }}" +
+							" last={{ ansible_loop.last }}",
+					},
+					Loop: []interface{}{
+						map[string]interface{}{"name": "alice"},
+						map[string]interface{}{"name": "{{ 'b' ~ 'ob' }}"},
+					},
+					LoopControl: ansible.LoopControl{
+						LoopVar:  "user",
+						IndexVar: "idx",
+						Label:    "{{ user.name }}",
+						Pause:    time.Millisecond,
+						Extended: true,
+					},
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 1},
+			},
+			expectContains: []string{
+				"    ok: [localhost] => (item=alice) => 0 alice 1/2 last=False\n",
+				"    ok: [localhost] => (item=bob) => 1 bob 2/2 last=True\n",
+			},
+		},
+		{
+			name: "when is evaluated per item",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:   "some",
+						Module: "voidspan.test.change",
+						Loop:   []interface{}{1, 2, 3},
+						When:   []string{"item > 1"},
+					},
+					{
+						Name:   "none",
+						Module: "voidspan.test.change",
+						Loop:   []interface{}{1, 2},
+						When:   []string{"item > 5"},
+					},
+					{
+						Name:   "empty",
+						Module: "debug",
+						Loop:   "{{ [] }}",
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 1, Changed: 1, Skipped: 2},
+			},
+			expectContains: []string{
+				"    skipped: [localhost] => (item=1) => conditional result was false: item > 1\n" +
+					"    changed: [localhost] => (item=2) => some\n",
+				"    skipped: [localhost] => no items in the list\n",
+			},
+		},
+		{
+			name: "a failed item fails the task after every item ran",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:    "partly",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{"msg": "{{ 1 / item }}"},
+						Loop:    []interface{}{1, 0, 2},
+					},
+					{
+						Name:   "after",
+						Module: "debug",
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				"    ok: [localhost] => (item=1) => 1.0\n    failed: [localhost] => (item=0)",
+				"    ok: [localhost] => (item=2) => 0.5\n" +
+					"    failed: [localhost] => one or more items failed\n",
+			},
+			expectMissing: []string{
+				"Task: after",
+			},
+		},
+		{
+			name: "with_items and with_dict",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Vars: map[string]interface{}{
+					"ports": map[string]interface{}{"https": 443, "http": 80},
+				},
+				Tasks: []ansible.Task{
+					{
+						Name:     "items",
+						Module:   "debug",
+						RawArgs:  map[string]interface{}{"msg": "{{ item }}"},
+						Loop:     []interface{}{"a", []interface{}{"b", "c"}},
+						LoopWith: "items",
+					},
+					{
+						Name:     "dict",
+						Module:   "debug",
+						RawArgs:  map[string]interface{}{"msg": "{{ item.key }}={{ item.value }}"},
+						Loop:     "{{ ports }}",
+						LoopWith: "dict",
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 2},
+			},
+			expectContains: []string{
+				"(item=a) => a\n",
+				"(item=b) => b\n",
+				"(item=c) => c\n",
+				`(item={"key":"http","value":80}) => http=80` + "\n" +
+					`    ok: [localhost] => (item={"key":"https","value":443}) => https=443` + "\n",
+			},
+		},
+		{
+			name: "invalid loop data",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:   "not a list",
+						Module: "debug",
+						Loop:   "{{ 'abc' }}",
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				"invalid data passed to 'loop', it requires a list, got this instead: abc",
+			},
+		},
+		{
+			name: "unsupported lookup",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:     "bogus",
+						Module:   "debug",
+						Loop:     "x",
+						LoopWith: "bogus",
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				`unsupported loop lookup "with_bogus"`,
+			},
+		},
+		{
+			name: "tasks run on every inventory host in order",
+			opts: []executor.Option{
+				executor.WithInventory(inv),
+			},
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "web",
+				Tasks: []ansible.Task{
+					{
+						Name:    "show",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{"msg": "{{ inventory_hostname }} {{ port }} {{ group_names | join(',') }}"},
+					},
+					{
+						Name:   "fail web2",
+						Module: "not.a.module",
+						When:   []string{"inventory_hostname == 'web2'"},
+					},
+					{
+						Name:   "after",
+						Module: "voidspan.test.change",
+						Notify: []string{"restart"},
+					},
+				},
+				Handlers: []ansible.Task{{
+					Name:    "restart",
+					Module:  "debug",
+					RawArgs: map[string]interface{}{"msg": "restarting {{ inventory_hostname }}"},
+				}},
+			}},
+			expected: executor.Stats{
+				"web1": {OK: 3, Changed: 1, Skipped: 1},
+				"web2": {OK: 1, Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				"  ▸ Task: show\n" +
+					"    ok: [web1] => web1 8080 web\n" +
+					"    ok: [web2] => web2 80 web\n",
+				"  ▸ Task: after\n" +
+					"    changed: [web1] => after\n" +
+					"  ▸ Handler: restart\n" +
+					"    ok: [web1] => restarting web1\n",
+				"  web1 : ok=3 changed=1 failed=0 skipped=1 rescued=0",
+				"  web2 : ok=1 changed=0 failed=1 skipped=0 rescued=0",
+			},
+			expectMissing: []string{
+				"changed: [web2]",
+			},
+		},
+		{
+			name: "rescue runs only on the failed hosts",
+			opts: []executor.Option{
+				executor.WithInventory(inv),
+			},
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{{
+					Block: []ansible.Task{{
+						Name:   "fail db1",
+						Module: "not.a.module",
+						When:   []string{"inventory_hostname == 'db1'"},
+					}},
+					Rescue: []ansible.Task{{
+						Name:   "rescue",
+						Module: "debug",
+					}},
+					Always: []ansible.Task{{
+						Name:   "always",
+						Module: "debug",
+					}},
+				}},
+			}},
+			expected: executor.Stats{
+				"web1": {OK: 1, Skipped: 1},
+				"web2": {OK: 1, Skipped: 1},
+				"db1":  {OK: 2, Rescued: 1},
+			},
+			expectContains: []string{
+				"  ▸ Task: rescue\n    ok: [db1] => Hello world!\n" +
+					"  ▸ Task: always\n" +
+					"    ok: [web1] => Hello world!\n" +
+					"    ok: [web2] => Hello world!\n" +
+					"    ok: [db1] => Hello world!\n",
+			},
+		},
+		{
+			name: "no hosts matched",
+			opts: []executor.Option{
+				executor.WithInventory(inv),
+			},
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "missing",
+				Tasks: []ansible.Task{{
+					Name:   "never",
+					Module: "debug",
+				}},
+			}},
+			expected: executor.Stats{},
+			expectContains: []string{
+				"  skipping: no hosts matched\n",
+			},
+			expectMissing: []string{
+				"Task: never",
+			},
+		},
+		{
+			name: "notified handlers run once at the end of the play",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:   "change config",
+						Module: "voidspan.test.change",
+						Notify: []string{"restart app"},
+					},
+					{
+						Name:   "change config again",
+						Module: "voidspan.test.change",
+						Notify: []string{"restart app", "web services"},
+					},
+					{
+						Name:   "unchanged",
+						Module: "debug",
+						Notify: []string{"never"},
+					},
+				},
+				Handlers: []ansible.Task{
+					{
+						Name:   "restart app",
+						Module: "voidspan.test.change",
+					},
+					{
+						Name:   "never",
+						Module: "debug",
+					},
+					{
+						Name:   "restart nginx",
+						Module: "voidspan.test.change",
+						Listen: []string{"web services"},
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 5, Changed: 4},
+			},
+			expectContains: []string{
+				"  ▸ Handler: restart app\n    changed: [localhost] => restart app\n" +
+					"  ▸ Handler: restart nginx\n",
+			},
+			expectMissing: []string{
+				"Handler: never",
+			},
+		},
+		{
+			name: "flush_handlers runs notified handlers mid-play",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:   "change config",
+						Module: "voidspan.test.change",
+						Notify: []string{"restart app"},
+					},
+					{
+						Name:    "flush",
+						Module:  "ansible.builtin.meta",
+						RawArgs: map[string]interface{}{"__value__": "flush_handlers"},
+					},
+					{
+						Name:    "after flush",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{"msg": "after"},
+					},
+				},
+				Handlers: []ansible.Task{{
+					Name:   "restart app",
+					Module: "debug",
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 3, Changed: 1},
+			},
+			expectContains: []string{
+				"  ▸ Handler: restart app\n    ok: [localhost] => Hello world!\n" +
+					"  ▸ Task: after flush\n",
+			},
+		},
+		{
+			name: "handlers flush after pre_tasks, tasks and post_tasks",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				PreTasks: []ansible.Task{{
+					Name:   "pre",
+					Module: "voidspan.test.change",
+					Notify: []string{"restart app"},
+				}},
+				Tasks: []ansible.Task{{
+					Name:   "main",
+					Module: "voidspan.test.change",
+					Notify: []string{"restart app"},
+				}},
+				PostTasks: []ansible.Task{{
+					Name:   "post",
+					Module: "voidspan.test.change",
+					Notify: []string{"restart app"},
+				}},
+				Handlers: []ansible.Task{{
+					Name:   "restart app",
+					Module: "debug",
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 6, Changed: 3},
+			},
+			expectContains: []string{
+				"  ▸ Task: pre\n    changed: [localhost] => pre\n" +
+					"  ▸ Handler: restart app\n    ok: [localhost] => Hello world!\n" +
+					"  ▸ Task: main\n    changed: [localhost] => main\n" +
+					"  ▸ Handler: restart app\n    ok: [localhost] => Hello world!\n" +
+					"  ▸ Task: post\n    changed: [localhost] => post\n" +
+					"  ▸ Handler: restart app\n    ok: [localhost] => Hello world!\n",
+			},
+		},
+		{
+			name: "failed pre_tasks stop the play",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				PreTasks: []ansible.Task{{
+					Name:   "bogus",
+					Module: "not.a.module",
+				}},
+				Tasks: []ansible.Task{{
+					Name:   "main",
+					Module: "debug",
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {Failed: 1},
+			},
+			expectFailed: true,
+			expectMissing: []string{
+				"Task: main",
+			},
+		},
+		{
+			name: "failed host does not run handlers",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:   "change config",
+						Module: "voidspan.test.change",
+						Notify: []string{"restart app"},
+					},
+					{
+						Name:   "bogus",
+						Module: "not.a.module",
+					},
+				},
+				Handlers: []ansible.Task{{
+					Name:   "restart app",
+					Module: "debug",
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 1, Changed: 1, Failed: 1},
+			},
+			expectFailed: true,
+			expectMissing: []string{
+				"Handler: restart app",
+			},
+		},
+		{
+			name: "unsupported meta action fails",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{{
+					Name:    "end",
+					Module:  "meta",
+					RawArgs: map[string]interface{}{"__value__": "end_everything"},
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				`    failed: [localhost] => unsupported meta action "end_everything"`,
+			},
+		},
+		{
+			name: "block failure runs rescue and always",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name: "guarded",
+						Block: []ansible.Task{
+							{Name: "breaks", Module: "not.a.module"},
+							{Name: "skipped by failure", Module: "debug"},
+						},
+						Rescue: []ansible.Task{
+							{Name: "recover", Module: "voidspan.test.change"},
+						},
+						Always: []ansible.Task{
+							{Name: "cleanup", Module: "debug"},
+						},
+					},
+					{Name: "after block", Module: "debug"},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 3, Changed: 1, Rescued: 1},
+			},
+			expectContains: []string{
+				"  ▸ Task: breaks\n    failed:",
+				"  ▸ Task: recover\n    changed:",
+				"  ▸ Task: cleanup\n    ok:",
+				"  ▸ Task: after block\n    ok:",
+			},
+			expectMissing: []string{
+				"skipped by failure",
+			},
+		},
+		{
+			name: "successful block skips rescue",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{{
+					Name:   "guarded",
+					Block:  []ansible.Task{{Name: "works", Module: "debug"}},
+					Rescue: []ansible.Task{{Name: "recover", Module: "debug"}},
+					Always: []ansible.Task{{Name: "cleanup", Module: "debug"}},
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 2},
+			},
+			expectMissing: []string{
+				"recover",
+			},
+		},
+		{
+			name: "failure without rescue still runs always",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:   "guarded",
+						Block:  []ansible.Task{{Name: "breaks", Module: "not.a.module"}},
+						Always: []ansible.Task{{Name: "cleanup", Module: "debug"}},
+					},
+					{Name: "after block", Module: "debug"},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 1, Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				"  ▸ Task: cleanup\n    ok:",
+			},
+			expectMissing: []string{
+				"after block",
+			},
+		},
+		{
+			name: "failing rescue fails the host",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{{
+					Name:   "guarded",
+					Block:  []ansible.Task{{Name: "breaks", Module: "not.a.module"}},
+					Rescue: []ansible.Task{{Name: "breaks again", Module: "not.a.module"}},
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {Failed: 1, Rescued: 1},
+			},
+			expectFailed: true,
+		},
+		{
+			name: "unknown module fails and stops the play",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:    "bogus",
+						Module:  "not.a.module",
+						RawArgs: map[string]interface{}{},
+					},
+					{
+						Name:    "never runs",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				`    failed: [localhost] => couldn't resolve module "not.a.module"`,
+			},
+			expectMissing: []string{
+				"never runs",
+			},
+		},
+		{
+			name: "render error fails the task",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{{
+					Name:   "broken template",
+					Module: "debug",
+					RawArgs: map[string]interface{}{
+						"msg": "{{ invalid",
+					},
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				"unexpected end of template",
+			},
+		},
+		{
+			name: "registered results and facts feed later tasks",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:     "change",
+						Module:   "voidspan.test.change",
+						RawArgs:  map[string]interface{}{},
+						Register: "changed_result",
+					},
+					{
+						Name:   "set facts",
+						Module: "set_fact",
+						RawArgs: map[string]interface{}{
+							"ports":   "{{ [80, 443] }}",
+							"enabled": "{{ changed_result.changed }}",
+						},
+					},
+					{
+						Name:   "check",
+						Module: "assert",
+						RawArgs: map[string]interface{}{
+							"that": []interface{}{
+								"changed_result.msg == 'change'",
+								"ports | length == 2",
+								"enabled",
+							},
+							"success_msg": "facts are typed",
+						},
+					},
+					{
+						Name:   "show ports",
+						Module: "debug",
+						RawArgs: map[string]interface{}{
+							"var": "ports",
+						},
+					},
+					{
+						Name:   "only verbose",
+						Module: "debug",
+						RawArgs: map[string]interface{}{
+							"msg":       "hidden",
+							"verbosity": 1,
+						},
+						Register: "verbose_result",
+					},
+					{
+						Name:   "skipped is registered",
+						Module: "debug",
+						RawArgs: map[string]interface{}{
+							"msg": "was skipped",
+						},
+						When: []string{"verbose_result.skipped"},
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 5, Changed: 1, Skipped: 1},
+			},
+			expectContains: []string{
+				"    ok: [localhost] => facts are typed",
+				"    ok: [localhost] => ports: [80,443]",
+				"    skipped: [localhost] => verbosity threshold not met",
+				"    ok: [localhost] => was skipped",
+			},
+		},
+		{
+			name: "verbosity runs verbose debug",
+			opts: []executor.Option{
+				executor.WithVerbosity(1),
+			},
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{{
+					Name:   "only verbose",
+					Module: "debug",
+					RawArgs: map[string]interface{}{
+						"msg":       "shown",
+						"verbosity": 1,
+					},
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 1},
+			},
+			expectContains: []string{
+				"    ok: [localhost] => shown",
+			},
+		},
+		{
+			name: "failed assert stops the host",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:   "check",
+						Module: "assert",
+						RawArgs: map[string]interface{}{
+							"that":     "1 == 2",
+							"fail_msg": "one is not two",
+						},
+					},
+					{
+						Name:    "never runs",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{},
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				"    failed: [localhost] => one is not two",
+			},
+			expectMissing: []string{
+				"never runs",
+			},
+		},
+		{
+			name: "fail renders its message",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Vars: map[string]interface{}{
+					"reason": "maintenance",
+				},
+				Tasks: []ansible.Task{{
+					Name:   "give up",
+					Module: "fail",
+					RawArgs: map[string]interface{}{
+						"msg": "stopped for {{ reason }}",
+					},
+				}},
+			}},
+			expected: executor.Stats{
+				"localhost": {Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				"    failed: [localhost] => stopped for maintenance",
+			},
+		},
+		{
+			name: "command results feed later tasks",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:   "list",
+						Module: "ansible.builtin.shell",
+						RawArgs: map[string]interface{}{
+							"__value__": "printf 'a\\nb\\n' | sort -r",
+						},
+						Register: "listed",
+					},
+					{
+						Name:   "greet",
+						Module: "script",
+						RawArgs: map[string]interface{}{
+							"__value__": "hello.sh {{ listed.stdout_lines | first }}",
+						},
+						Register: "greeted",
+						Role:     role,
+						When:     []string{"listed.rc == 0"},
+					},
+					{
+						Name:   "show",
+						Module: "debug",
+						RawArgs: map[string]interface{}{
+							"msg": "{{ greeted.stdout }} {{ listed.stdout_lines }}",
+						},
+					},
+					{
+						Name:   "exit non-zero",
+						Module: "command",
+						RawArgs: map[string]interface{}{
+							"argv": []interface{}{"false"},
+						},
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 3, Changed: 2, Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				"    changed: [localhost]\n",
+				"    ok: [localhost] => hello b ['b', 'a']",
+				"    failed: [localhost] => non-zero return code",
+			},
+		},
+		{
+			name: "registered host output is never rendered",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{
+						Name:   "read",
+						Module: "shell",
+						RawArgs: map[string]interface{}{
+							"__value__": `echo "{""{ 7 * 7 }""}"`,
+						},
+						Register: "r",
+					},
+					{
+						Name:    "msg",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{"msg": "msg {{ r.stdout }}"},
+					},
+					{
+						Name:    "var",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{"var": "r.stdout"},
+					},
+					{
+						Name:    "copy",
+						Module:  "set_fact",
+						RawArgs: map[string]interface{}{"out": "{{ r.stdout }}"},
+					},
+					{
+						Name:    "fact",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{"msg": "fact {{ out }}"},
+					},
+					{
+						Name:    "item",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{"msg": "item {{ item }}"},
+						Loop:    "{{ r.stdout_lines }}",
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 6, Changed: 1},
+			},
+			expectContains: []string{
+				"    ok: [localhost] => msg {{ 7 * 7 }}\n",
+				"    ok: [localhost] => r.stdout: {{ 7 * 7 }}\n",
+				"    ok: [localhost] => fact {{ 7 * 7 }}\n",
+				"item {{ 7 * 7 }}",
+			},
+		},
+		{
+			name: "template and copy only change what differs",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Vars: map[string]interface{}{
+					"ports": []interface{}{80, 443},
+					"dest":  destDir,
+				},
+				Tasks: []ansible.Task{
+					{
+						Name:   "render",
+						Module: "template",
+						RawArgs: map[string]interface{}{
+							"src":  "app.conf.j2",
+							"dest": "{{ dest }}/app.conf",
+						},
+						Role: role,
+					},
+					{
+						Name:   "render again",
+						Module: "ansible.builtin.template",
+						RawArgs: map[string]interface{}{
+							"src":  "app.conf.j2",
+							"dest": "{{ dest }}/app.conf",
+						},
+						Role:     role,
+						Register: "rendered",
+					},
+					{
+						Name:   "copy the same content",
+						Module: "copy",
+						RawArgs: map[string]interface{}{
+							"content": "# Ansible managed\nlisten 80\nlisten 443\n",
+							"dest":    "{{ dest }}/app.conf",
+						},
+						When: []string{"not rendered.changed"},
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 3, Changed: 1},
+			},
+			expectContains: []string{
+				"  ▸ Task: render\n    changed: [localhost]\n",
+				"  ▸ Task: render again\n    ok: [localhost]\n",
+				"  ▸ Task: copy the same content\n    ok: [localhost]\n",
+			},
+		},
+		{
+			name: "file, stat and find report on the host",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Vars: map[string]interface{}{
+					"dir": destDir + "/state",
+				},
+				Tasks: []ansible.Task{
+					{
+						Name:   "create dir",
+						Module: "file",
+						RawArgs: map[string]interface{}{
+							"path":  "{{ dir }}",
+							"state": "directory",
+							"mode":  "0750",
+						},
+					},
+					{
+						Name:     "stat dir",
+						Module:   "stat",
+						RawArgs:  map[string]interface{}{"path": "{{ dir }}"},
+						Register: "dir_stat",
+					},
+					{
+						Name:   "touch when a directory",
+						Module: "file",
+						RawArgs: map[string]interface{}{
+							"path":  "{{ dir }}/marker",
+							"state": "touch",
+						},
+						When: []string{"dir_stat.stat.isdir", "dir_stat.stat.mode == '0750'"},
+					},
+					{
+						Name:     "find markers",
+						Module:   "find",
+						RawArgs:  map[string]interface{}{"paths": "{{ dir }}", "patterns": "mark*"},
+						Register: "found",
+					},
+					{
+						Name:    "show matched",
+						Module:  "debug",
+						RawArgs: map[string]interface{}{"msg": "matched {{ found.matched }}"},
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 5, Changed: 2},
+			},
+			expectContains: []string{
+				"  ▸ Task: create dir\n    changed: [localhost]\n",
+				"  ▸ Task: touch when a directory\n    changed: [localhost]\n",
+				"  ▸ Task: show matched\n    ok: [localhost] => matched 1\n",
+			},
+		},
+		{
+			name: "text editing modules are idempotent",
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Vars: map[string]interface{}{
+					"conf": destDir + "/edit.conf",
+					"app":  "web",
+					"port": 8080,
+				},
+				Tasks: []ansible.Task{
+					{
+						Name:   "seed",
+						Module: "copy",
+						RawArgs: map[string]interface{}{
+							"content": "user=nobody\n",
+							"dest":    "{{ conf }}",
+							"force":   false,
+						},
+					},
+					{
+						Name:   "set line",
+						Module: "lineinfile",
+						RawArgs: map[string]interface{}{
+							"path":   "{{ conf }}",
+							"regexp": "^port=",
+							"line":   "port={{ port }}",
+							"create": true,
+						},
+					},
+					{
+						Name:   "set block",
+						Module: "blockinfile",
+						RawArgs: map[string]interface{}{
+							"path":   "{{ conf }}",
+							"marker": "# {mark} {{ app }}",
+							"block":  "debug=false",
+						},
+					},
+					{
+						Name:   "replace user",
+						Module: "replace",
+						RawArgs: map[string]interface{}{
+							"path":    "{{ conf }}",
+							"regexp":  `^user=(nobody)$`,
+							"replace": `user={{ app }} # was \1`,
+						},
+					},
+					{
+						Name:   "seed again",
+						Module: "copy",
+						RawArgs: map[string]interface{}{
+							"content": "user=nobody\n",
+							"dest":    "{{ conf }}",
+							"force":   false,
+						},
+					},
+					{
+						Name:   "set line again",
+						Module: "lineinfile",
+						RawArgs: map[string]interface{}{
+							"path":   "{{ conf }}",
+							"regexp": "^port=",
+							"line":   "port={{ port }}",
+							"create": true,
+						},
+					},
+					{
+						Name:   "set block again",
+						Module: "blockinfile",
+						RawArgs: map[string]interface{}{
+							"path":   "{{ conf }}",
+							"marker": "# {mark} {{ app }}",
+							"block":  "debug=false",
+						},
+					},
+					{
+						Name:   "replace user again",
+						Module: "replace",
+						RawArgs: map[string]interface{}{
+							"path":    "{{ conf }}",
+							"regexp":  `^user=(nobody)$`,
+							"replace": `user={{ app }} # was \1`,
+						},
+					},
+					{
+						Name:    "check marker",
+						Module:  "command",
+						RawArgs: map[string]interface{}{"__value__": "grep -qx '# BEGIN web' {{ conf }}"},
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"localhost": {OK: 9, Changed: 5},
+			},
+			expectContains: []string{
+				"  ▸ Task: set line\n    changed: [localhost] => line added\n",
+				"  ▸ Task: set block\n    changed: [localhost] => Block inserted\n",
+				"  ▸ Task: replace user\n    changed: [localhost] => 1 replacements made\n",
+				"  ▸ Task: seed again\n    ok: [localhost]\n",
+				"  ▸ Task: set line again\n    ok: [localhost]\n",
+				"  ▸ Task: set block again\n    ok: [localhost]\n",
+				"  ▸ Task: replace user again\n    ok: [localhost]\n",
+				"  ▸ Task: check marker\n    changed: [localhost]\n",
+			},
+		},
+		{
+			name: "modules run commands over the host connection",
+			opts: []executor.Option{
+				executor.WithInventory(inv),
+			},
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "web1:db1",
+				Vars: map[string]interface{}{
+					"ansible_connection": "{{ 'local' if inventory_hostname == 'web1' else 'winrm' }}",
+				},
+				Tasks: []ansible.Task{{
+					Name:   "run echo",
+					Module: "voidspan.test.exec",
+					RawArgs: map[string]interface{}{
+						"cmd": "echo connected to {{ inventory_hostname }}",
+					},
+				}},
+			}},
+			expected: executor.Stats{
+				"web1": {OK: 1},
+				"db1":  {Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				"    ok: [web1] => connected to web1",
+				`    failed: [db1] => failed to connect: unsupported connection type "winrm"`,
+			},
+		},
+		{
+			name: "become wraps commands and variables override keywords",
+			opts: []executor.Option{
+				executor.WithInventory(inv),
+			},
+			plays: []ansible.Play{{
+				Name:  "test play",
+				Hosts: "web1",
+				Vars: map[string]interface{}{
+					"ansible_connection": "local",
+					// env -i -u root stands in for sudo
+					"ansible_become_exe":   "env",
+					"ansible_become_flags": "-i",
+				},
+				Tasks: []ansible.Task{
+					{
+						Name:   "run as root",
+						Module: "voidspan.test.exec",
+						RawArgs: map[string]interface{}{
+							"cmd": `echo "became with HOME=$HOME"`,
+						},
+						Become: ansible.Become{Enabled: &[]bool{true}[0]},
+					},
+					{
+						Name:   "unsupported method",
+						Module: "voidspan.test.exec",
+						RawArgs: map[string]interface{}{
+							"cmd": "true",
+						},
+						Vars: map[string]interface{}{
+							"ansible_become":        "yes",
+							"ansible_become_method": "doas",
+						},
+					},
+				},
+			}},
+			expected: executor.Stats{
+				"web1": {OK: 1, Failed: 1},
+			},
+			expectFailed: true,
+			expectContains: []string{
+				"    ok: [web1] => became with HOME=\n",
+				`    failed: [web1] => unsupported become method "doas"`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			var out bytes.Buffer
+
+			stats := executor.New(s.renderer, s.modules, &out, tc.opts...).Run(tc.plays)
+
+			s.Equal(tc.expected, stats)
+			s.Equal(tc.expectFailed, stats.Failed())
+			for _, want := range tc.expectContains {
+				s.Contains(out.String(), want)
+			}
+			for _, unwanted := range tc.expectMissing {
+				s.NotContains(out.String(), unwanted)
+			}
+		})
+	}
+}
+
+func (s *ExecutorPublicTestSuite) TestRunPlaybook() {
+	tests := []struct {
+		name           string
+		playbook       string
+		files          map[string]string
+		expected       executor.Stats
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			name: "role included twice notifies its handler once",
+			playbook: `
+- name: twice
+  hosts: all
+  tasks:
+    - include_role:
+        name: web
+    - include_role:
+        name: web
+`,
+			files: map[string]string{
+				"web/tasks/main.yml": `
+- name: configure
+  command: "true"
+  notify: restart
+`,
+				"web/handlers/main.yml": `
+- name: restart
+  debug:
+    msg: restarted
+`,
+			},
+			expected: executor.Stats{
+				"localhost": {OK: 3, Changed: 2},
+			},
+			expectContains: []string{
+				"  ▸ Handler: restart\n    ok: [localhost] => restarted\n",
+			},
+		},
+		{
+			name: "include_role vars reach the role's handlers",
+			playbook: `
+- name: include vars
+  hosts: all
+  tasks:
+    - include_role:
+        name: app
+      vars:
+        service: nginx
+`,
+			files: map[string]string{
+				"app/tasks/main.yml": `
+- name: configure
+  command: "true"
+  notify: restart
+`,
+				"app/handlers/main.yml": `
+- name: restart
+  debug:
+    msg: restarted {{ service }}
+`,
+			},
+			expected: executor.Stats{
+				"localhost": {OK: 2, Changed: 1},
+			},
+			expectContains: []string{
+				"  ▸ Handler: restart\n    ok: [localhost] => restarted nginx\n",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			dir := s.T().TempDir()
+			for name, content := range tc.files {
+				file := filepath.Join(dir, name)
+				s.Require().NoError(os.MkdirAll(filepath.Dir(file), 0o755))
+				s.Require().NoError(os.WriteFile(file, []byte(content), 0o644))
+			}
+
+			path := filepath.Join(dir, "playbook.yml")
+			plays, err := ansible.LoadPlaybook([]byte(tc.playbook), path, filepath.Dir(path))
+			s.Require().NoError(err)
+
+			var out bytes.Buffer
+			stats := executor.New(s.renderer, s.modules, &out).Run(plays)
+
+			s.Equal(tc.expected, stats)
+			for _, want := range tc.expectContains {
+				s.Contains(out.String(), want)
+			}
+			for _, unwanted := range tc.expectMissing {
+				s.NotContains(out.String(), unwanted)
+			}
+		})
+	}
+}
+
+func (s *ExecutorPublicTestSuite) TestForks() {
+	inv := inventory.New()
+	hosts := []string{"h1", "h2", "h3", "h4", "h5", "h6", "h7"}
+	for _, host := range hosts {
+		inv.AddHost(host, inventory.UngroupedGroup)
+	}
+
+	tests := []struct {
+		name        string
+		opts        []executor.Option
+		expectForks int32
+	}{
+		{
+			name:        "default forks",
+			expectForks: 5,
+		},
+		{
+			name:        "three forks",
+			opts:        []executor.Option{executor.WithForks(3)},
+			expectForks: 3,
+		},
+		{
+			name:        "forks below one run hosts one at a time",
+			opts:        []executor.Option{executor.WithForks(0)},
+			expectForks: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			var running, peak atomic.Int32
+			modules := module.NewRegistry()
+			err := modules.Register(
+				"voidspan.test.sleep",
+				module.Func(func(ctx *module.Context, _ map[string]interface{}) (*module.Result, error) {
+					n := running.Add(1)
+					defer running.Add(-1)
+					for {
+						p := peak.Load()
+						if n <= p || peak.CompareAndSwap(p, n) {
+							break
+						}
+					}
+
+					// later hosts finish first
+					i := slices.Index(hosts, ctx.Host)
+					time.Sleep(time.Duration(len(hosts)-i) * 5 * time.Millisecond)
+
+					return &module.Result{Msg: "slept"}, nil
+				}),
+			)
+			s.Require().NoError(err)
+
+			var out bytes.Buffer
+			opts := append([]executor.Option{executor.WithInventory(inv)}, tc.opts...)
+			stats := executor.New(s.renderer, modules, &out, opts...).Run([]ansible.Play{{
+				Name:  "test play",
+				Hosts: "all",
+				Tasks: []ansible.Task{
+					{Name: "first", Module: "voidspan.test.sleep"},
+					{Name: "second", Module: "voidspan.test.sleep"},
+				},
+			}})
+
+			s.False(stats.Failed())
+			s.Equal(tc.expectForks, peak.Load())
+
+			var want strings.Builder
+			want.WriteString("▶ Play: test play (hosts: all)\n")
+			for _, task := range []string{"first", "second"} {
+				want.WriteString("  ▸ Task: " + task + "\n")
+				for _, host := range hosts {
+					want.WriteString("    ok: [" + host + "] => slept\n")
+				}
+			}
+			s.True(strings.HasPrefix(out.String(), want.String()), out.String())
+		})
+	}
+}
+
+func (s *ExecutorPublicTestSuite) TestStrategies() {
+	inv := inventory.New()
+	inv.AddHost("slow", inventory.UngroupedGroup)
+	inv.AddHost("fast", inventory.UngroupedGroup)
+
+	tests := []struct {
+		name           string
+		opts           []executor.Option
+		strategy       string
+		expectOrder    []string
+		expectContains []string
+	}{
+		{
+			name:        "linear waits for every host",
+			expectOrder: []string{"fast first", "slow first", "fast second", "slow second"},
+		},
+		{
+			name:        "free lets hosts run ahead",
+			strategy:    "free",
+			expectOrder: []string{"fast first", "fast second", "slow first", "slow second"},
+			expectContains: []string{
+				"  ▸ Task: first\n    ok: [fast]\n  ▸ Task: second\n    ok: [fast]\n",
+			},
+		},
+		{
+			name: "host_pinned keeps a fork per host",
+			opts: []executor.Option{
+				executor.WithStrategy("host_pinned"),
+				executor.WithForks(1),
+			},
+			expectOrder: []string{"slow first", "slow second", "fast first", "fast second"},
+		},
+		{
+			name: "play strategy overrides the default",
+			opts: []executor.Option{
+				executor.WithStrategy("host_pinned"),
+				executor.WithForks(1),
+			},
+			strategy:    "linear",
+			expectOrder: []string{"slow first", "fast first", "slow second", "fast second"},
+		},
+		{
+			name:           "unknown strategy",
+			strategy:       "bogus",
+			expectContains: []string{`  skipping: unknown strategy "bogus"`},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			var (
+				mu    sync.Mutex
+				order []string
+			)
+			modules := module.NewRegistry()
+			err := modules.Register(
+				"voidspan.test.sleep",
+				module.Func(func(ctx *module.Context, _ map[string]interface{}) (*module.Result, error) {
+					if ctx.Host == "slow" {
+						time.Sleep(100 * time.Millisecond)
+					}
+
+					mu.Lock()
+					order = append(order, ctx.Host+" "+ctx.Task)
+					mu.Unlock()
+
+					return &module.Result{}, nil
+				}),
+			)
+			s.Require().NoError(err)
+
+			var out bytes.Buffer
+			opts := append([]executor.Option{executor.WithInventory(inv)}, tc.opts...)
+			executor.New(s.renderer, modules, &out, opts...).Run([]ansible.Play{{
+				Name:     "test play",
+				Hosts:    "all",
+				Strategy: tc.strategy,
+				Tasks: []ansible.Task{
+					{Name: "first", Module: "voidspan.test.sleep"},
+					{Name: "second", Module: "voidspan.test.sleep"},
+				},
+			}})
+
+			s.Equal(tc.expectOrder, order)
+			for _, want := range tc.expectContains {
+				s.Contains(out.String(), want)
+			}
+		})
+	}
+}
+
+func (s *ExecutorPublicTestSuite) TestSerial() {
+	inv := inventory.New()
+	for _, host := range []string{"h1", "h2", "h3", "h4", "h5"} {
+		inv.AddHost(host, inventory.UngroupedGroup)
+	}
+
+	batch := ansible.Task{
+		Name:    "batch",
+		Module:  "ansible.builtin.debug",
+		RawArgs: map[string]interface{}{"msg": "{{ ansible_play_batch | join(',') }}"},
+	}
+	maybeFail := ansible.Task{
+		Name:   "maybe fail",
+		Module: "voidspan.test.missing",
+		When:   []string{"inventory_hostname in fail_hosts"},
+	}
+	after := ansible.Task{Name: "after", Module: "voidspan.test.fact"}
+
+	tests := []struct {
+		name           string
+		play           ansible.Play
+		expected       executor.Stats
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			name: "serial runs the whole play batch by batch",
+			play: ansible.Play{
+				Serial: []ansible.BatchSize{{Value: 2}},
+				Tasks:  []ansible.Task{batch, after},
+			},
+			expected: executor.Stats{
+				"h1": {OK: 2},
+				"h2": {OK: 2},
+				"h3": {OK: 2},
+				"h4": {OK: 2},
+				"h5": {OK: 2},
+			},
+			expectContains: []string{
+				"  ▸ Batch 1/3 (hosts: h1,h2)\n" +
+					"  ▸ Task: batch\n" +
+					"    ok: [h1] => h1,h2\n" +
+					"    ok: [h2] => h1,h2\n" +
+					"  ▸ Task: after\n" +
+					"    ok: [h1]\n" +
+					"    ok: [h2]\n" +
+					"  ▸ Batch 2/3 (hosts: h3,h4)\n",
+				"  ▸ Batch 3/3 (hosts: h5)\n",
+			},
+		},
+		{
+			name: "list of batch sizes repeats the last one",
+			play: ansible.Play{
+				Serial: []ansible.BatchSize{{Value: 1}, {Value: 50, Percent: true}},
+				Tasks:  []ansible.Task{batch},
+			},
+			expected: executor.Stats{
+				"h1": {OK: 1},
+				"h2": {OK: 1},
+				"h3": {OK: 1},
+				"h4": {OK: 1},
+				"h5": {OK: 1},
+			},
+			expectContains: []string{
+				"  ▸ Batch 1/3 (hosts: h1)\n",
+				"  ▸ Batch 2/3 (hosts: h2,h3)\n",
+				"  ▸ Batch 3/3 (hosts: h4,h5)\n",
+			},
+		},
+		{
+			name: "max_fail_percentage aborts the following batches",
+			play: ansible.Play{
+				Serial:            []ansible.BatchSize{{Value: 2}},
+				MaxFailPercentage: &[]int{40}[0],
+				Vars:              map[string]interface{}{"fail_hosts": []interface{}{"h3"}},
+				Tasks:             []ansible.Task{maybeFail, after},
+			},
+			expected: executor.Stats{
+				"h1": {OK: 1, Skipped: 1},
+				"h2": {OK: 1, Skipped: 1},
+				"h3": {Failed: 1},
+				"h4": {Skipped: 1},
+			},
+			expectContains: []string{
+				"  aborting: 1 of 2 hosts failed, exceeding max_fail_percentage of 40%\n",
+			},
+			expectMissing: []string{"Batch 3/3"},
+		},
+		{
+			name: "failures within max_fail_percentage",
+			play: ansible.Play{
+				MaxFailPercentage: &[]int{20}[0],
+				Vars:              map[string]interface{}{"fail_hosts": []interface{}{"h3"}},
+				Tasks:             []ansible.Task{maybeFail, after},
+			},
+			expected: executor.Stats{
+				"h1": {OK: 1, Skipped: 1},
+				"h2": {OK: 1, Skipped: 1},
+				"h3": {Failed: 1},
+				"h4": {OK: 1, Skipped: 1},
+				"h5": {OK: 1, Skipped: 1},
+			},
+			expectMissing: []string{"aborting"},
+		},
+		{
+			name: "any_errors_fatal stops every host",
+			play: ansible.Play{
+				AnyErrorsFatal: true,
+				Vars:           map[string]interface{}{"fail_hosts": []interface{}{"h2"}},
+				Tasks:          []ansible.Task{maybeFail, after},
+			},
+			expected: executor.Stats{
+				"h1": {Skipped: 1},
+				"h2": {Failed: 1},
+				"h3": {Skipped: 1},
+				"h4": {Skipped: 1},
+				"h5": {Skipped: 1},
+			},
+			expectContains: []string{"  aborting: a task failed and any_errors_fatal is set\n"},
+			expectMissing:  []string{"Task: after"},
+		},
+		{
+			name: "any_errors_fatal ignores rescued failures",
+			play: ansible.Play{
+				AnyErrorsFatal: true,
+				Vars:           map[string]interface{}{"fail_hosts": []interface{}{"h2"}},
+				Tasks: []ansible.Task{
+					{
+						Name:   "guarded",
+						Block:  []ansible.Task{maybeFail},
+						Rescue: []ansible.Task{{Name: "recover", Module: "voidspan.test.fact"}},
+					},
+					after,
+				},
+			},
+			expected: executor.Stats{
+				"h1": {OK: 1, Skipped: 1},
+				"h2": {OK: 2, Rescued: 1},
+				"h3": {OK: 1, Skipped: 1},
+				"h4": {OK: 1, Skipped: 1},
+				"h5": {OK: 1, Skipped: 1},
+			},
+			expectMissing: []string{"aborting"},
+		},
+		{
+			name: "a batch where every host failed aborts the play",
+			play: ansible.Play{
+				Serial: []ansible.BatchSize{{Value: 2}},
+				Vars:   map[string]interface{}{"fail_hosts": []interface{}{"h1", "h2"}},
+				Tasks:  []ansible.Task{maybeFail},
+			},
+			expected: executor.Stats{
+				"h1": {Failed: 1},
+				"h2": {Failed: 1},
+			},
+			expectContains: []string{"  aborting: every host of the batch failed\n"},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			var out bytes.Buffer
+
+			tc.play.Name = "test play"
+			tc.play.Hosts = "all"
+			stats := executor.New(s.renderer, s.modules, &out, executor.WithInventory(inv)).
+				Run([]ansible.Play{tc.play})
+
+			s.Equal(tc.expected, stats)
+			for _, want := range tc.expectContains {
+				s.Contains(out.String(), want)
+			}
+			for _, unwanted := range tc.expectMissing {
+				s.NotContains(out.String(), unwanted)
+			}
+		})
+	}
+}
+
+func TestExecutorPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(ExecutorPublicTestSuite))
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"slices"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+// notify marks every handler whose name or listen topics match one of the
+// notify targets.
+func notify(
+	handlers []ansible.Task,
+	targets []string,
+	notified map[int]bool,
+) {
+	for _, target := range targets {
+		for i, h := range handlers {
+			if h.Name == target || slices.Contains(h.Listen, target) {
+				notified[i] = true
+			}
+		}
+	}
+}
+
+// flushHandlers runs the notified handlers once each on the hosts that
+// notified them, in the order they are defined, and returns the hosts that
+// are still healthy afterwards. Handlers may notify handlers defined after
+// them.
+func (e *Executor) flushHandlers(
+	run *playRun,
+	hosts []string,
+) []string {
+	for i, h := range run.play.Handlers {
+		var targets []string
+		run.mu.Lock()
+		for _, host := range hosts {
+			if run.notified[host][i] {
+				delete(run.notified[host], i)
+				targets = append(targets, host)
+			}
+		}
+		run.mu.Unlock()
+		if len(targets) == 0 {
+			continue
+		}
+
+		healthy := e.runOnHosts(run, "Handler", h, targets)
+		hosts = without(hosts, without(targets, healthy))
+	}
+
+	return hosts
+}
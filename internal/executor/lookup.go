@@ -0,0 +1,299 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+// lookupFunc produces the items of a with_<lookup> loop from its evaluated
+// terms.
+type lookupFunc func(task ansible.Task, terms interface{}) ([]interface{}, error)
+
+// lookups maps the supported with_* loops to their implementation.
+var lookups = map[string]lookupFunc{
+	"dict":          lookupDict,
+	"fileglob":      lookupFileglob,
+	"flattened":     lookupFlattened,
+	"indexed_items": lookupIndexedItems,
+	"items":         lookupItems,
+	"list":          lookupList,
+	"nested":        lookupNested,
+	"together":      lookupTogether,
+}
+
+// lookupItems flattens the terms by one level.
+func lookupItems(
+	_ ansible.Task,
+	terms interface{},
+) ([]interface{}, error) {
+	var items []interface{}
+	for _, term := range toList(terms) {
+		if list, ok := term.([]interface{}); ok {
+			items = append(items, list...)
+			continue
+		}
+		items = append(items, term)
+	}
+
+	return items, nil
+}
+
+// lookupList returns the terms unchanged.
+func lookupList(
+	_ ansible.Task,
+	terms interface{},
+) ([]interface{}, error) {
+	return toList(terms), nil
+}
+
+// lookupFlattened flattens nested lists completely.
+func lookupFlattened(
+	_ ansible.Task,
+	terms interface{},
+) ([]interface{}, error) {
+	var items []interface{}
+
+	var flatten func(list []interface{})
+	flatten = func(list []interface{}) {
+		for _, term := range list {
+			if nested, ok := term.([]interface{}); ok {
+				flatten(nested)
+				continue
+			}
+			items = append(items, term)
+		}
+	}
+	flatten(toList(terms))
+
+	return items, nil
+}
+
+// lookupIndexedItems pairs every item, flattened as with_items, with its
+// index.
+func lookupIndexedItems(
+	task ansible.Task,
+	terms interface{},
+) ([]interface{}, error) {
+	flat, err := lookupItems(task, terms)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]interface{}, len(flat))
+	for i, item := range flat {
+		items[i] = []interface{}{i, item}
+	}
+
+	return items, nil
+}
+
+// lookupDict turns a mapping into key/value items sorted by key.
+func lookupDict(
+	_ ansible.Task,
+	terms interface{},
+) ([]interface{}, error) {
+	dict, ok := terms.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("with_dict expects a dict, got %T", terms)
+	}
+
+	keys := make([]string, 0, len(dict))
+	for k := range dict {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	items := make([]interface{}, len(keys))
+	for i, k := range keys {
+		items[i] = map[string]interface{}{
+			"key":   k,
+			"value": dict[k],
+		}
+	}
+
+	return items, nil
+}
+
+// lookupTogether zips lists, padding the shorter ones with nil.
+func lookupTogether(
+	_ ansible.Task,
+	terms interface{},
+) ([]interface{}, error) {
+	lists, err := toLists("with_together", terms)
+	if err != nil {
+		return nil, err
+	}
+
+	var length int
+	for _, list := range lists {
+		length = max(length, len(list))
+	}
+
+	items := make([]interface{}, length)
+	for i := range items {
+		item := make([]interface{}, len(lists))
+		for j, list := range lists {
+			if i < len(list) {
+				item[j] = list[i]
+			}
+		}
+		items[i] = item
+	}
+
+	return items, nil
+}
+
+// lookupNested returns every combination of one element from each list.
+func lookupNested(
+	_ ansible.Task,
+	terms interface{},
+) ([]interface{}, error) {
+	lists, err := toLists("with_nested", terms)
+	if err != nil {
+		return nil, err
+	}
+
+	combos := [][]interface{}{{}}
+	for _, list := range lists {
+		var next [][]interface{}
+		for _, combo := range combos {
+			for _, elem := range list {
+				next = append(next, append(combo[:len(combo):len(combo)], elem))
+			}
+		}
+		combos = next
+	}
+
+	items := make([]interface{}, len(combos))
+	for i, combo := range combos {
+		items[i] = combo
+	}
+
+	return items, nil
+}
+
+// lookupFileglob returns the files matching each pattern. Relative patterns
+// are resolved against the role's files directory and the directory of the
+// task's file, the first directory with matches winning.
+func lookupFileglob(
+	task ansible.Task,
+	terms interface{},
+) ([]interface{}, error) {
+	var items []interface{}
+	for _, term := range toList(terms) {
+		pattern, ok := term.(string)
+		if !ok {
+			return nil, fmt.Errorf("with_fileglob expects patterns, got %T", term)
+		}
+
+		matches, err := fileglob(pattern, searchDirs(task))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			items = append(items, match)
+		}
+	}
+
+	return items, nil
+}
+
+// fileglob returns the regular files matching pattern, in sorted order.
+func fileglob(
+	pattern string,
+	dirs []string,
+) ([]string, error) {
+	if filepath.IsAbs(pattern) {
+		dirs = []string{""}
+	}
+
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid fileglob pattern %q: %w", pattern, err)
+		}
+
+		var files []string
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.Mode().IsRegular() {
+				files = append(files, match)
+			}
+		}
+
+		if len(files) > 0 {
+			sort.Strings(files)
+			return files, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// searchDirs returns the directories relative lookup paths of a task are
+// resolved against, in order of preference.
+func searchDirs(
+	task ansible.Task,
+) []string {
+	var dirs []string
+	if task.Role != nil {
+		dirs = append(dirs, filepath.Join(task.Role.Path, "files"), task.Role.Path)
+	}
+
+	sourceDir := filepath.Dir(task.Source)
+	return append(dirs, filepath.Join(sourceDir, "files"), sourceDir)
+}
+
+// toList returns terms as a list, wrapping a single value.
+func toList(
+	terms interface{},
+) []interface{} {
+	switch val := terms.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return val
+	default:
+		return []interface{}{val}
+	}
+}
+
+// toLists returns terms as a list of lists.
+func toLists(
+	lookup string,
+	terms interface{},
+) ([][]interface{}, error) {
+	var lists [][]interface{}
+	for _, term := range toList(terms) {
+		list, ok := term.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s expects a list of lists, got %T", lookup, term)
+		}
+		lists = append(lists, list)
+	}
+
+	return lists, nil
+}
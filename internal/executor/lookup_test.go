@@ -0,0 +1,227 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+type LookupTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+}
+
+func (s *LookupTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-lookup-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+}
+
+func (s *LookupTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func (s *LookupTestSuite) TestLookups() {
+	tests := []struct {
+		name              string
+		lookup            string
+		task              ansible.Task
+		terms             interface{}
+		expected          []interface{}
+		expectErr         bool
+		expectErrContains string
+		prepare           func(dir string)
+	}{
+		{
+			name:     "items flattens one level",
+			lookup:   "items",
+			terms:    []interface{}{"a", []interface{}{"b", []interface{}{"c"}}},
+			expected: []interface{}{"a", "b", []interface{}{"c"}},
+		},
+		{
+			name:     "items wraps a single value",
+			lookup:   "items",
+			terms:    "a",
+			expected: []interface{}{"a"},
+		},
+		{
+			name:     "list keeps nested lists",
+			lookup:   "list",
+			terms:    []interface{}{"a", []interface{}{"b"}},
+			expected: []interface{}{"a", []interface{}{"b"}},
+		},
+		{
+			name:     "flattened flattens completely",
+			lookup:   "flattened",
+			terms:    []interface{}{"a", []interface{}{"b", []interface{}{"c"}}},
+			expected: []interface{}{"a", "b", "c"},
+		},
+		{
+			name:   "indexed_items",
+			lookup: "indexed_items",
+			terms:  []interface{}{"a", "b"},
+			expected: []interface{}{
+				[]interface{}{0, "a"},
+				[]interface{}{1, "b"},
+			},
+		},
+		{
+			name:   "dict sorted by key",
+			lookup: "dict",
+			terms:  map[string]interface{}{"b": 2, "a": 1},
+			expected: []interface{}{
+				map[string]interface{}{"key": "a", "value": 1},
+				map[string]interface{}{"key": "b", "value": 2},
+			},
+		},
+		{
+			name:              "dict of a list",
+			lookup:            "dict",
+			terms:             []interface{}{"a"},
+			expectErr:         true,
+			expectErrContains: "with_dict expects a dict",
+		},
+		{
+			name:   "together pads shorter lists",
+			lookup: "together",
+			terms: []interface{}{
+				[]interface{}{"a", "b"},
+				[]interface{}{1},
+			},
+			expected: []interface{}{
+				[]interface{}{"a", 1},
+				[]interface{}{"b", nil},
+			},
+		},
+		{
+			name:   "nested returns every combination",
+			lookup: "nested",
+			terms: []interface{}{
+				[]interface{}{"a", "b"},
+				[]interface{}{1, 2},
+			},
+			expected: []interface{}{
+				[]interface{}{"a", 1},
+				[]interface{}{"a", 2},
+				[]interface{}{"b", 1},
+				[]interface{}{"b", 2},
+			},
+		},
+		{
+			name:              "nested of scalars",
+			lookup:            "nested",
+			terms:             []interface{}{"a"},
+			expectErr:         true,
+			expectErrContains: "with_nested expects a list of lists",
+		},
+		{
+			name:   "fileglob prefers the role files directory",
+			lookup: "fileglob",
+			task: ansible.Task{
+				Source: "playbook.yml",
+				Role:   &ansible.Role{Path: "roles/web"},
+			},
+			terms: "*.conf",
+			expected: []interface{}{
+				"roles/web/files/a.conf",
+				"roles/web/files/b.conf",
+			},
+			prepare: func(dir string) {
+				for _, name := range []string{
+					"roles/web/files/b.conf",
+					"roles/web/files/a.conf",
+					"files/c.conf",
+				} {
+					path := filepath.Join(dir, name)
+					_ = os.MkdirAll(filepath.Dir(path), 0o755)
+					_ = os.WriteFile(path, nil, 0o644)
+				}
+				_ = os.MkdirAll(filepath.Join(dir, "roles/web/files/dir.conf"), 0o755)
+			},
+		},
+		{
+			name:   "fileglob falls back to the playbook directory",
+			lookup: "fileglob",
+			task: ansible.Task{
+				Source: "playbook.yml",
+			},
+			terms:    []interface{}{"files/*.conf", "missing/*"},
+			expected: []interface{}{"files/c.conf"},
+			prepare: func(dir string) {
+				_ = os.MkdirAll(filepath.Join(dir, "files"), 0o755)
+				_ = os.WriteFile(filepath.Join(dir, "files", "c.conf"), nil, 0o644)
+			},
+		},
+		{
+			name:              "fileglob of a non-string",
+			lookup:            "fileglob",
+			terms:             []interface{}{1},
+			expectErr:         true,
+			expectErrContains: "with_fileglob expects patterns",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			if tc.prepare != nil {
+				tc.prepare(s.tmpDir)
+			}
+
+			task := tc.task
+			task.Source = filepath.Join(s.tmpDir, task.Source)
+			if task.Role != nil {
+				task.Role = &ansible.Role{Path: filepath.Join(s.tmpDir, task.Role.Path)}
+			}
+
+			items, err := lookups[tc.lookup](task, tc.terms)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+
+			if tc.lookup == "fileglob" {
+				for i, item := range items {
+					rel, err := filepath.Rel(s.tmpDir, item.(string))
+					s.Require().NoError(err)
+					items[i] = rel
+				}
+			}
+			s.Equal(tc.expected, items)
+		})
+	}
+}
+
+func TestLookupTestSuite(t *testing.T) {
+	suite.Run(t, new(LookupTestSuite))
+}
@@ -0,0 +1,245 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+// defaultLoopVar is the variable loop items are bound to unless loop_control
+// sets loop_var.
+const defaultLoopVar = "item"
+
+// runLoop runs a task once per loop item and combines the item results.
+// Every item runs even when an earlier one fails.
+func (e *Executor) runLoop(
+	run *playRun,
+	host string,
+	task ansible.Task,
+	taskVars map[string]interface{},
+) *Result {
+	result := &Result{
+		Host: host,
+		Task: task.Name,
+	}
+
+	items, err := e.loopItems(task, taskVars)
+	if err != nil {
+		result.Status = StatusFailed
+		result.Msg = err.Error()
+		return result
+	}
+
+	if len(items) == 0 {
+		result.Status = StatusSkipped
+		result.Msg = "no items in the list"
+		return result
+	}
+
+	lc := task.LoopControl
+	loopVar := lc.LoopVar
+	if loopVar == "" {
+		loopVar = defaultLoopVar
+	}
+
+	itemResults := make([]interface{}, 0, len(items))
+	for i, item := range items {
+		if i > 0 && lc.Pause > 0 {
+			time.Sleep(lc.Pause)
+		}
+
+		// items are already rendered and must not be rendered again
+		itemVars := maps.Clone(taskVars)
+		itemVars[loopVar] = ansible.Unsafe{Value: item}
+		itemVars["ansible_loop_var"] = loopVar
+		if lc.IndexVar != "" {
+			itemVars[lc.IndexVar] = i
+			itemVars["ansible_index_var"] = lc.IndexVar
+		}
+		if lc.Extended {
+			itemVars["ansible_loop"] = ansible.Unsafe{Value: loopDetails(items, i)}
+		}
+
+		itemResult := e.runOnce(run, host, task, itemVars)
+		itemResult.Label = e.itemLabel(lc.Label, item, itemVars)
+		result.Items = append(result.Items, itemResult)
+		itemResults = append(itemResults, itemData(itemResult, loopVar, item))
+	}
+
+	result.Status = combineStatus(result.Items)
+	result.Data = map[string]interface{}{"results": itemResults}
+	if result.Status == StatusFailed {
+		result.Msg = "one or more items failed"
+	}
+
+	return result
+}
+
+// loopItems evaluates the loop of a task into the list of its items.
+func (e *Executor) loopItems(
+	task ansible.Task,
+	taskVars map[string]interface{},
+) ([]interface{}, error) {
+	var terms interface{}
+	var err error
+
+	if loop, ok := task.Loop.(string); ok {
+		terms, err = ansible.EvaluateExpression(loop, taskVars, e.renderer)
+	} else {
+		terms, err = ansible.RenderValue(task.Loop, taskVars, e.renderer)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if task.LoopWith == "" {
+		items, ok := terms.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf(
+				"invalid data passed to 'loop', it requires a list, got this instead: %v",
+				terms,
+			)
+		}
+
+		return items, nil
+	}
+
+	lookup, ok := lookups[task.LoopWith]
+	if !ok {
+		return nil, fmt.Errorf("unsupported loop lookup %q", "with_"+task.LoopWith)
+	}
+
+	return lookup(task, terms)
+}
+
+// itemLabel renders the loop_control label of an item, or formats the item
+// itself when no label is set.
+func (e *Executor) itemLabel(
+	label string,
+	item interface{},
+	itemVars map[string]interface{},
+) string {
+	if label != "" {
+		rendered, err := ansible.RenderValue(label, itemVars, e.renderer)
+		if err == nil {
+			return fmt.Sprint(rendered)
+		}
+	}
+
+	if s, ok := item.(string); ok {
+		return s
+	}
+
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Sprint(item)
+	}
+
+	return string(encoded)
+}
+
+// loopDetails returns the ansible_loop variable exposed by extended loops.
+func loopDetails(
+	items []interface{},
+	index int,
+) map[string]interface{} {
+	details := map[string]interface{}{
+		"allitems":  items,
+		"index":     index + 1,
+		"index0":    index,
+		"revindex":  len(items) - index,
+		"revindex0": len(items) - index - 1,
+		"first":     index == 0,
+		"last":      index == len(items)-1,
+		"length":    len(items),
+	}
+	if index > 0 {
+		details["previtem"] = items[index-1]
+	}
+	if index < len(items)-1 {
+		details["nextitem"] = items[index+1]
+	}
+
+	return details
+}
+
+// itemData returns the result of a single loop item as exposed in the
+// results of the task.
+func itemData(
+	result *Result,
+	loopVar string,
+	item interface{},
+) map[string]interface{} {
+	data := resultData(result)
+	data[loopVar] = item
+	data["ansible_loop_var"] = loopVar
+
+	return data
+}
+
+// resultData returns a task result as exposed to later tasks: the data of
+// the module along with the changed, failed and skipped flags and the
+// message.
+func resultData(
+	result *Result,
+) map[string]interface{} {
+	data := maps.Clone(result.Data)
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	data["changed"] = result.Status == StatusChanged
+	data["failed"] = result.Status == StatusFailed
+	data["skipped"] = result.Status == StatusSkipped
+	if result.Msg != "" {
+		data["msg"] = result.Msg
+	}
+
+	return data
+}
+
+// combineStatus returns the status of a looped task: failed when any item
+// failed, changed when any item changed and skipped when every item was
+// skipped.
+func combineStatus(
+	items []*Result,
+) Status {
+	status := StatusSkipped
+	for _, item := range items {
+		switch item.Status {
+		case StatusFailed:
+			return StatusFailed
+		case StatusChanged:
+			status = StatusChanged
+		case StatusOK:
+			if status == StatusSkipped {
+				status = StatusOK
+			}
+		}
+	}
+
+	return status
+}
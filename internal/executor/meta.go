@@ -0,0 +1,76 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"fmt"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+// isMeta reports whether module is the executor-internal meta module.
+func isMeta(module string) bool {
+	return module == "meta" || module == "ansible.builtin.meta"
+}
+
+// runMeta performs a meta action on hosts and returns the hosts that are
+// still healthy afterwards.
+func (e *Executor) runMeta(
+	run *playRun,
+	task ansible.Task,
+	hosts []string,
+) []string {
+	action := safeString(task.RawArgs["__value__"])
+
+	switch action {
+	case "flush_handlers":
+		return e.flushHandlers(run, hosts)
+	case "noop":
+		return hosts
+	default:
+		run.mu.Lock()
+		defer run.mu.Unlock()
+
+		_, _ = fmt.Fprintf(e.out, "  ▸ Task: %s\n", task.Name)
+
+		for _, host := range hosts {
+			result := &Result{
+				Host:   host,
+				Task:   task.Name,
+				Status: StatusFailed,
+				Msg:    fmt.Sprintf("unsupported meta action %q", action),
+			}
+			run.stats.record(result)
+			run.failed[host] = true
+			e.printResult(result)
+		}
+
+		return nil
+	}
+}
+
+// safeString returns v when it is a string and "" otherwise.
+func safeString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"sort"
+)
+
+// Failed reports whether any host had a failed task.
+func (s Stats) Failed() bool {
+	for _, hs := range s {
+		if hs.Failed > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Hosts returns the host names in sorted order.
+func (s Stats) Hosts() []string {
+	hosts := make([]string, 0, len(s))
+	for host := range s {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	return hosts
+}
+
+// host returns the counters of host, creating them when missing.
+func (s Stats) host(name string) *HostStats {
+	hs, ok := s[name]
+	if !ok {
+		hs = &HostStats{}
+		s[name] = hs
+	}
+
+	return hs
+}
+
+// record counts a result against its host.
+func (s Stats) record(result *Result) {
+	hs := s.host(result.Host)
+
+	switch result.Status {
+	case StatusOK:
+		hs.OK++
+	case StatusChanged:
+		hs.OK++
+		hs.Changed++
+	case StatusFailed:
+		hs.Failed++
+	case StatusSkipped:
+		hs.Skipped++
+	}
+}
+
+// rescue turns the failure that aborted a block into a rescued one.
+func (s Stats) rescue(host string) {
+	hs := s.host(host)
+	hs.Failed--
+	hs.Rescued++
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/executor"
+)
+
+type StatsPublicTestSuite struct {
+	suite.Suite
+}
+
+func (s *StatsPublicTestSuite) TestFailed() {
+	tests := []struct {
+		name     string
+		stats    executor.Stats
+		expected bool
+	}{
+		{
+			name:     "empty stats",
+			stats:    executor.Stats{},
+			expected: false,
+		},
+		{
+			name: "no failures",
+			stats: executor.Stats{
+				"web1": {OK: 2, Changed: 1},
+				"web2": {Skipped: 1},
+			},
+			expected: false,
+		},
+		{
+			name: "one host failed",
+			stats: executor.Stats{
+				"web1": {OK: 2},
+				"web2": {Failed: 1},
+			},
+			expected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, tc.stats.Failed())
+		})
+	}
+}
+
+func (s *StatsPublicTestSuite) TestHosts() {
+	stats := executor.Stats{
+		"web2": {},
+		"db1":  {},
+		"web1": {},
+	}
+
+	s.Equal([]string{"db1", "web1", "web2"}, stats.Hosts())
+}
+
+func TestStatsPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(StatsPublicTestSuite))
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+// defaultStrategy is the strategy of plays that set none, unless
+// WithStrategy sets it.
+const defaultStrategy = "linear"
+
+// strategy decides how the tasks of a play section advance across hosts.
+type strategy interface {
+	// run runs tasks on hosts, then the handlers they notified, and returns
+	// the hosts that are still healthy afterwards.
+	run(e *Executor, run *playRun, tasks []ansible.Task, hosts []string) []string
+}
+
+// strategies holds the strategies by name.
+var strategies = map[string]strategy{
+	"linear":      linear{},
+	"free":        free{},
+	"host_pinned": free{pinned: true},
+}
+
+// ValidateStrategy returns an error when name is not a known strategy.
+func ValidateStrategy(
+	name string,
+) error {
+	if _, ok := strategies[name]; !ok {
+		return fmt.Errorf("unknown strategy %q", name)
+	}
+
+	return nil
+}
+
+// linear runs each task on every host before the next task starts.
+type linear struct{}
+
+func (linear) run(
+	e *Executor,
+	run *playRun,
+	tasks []ansible.Task,
+	hosts []string,
+) []string {
+	hosts = e.runSection(run, tasks, hosts)
+
+	return e.flushHandlers(run, hosts)
+}
+
+// free lets every host run through the tasks on its own, without waiting
+// for the other hosts. Every task run takes one of the forks, so hosts
+// interleave. When pinned, as for host_pinned, a host keeps its fork until
+// it is done and the next host starts only then.
+type free struct {
+	pinned bool
+}
+
+func (s free) run(
+	e *Executor,
+	run *playRun,
+	tasks []ansible.Task,
+	hosts []string,
+) []string {
+	run.pinned = s.pinned
+
+	var (
+		mu      sync.Mutex
+		healthy []string
+		wg      sync.WaitGroup
+	)
+	for _, host := range hosts {
+		if s.pinned {
+			run.forks <- struct{}{}
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				if s.pinned {
+					<-run.forks
+				}
+				wg.Done()
+			}()
+
+			remaining := e.runSection(run, tasks, []string{host})
+			remaining = e.flushHandlers(run, remaining)
+
+			mu.Lock()
+			healthy = append(healthy, remaining...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return keep(hosts, healthy)
+}
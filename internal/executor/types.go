@@ -0,0 +1,71 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+// Status is the outcome of a single task on a single host.
+type Status string
+
+const (
+	// StatusOK means the task ran and made no changes.
+	StatusOK Status = "ok"
+	// StatusChanged means the task ran and changed the host.
+	StatusChanged Status = "changed"
+	// StatusFailed means the task did not complete successfully.
+	StatusFailed Status = "failed"
+	// StatusSkipped means the task was not run.
+	StatusSkipped Status = "skipped"
+)
+
+// Result is the outcome of running a task on a host.
+type Result struct {
+	// Host is the host the task ran against.
+	Host string
+	// Task is the name of the task.
+	Task string
+	// Status is the final status of the task.
+	Status Status
+	// Msg is a human readable summary of the outcome.
+	Msg string
+	// Data holds any additional return values from the module. For a looped
+	// task it holds the item results under "results".
+	Data map[string]interface{}
+	// Label identifies the loop item a per-item result belongs to.
+	Label string
+	// Items holds the per-item results of a looped task.
+	Items []*Result
+}
+
+// HostStats counts task outcomes for a single host.
+type HostStats struct {
+	// OK counts tasks that succeeded, including changed ones.
+	OK int
+	// Changed counts tasks that changed the host.
+	Changed int
+	// Failed counts tasks that failed.
+	Failed int
+	// Skipped counts tasks that were skipped.
+	Skipped int
+	// Rescued counts failures handled by a rescue section.
+	Rescued int
+}
+
+// Stats maps host names to their task outcome counters.
+type Stats map[string]*HostStats
@@ -0,0 +1,132 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package executor
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+	"github.com/retr0h/voidspan/internal/vars"
+)
+
+// taskVars merges every variable source visible to a task on a host by
+// precedence.
+func (e *Executor) taskVars(
+	run *playRun,
+	host string,
+	task ansible.Task,
+) map[string]interface{} {
+	layers := vars.Layers{
+		vars.Inventory: e.inventory.HostVars(host),
+		vars.PlayVars:  run.play.Vars,
+		vars.TaskVars:  task.Vars,
+		vars.Facts:     e.hostFacts(host),
+		vars.ExtraVars: e.extraVars,
+		vars.Magic:     e.magicVars(run, host),
+	}
+
+	if task.Role != nil {
+		layers[vars.RoleDefaults] = task.Role.Defaults
+		layers[vars.RoleVars] = task.Role.Vars
+	}
+
+	return layers.Merge()
+}
+
+// magicVars returns the variables describing the host and play a task runs
+// in.
+func (e *Executor) magicVars(
+	run *playRun,
+	host string,
+) map[string]interface{} {
+	groups := make(map[string]interface{})
+	for _, name := range e.inventory.GroupNames() {
+		groups[name] = append([]string{}, e.inventory.GroupHosts(name)...)
+	}
+
+	short, _, _ := strings.Cut(host, ".")
+
+	return map[string]interface{}{
+		"inventory_hostname":       host,
+		"inventory_hostname_short": short,
+		"group_names":              e.inventory.HostGroups(host),
+		"groups":                   groups,
+		"ansible_play_hosts_all":   run.hosts,
+		"ansible_play_batch":       run.batch,
+		"ansible_play_name":        run.play.Name,
+	}
+}
+
+// hostFacts returns a copy of the variables set by modules on a host.
+func (e *Executor) hostFacts(
+	host string,
+) map[string]interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return maps.Clone(e.facts[host])
+}
+
+// setFacts records variables set by a module on a host. Their values are
+// marked unsafe: they were already rendered, or read from the host, and are
+// never rendered as templates again.
+func (e *Executor) setFacts(
+	host string,
+	facts map[string]interface{},
+) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	hostFacts, ok := e.facts[host]
+	if !ok {
+		hostFacts = make(map[string]interface{})
+		e.facts[host] = hostFacts
+	}
+
+	for k, v := range facts {
+		hostFacts[k] = ansible.Unsafe{Value: v}
+	}
+}
+
+// connectionVars returns a func rendering the ansible_* variables of
+// taskVars, which select and configure the connection to the host.
+func (e *Executor) connectionVars(
+	taskVars map[string]interface{},
+) func() (map[string]interface{}, error) {
+	return func() (map[string]interface{}, error) {
+		connVars := make(map[string]interface{})
+		for k, v := range taskVars {
+			if !strings.HasPrefix(k, "ansible_") {
+				continue
+			}
+
+			rendered, err := ansible.RenderValue(v, taskVars, e.renderer)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render %s: %w", k, err)
+			}
+			connVars[k] = rendered
+		}
+
+		return connVars, nil
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// expandHostPattern expands the ranges of a host pattern, such as
+// "web[01:20].example.com" or "db-[a:c]", into host names. A range takes the
+// form [start:end] or [start:end:stride]; numeric ranges keep the width of
+// a zero-padded start.
+func expandHostPattern(
+	pattern string,
+) ([]string, error) {
+	open := strings.Index(pattern, "[")
+	if open < 0 {
+		return []string{pattern}, nil
+	}
+
+	closing := strings.Index(pattern[open:], "]")
+	if closing < 0 {
+		return nil, fmt.Errorf("invalid host range %q: missing ']'", pattern)
+	}
+	closing += open
+
+	head := pattern[:open]
+	spec := pattern[open+1 : closing]
+
+	values, err := expandRange(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host range %q: %w", pattern, err)
+	}
+
+	tails, err := expandHostPattern(pattern[closing+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]string, 0, len(values)*len(tails))
+	for _, v := range values {
+		for _, tail := range tails {
+			hosts = append(hosts, head+v+tail)
+		}
+	}
+
+	return hosts, nil
+}
+
+// expandRange expands the start:end[:stride] specification of a range.
+func expandRange(
+	spec string,
+) ([]string, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, fmt.Errorf("expected [start:end] or [start:end:stride]")
+	}
+
+	start, end := parts[0], parts[1]
+	stride := 1
+	if len(parts) == 3 {
+		var err error
+		stride, err = strconv.Atoi(parts[2])
+		if err != nil || stride < 1 {
+			return nil, fmt.Errorf("invalid stride %q", parts[2])
+		}
+	}
+
+	if isAlpha(start) && isAlpha(end) {
+		if start > end {
+			return nil, fmt.Errorf("range start %q is after end %q", start, end)
+		}
+
+		var values []string
+		for c := int(start[0]); c <= int(end[0]); c += stride {
+			values = append(values, string(rune(c)))
+		}
+		return values, nil
+	}
+
+	if start == "" {
+		start = "0"
+	}
+
+	first, err := strconv.Atoi(start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range start %q", start)
+	}
+	last, err := strconv.Atoi(end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range end %q", end)
+	}
+	if first > last {
+		return nil, fmt.Errorf("range start %q is after end %q", start, end)
+	}
+
+	width := 0
+	if len(start) > 1 && start[0] == '0' {
+		width = len(start)
+	}
+
+	var values []string
+	for n := first; n <= last; n += stride {
+		values = append(values, fmt.Sprintf("%0*d", width, n))
+	}
+
+	return values, nil
+}
+
+// isAlpha reports whether s is a single ASCII letter.
+func isAlpha(
+	s string,
+) bool {
+	return len(s) == 1 && (s[0] >= 'a' && s[0] <= 'z' || s[0] >= 'A' && s[0] <= 'Z')
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExpandHostsTestSuite struct {
+	suite.Suite
+}
+
+func (s *ExpandHostsTestSuite) TestExpandHostPattern() {
+	tests := []struct {
+		name              string
+		pattern           string
+		expected          []string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:     "plain host",
+			pattern:  "web.example.com",
+			expected: []string{"web.example.com"},
+		},
+		{
+			name:     "zero padded numeric range",
+			pattern:  "web[08:11].example.com",
+			expected: []string{"web08.example.com", "web09.example.com", "web10.example.com", "web11.example.com"},
+		},
+		{
+			name:     "numeric range with stride",
+			pattern:  "db[1:7:3]",
+			expected: []string{"db1", "db4", "db7"},
+		},
+		{
+			name:     "alphabetic range",
+			pattern:  "cache-[a:c]",
+			expected: []string{"cache-a", "cache-b", "cache-c"},
+		},
+		{
+			name:     "multiple ranges",
+			pattern:  "rack[1:2]-node[a:b]",
+			expected: []string{"rack1-nodea", "rack1-nodeb", "rack2-nodea", "rack2-nodeb"},
+		},
+		{
+			name:     "empty start counts from zero",
+			pattern:  "n[:2]",
+			expected: []string{"n0", "n1", "n2"},
+		},
+		{
+			name:              "missing closing bracket",
+			pattern:           "web[01:20",
+			expectErr:         true,
+			expectErrContains: "missing ']'",
+		},
+		{
+			name:              "start after end",
+			pattern:           "web[5:1]",
+			expectErr:         true,
+			expectErrContains: `range start "5" is after end "1"`,
+		},
+		{
+			name:              "invalid stride",
+			pattern:           "web[1:5:0]",
+			expectErr:         true,
+			expectErrContains: `invalid stride "0"`,
+		},
+		{
+			name:              "not a range",
+			pattern:           "web[1]",
+			expectErr:         true,
+			expectErrContains: "expected [start:end] or [start:end:stride]",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			hosts, err := expandHostPattern(tc.pattern)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, hosts)
+		})
+	}
+}
+
+func TestExpandHostsTestSuite(t *testing.T) {
+	suite.Run(t, new(ExpandHostsTestSuite))
+}
@@ -0,0 +1,342 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory
+
+import (
+	"maps"
+	"slices"
+	"sort"
+)
+
+// Names of the groups every inventory has.
+const (
+	// AllGroup contains every host.
+	AllGroup = "all"
+	// UngroupedGroup contains the hosts that belong to no other group.
+	UngroupedGroup = "ungrouped"
+)
+
+// New creates an empty inventory holding the all and ungrouped groups.
+func New() *Inventory {
+	inv := &Inventory{
+		hosts:  make(map[string]*Host),
+		groups: make(map[string]*Group),
+	}
+	inv.AddGroup(AllGroup)
+	inv.AddChild(AllGroup, UngroupedGroup)
+
+	return inv
+}
+
+// Implicit returns the inventory used when none is given: a single
+// localhost managed through a local connection.
+func Implicit() *Inventory {
+	inv := New()
+	inv.AddHost("localhost", UngroupedGroup).Vars["ansible_connection"] = "local"
+
+	return inv
+}
+
+// AddGroup returns the named group, creating it when missing.
+func (i *Inventory) AddGroup(
+	name string,
+) *Group {
+	if g, ok := i.groups[name]; ok {
+		return g
+	}
+
+	g := &Group{
+		Name: name,
+		Vars: make(map[string]interface{}),
+	}
+	i.groups[name] = g
+
+	return g
+}
+
+// AddHost returns the named host, creating it when missing, and adds it to
+// group.
+func (i *Inventory) AddHost(
+	name string,
+	group string,
+) *Host {
+	h, ok := i.hosts[name]
+	if !ok {
+		h = &Host{
+			Name: name,
+			Vars: make(map[string]interface{}),
+		}
+		i.hosts[name] = h
+		i.hostOrder = append(i.hostOrder, name)
+	}
+
+	g := i.AddGroup(group)
+	if !slices.Contains(g.Hosts, name) {
+		g.Hosts = append(g.Hosts, name)
+	}
+	if !slices.Contains(h.Groups, group) {
+		h.Groups = append(h.Groups, group)
+	}
+
+	return h
+}
+
+// AddChild makes child a child group of parent, creating both when missing.
+func (i *Inventory) AddChild(
+	parent string,
+	child string,
+) {
+	p := i.AddGroup(parent)
+	c := i.AddGroup(child)
+
+	if !slices.Contains(p.Children, child) {
+		p.Children = append(p.Children, child)
+	}
+	if !slices.Contains(c.Parents, parent) {
+		c.Parents = append(c.Parents, parent)
+	}
+}
+
+// Host returns the named host.
+func (i *Inventory) Host(
+	name string,
+) (*Host, bool) {
+	h, ok := i.hosts[name]
+	return h, ok
+}
+
+// Group returns the named group.
+func (i *Inventory) Group(
+	name string,
+) (*Group, bool) {
+	g, ok := i.groups[name]
+	return g, ok
+}
+
+// HostNames returns every host name in definition order.
+func (i *Inventory) HostNames() []string {
+	return slices.Clone(i.hostOrder)
+}
+
+// GroupNames returns every group name in sorted order.
+func (i *Inventory) GroupNames() []string {
+	names := make([]string, 0, len(i.groups))
+	for name := range i.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// GroupHosts returns the hosts of a group and of its descendants, in
+// definition order.
+func (i *Inventory) GroupHosts(
+	name string,
+) []string {
+	members := make(map[string]struct{})
+	i.walkGroup(name, make(map[string]struct{}), func(g *Group) {
+		for _, h := range g.Hosts {
+			members[h] = struct{}{}
+		}
+	})
+
+	var hosts []string
+	for _, h := range i.hostOrder {
+		if _, ok := members[h]; ok {
+			hosts = append(hosts, h)
+		}
+	}
+
+	return hosts
+}
+
+// HostGroups returns every group the host belongs to, directly or through
+// child groups, excluding all. Groups are sorted by name.
+func (i *Inventory) HostGroups(
+	name string,
+) []string {
+	var groups []string
+	for _, g := range i.ancestors(name) {
+		if g.Name != AllGroup {
+			groups = append(groups, g.Name)
+		}
+	}
+	sort.Strings(groups)
+
+	return groups
+}
+
+// HostVars returns the inventory variables of a host. Variables of child
+// groups override those of their parents, all having the lowest precedence,
+// and host variables override group variables. Groups at the same depth are
+// applied by name. Variables from group_vars and host_vars override those
+// from the inventory sources, later vars directories overriding earlier
+// ones.
+func (i *Inventory) HostVars(
+	name string,
+) map[string]interface{} {
+	groups := i.ancestors(name)
+	depths := make(map[string]int, len(groups))
+	for _, g := range groups {
+		depths[g.Name] = i.depth(g.Name, make(map[string]struct{}))
+	}
+	sort.SliceStable(groups, func(a, b int) bool {
+		if depths[groups[a].Name] != depths[groups[b].Name] {
+			return depths[groups[a].Name] < depths[groups[b].Name]
+		}
+		return groups[a].Name < groups[b].Name
+	})
+
+	// inventory file vars come first, then group_vars/all from every vars
+	// directory, then the other group_vars, then host vars
+	merged := make(map[string]interface{})
+	for _, g := range groups {
+		maps.Copy(merged, g.Vars)
+	}
+	for _, vd := range i.varsDirs {
+		maps.Copy(merged, vd.groups[AllGroup])
+	}
+	for _, vd := range i.varsDirs {
+		for _, g := range groups {
+			if g.Name != AllGroup {
+				maps.Copy(merged, vd.groups[g.Name])
+			}
+		}
+	}
+	if h, ok := i.hosts[name]; ok {
+		maps.Copy(merged, h.Vars)
+	}
+	for _, vd := range i.varsDirs {
+		maps.Copy(merged, vd.hosts[name])
+	}
+
+	return merged
+}
+
+// reconcile makes every group without a parent a child of all and adds
+// hosts that belong to no group but all to ungrouped, as Ansible does after
+// parsing an inventory source.
+func (i *Inventory) reconcile() {
+	for _, name := range i.GroupNames() {
+		g := i.groups[name]
+		if name != AllGroup && len(g.Parents) == 0 {
+			i.AddChild(AllGroup, name)
+		}
+	}
+
+	for _, name := range i.hostOrder {
+		h := i.hosts[name]
+
+		grouped := false
+		for _, group := range h.Groups {
+			if group != AllGroup && group != UngroupedGroup {
+				grouped = true
+			}
+		}
+
+		if !grouped {
+			i.AddHost(name, UngroupedGroup)
+		}
+	}
+}
+
+// ancestors returns the groups a host belongs to, directly or through child
+// groups, including all.
+func (i *Inventory) ancestors(
+	hostName string,
+) []*Group {
+	h, ok := i.hosts[hostName]
+	if !ok {
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	var groups []*Group
+
+	var visit func(name string)
+	visit = func(name string) {
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+
+		g, ok := i.groups[name]
+		if !ok {
+			return
+		}
+		groups = append(groups, g)
+		for _, parent := range g.Parents {
+			visit(parent)
+		}
+	}
+	for _, name := range h.Groups {
+		visit(name)
+	}
+	visit(AllGroup)
+
+	return groups
+}
+
+// depth returns the length of the longest path from all to a group.
+func (i *Inventory) depth(
+	name string,
+	visiting map[string]struct{},
+) int {
+	g, ok := i.groups[name]
+	if !ok || len(g.Parents) == 0 {
+		return 0
+	}
+	if _, ok := visiting[name]; ok {
+		return 0
+	}
+	visiting[name] = struct{}{}
+	defer delete(visiting, name)
+
+	deepest := 0
+	for _, parent := range g.Parents {
+		deepest = max(deepest, i.depth(parent, visiting)+1)
+	}
+
+	return deepest
+}
+
+// walkGroup calls fn for a group and each of its descendants once.
+func (i *Inventory) walkGroup(
+	name string,
+	seen map[string]struct{},
+	fn func(*Group),
+) {
+	if _, ok := seen[name]; ok {
+		return
+	}
+	seen[name] = struct{}{}
+
+	g, ok := i.groups[name]
+	if !ok {
+		return
+	}
+	fn(g)
+
+	for _, child := range g.Children {
+		i.walkGroup(child, seen, fn)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/inventory"
+)
+
+type InventoryPublicTestSuite struct {
+	suite.Suite
+}
+
+// newInventory builds:
+//
+//	all (level=all)
+//	└── prod (level=prod)
+//	    └── web (level=web, port=80)
+//	        └── web1 (port=8080)
+//	└── east (level=east, region=east)
+//	    └── web1
+func (s *InventoryPublicTestSuite) newInventory() *inventory.Inventory {
+	inv := inventory.New()
+
+	all, _ := inv.Group(inventory.AllGroup)
+	all.Vars["level"] = "all"
+
+	inv.AddChild("prod", "web")
+	inv.AddGroup("prod").Vars["level"] = "prod"
+	inv.AddGroup("web").Vars["level"] = "web"
+	inv.AddGroup("web").Vars["port"] = 80
+	inv.AddGroup("east").Vars["level"] = "east"
+	inv.AddGroup("east").Vars["region"] = "east"
+
+	inv.AddHost("web1", "web").Vars["port"] = 8080
+	inv.AddHost("web1", "east")
+	inv.AddHost("db1", "prod")
+	inv.AddChild(inventory.AllGroup, "prod")
+	inv.AddChild(inventory.AllGroup, "east")
+
+	return inv
+}
+
+func (s *InventoryPublicTestSuite) TestHostVars() {
+	inv := s.newInventory()
+
+	tests := []struct {
+		name     string
+		host     string
+		expected map[string]interface{}
+	}{
+		{
+			name: "deeper groups and host vars win",
+			host: "web1",
+			expected: map[string]interface{}{
+				"level":  "web",
+				"region": "east",
+				"port":   8080,
+			},
+		},
+		{
+			name: "parent group vars",
+			host: "db1",
+			expected: map[string]interface{}{
+				"level": "prod",
+			},
+		},
+		{
+			name:     "unknown host",
+			host:     "missing",
+			expected: map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, inv.HostVars(tc.host))
+		})
+	}
+}
+
+func (s *InventoryPublicTestSuite) TestHostGroups() {
+	inv := s.newInventory()
+
+	s.Equal([]string{"east", "prod", "web"}, inv.HostGroups("web1"))
+	s.Equal([]string{"prod"}, inv.HostGroups("db1"))
+	s.Nil(inv.HostGroups("missing"))
+}
+
+func (s *InventoryPublicTestSuite) TestGroupHosts() {
+	inv := s.newInventory()
+
+	s.Equal([]string{"web1", "db1"}, inv.GroupHosts(inventory.AllGroup))
+	s.Equal([]string{"web1", "db1"}, inv.GroupHosts("prod"))
+	s.Equal([]string{"web1"}, inv.GroupHosts("east"))
+	s.Nil(inv.GroupHosts("missing"))
+}
+
+func (s *InventoryPublicTestSuite) TestGroupCycle() {
+	inv := inventory.New()
+	inv.AddChild("a", "b")
+	inv.AddChild("b", "a")
+	inv.AddHost("h1", "b")
+	inv.AddGroup("a").Vars["from"] = "a"
+
+	s.Equal([]string{"h1"}, inv.GroupHosts("a"))
+	s.Equal(map[string]interface{}{"from": "a"}, inv.HostVars("h1"))
+}
+
+func (s *InventoryPublicTestSuite) TestImplicit() {
+	inv := inventory.Implicit()
+
+	s.Equal([]string{"localhost"}, inv.HostNames())
+	s.Equal([]string{"localhost"}, inv.GroupHosts(inventory.AllGroup))
+	s.Equal(
+		map[string]interface{}{"ansible_connection": "local"},
+		inv.HostVars("localhost"),
+	)
+
+	h, ok := inv.Host("localhost")
+	s.Require().True(ok)
+	s.Equal([]string{inventory.UngroupedGroup}, h.Groups)
+}
+
+func TestInventoryPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(InventoryPublicTestSuite))
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ignoredExtensions lists the file extensions skipped when loading an
+// inventory directory, following Ansible's inventory_ignore_extensions.
+var ignoredExtensions = []string{
+	"~", ".bak", ".swp", ".rpm", ".md", ".txt", ".rst", ".pyc", ".pyo",
+	".orig", ".ini", ".cfg", ".retry",
+}
+
+// Load builds an inventory from one or more sources. A source is an INI or
+// YAML file, an executable inventory script, a directory of such files, or a
+// comma separated host list such as "web01,web02,".
+func Load(
+	sources ...string,
+) (*Inventory, error) {
+	inv := New()
+
+	for _, source := range sources {
+		if err := inv.load(source); err != nil {
+			return nil, err
+		}
+	}
+	inv.reconcile()
+
+	return inv, nil
+}
+
+// load adds a single inventory source to the inventory.
+func (i *Inventory) load(
+	source string,
+) error {
+	info, err := os.Stat(source)
+	if errors.Is(err, fs.ErrNotExist) && strings.Contains(source, ",") {
+		for _, name := range strings.Split(source, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				i.AddHost(name, UngroupedGroup)
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	if info.IsDir() {
+		if err := i.loadDir(source); err != nil {
+			return err
+		}
+		return i.LoadVarsDir(source)
+	}
+
+	if err := i.loadFile(source); err != nil {
+		return err
+	}
+	return i.LoadVarsDir(filepath.Dir(source))
+}
+
+// loadDir loads every inventory file of a directory in name order, skipping
+// hidden files, ignored extensions and the group_vars and host_vars
+// directories.
+func (i *Inventory) loadDir(
+	dir string,
+) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read inventory directory: %w", err)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].Name() < entries[b].Name()
+	})
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || name == "group_vars" || name == "host_vars" {
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+		if isIgnored(name) {
+			continue
+		}
+
+		if err := i.loadFile(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadFile runs an executable file as an inventory script. Otherwise, or
+// when the executable turns out not to be a script, as a static inventory
+// with an exec bit set often is, it parses a YAML inventory when the file
+// has a YAML or JSON extension and an INI inventory otherwise.
+func (i *Inventory) loadFile(
+	path string,
+) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	var scriptErr error
+	if info.Mode().Perm()&0o111 != 0 {
+		isScript, err := i.loadScript(path)
+		if isScript {
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			return nil
+		}
+		scriptErr = err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml", ".json":
+		err = i.parseYAML(data)
+	default:
+		err = i.parseINI(data)
+	}
+	if err != nil && scriptErr != nil {
+		return fmt.Errorf("%s: %w (%v)", path, err, scriptErr)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+// isIgnored reports whether an inventory directory entry is skipped.
+func isIgnored(
+	name string,
+) bool {
+	for _, ext := range ignoredExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/inventory"
+)
+
+type LoadPublicTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+}
+
+func (s *LoadPublicTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-inventory-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+}
+
+func (s *LoadPublicTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func (s *LoadPublicTestSuite) TestLoad() {
+	tests := []struct {
+		name              string
+		files             map[string]string
+		sources           []string
+		expectHosts       []string
+		expectGroups      map[string][]string
+		expectVars        map[string]map[string]interface{}
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "INI inventory",
+			files: map[string]string{
+				"hosts": `
+# leading comment
+bastion.example.com ansible_port=2222
+
+[web]
+web[01:03].example.com http_port=80
+web04.example.com:8022 msg="hello world" enabled=True ratio=0.5
+
+[db]
+db1 ansible_host=10.0.0.5 # trailing comment
+
+[prod:children]
+web
+db
+
+[prod:vars]
+env = prod
+greeting = 'hi there'
+
+[web:vars]
+http_port=8080
+`,
+			},
+			sources: []string{"hosts"},
+			expectHosts: []string{
+				"bastion.example.com",
+				"web01.example.com",
+				"web02.example.com",
+				"web03.example.com",
+				"web04.example.com",
+				"db1",
+			},
+			expectGroups: map[string][]string{
+				"all": {
+					"bastion.example.com",
+					"web01.example.com",
+					"web02.example.com",
+					"web03.example.com",
+					"web04.example.com",
+					"db1",
+				},
+				"ungrouped": {"bastion.example.com"},
+				"web": {
+					"web01.example.com",
+					"web02.example.com",
+					"web03.example.com",
+					"web04.example.com",
+				},
+				"prod": {
+					"web01.example.com",
+					"web02.example.com",
+					"web03.example.com",
+					"web04.example.com",
+					"db1",
+				},
+			},
+			expectVars: map[string]map[string]interface{}{
+				"bastion.example.com": {"ansible_port": 2222},
+				"web01.example.com": {
+					"http_port": 80,
+					"env":       "prod",
+					"greeting":  "hi there",
+				},
+				"web04.example.com": {
+					"ansible_port": 8022,
+					"http_port":    8080,
+					"msg":          "hello world",
+					"enabled":      true,
+					"ratio":        0.5,
+					"env":          "prod",
+					"greeting":     "hi there",
+				},
+				"db1": {
+					"ansible_host": "10.0.0.5",
+					"env":          "prod",
+					"greeting":     "hi there",
+				},
+			},
+		},
+		{
+			name: "YAML inventory",
+			files: map[string]string{
+				"hosts.yml": `
+all:
+  vars:
+    env: prod
+  hosts:
+    bastion:
+      ansible_port: 2222
+  children:
+    web:
+      vars:
+        http_port: 8080
+      hosts:
+        web[1:2]:
+        web3:
+          http_port: 80
+    db:
+      hosts:
+        db1:
+      children:
+        replicas:
+          hosts:
+            db2:
+`,
+			},
+			sources:     []string{"hosts.yml"},
+			expectHosts: []string{"bastion", "web1", "web2", "web3", "db1", "db2"},
+			expectGroups: map[string][]string{
+				"all":       {"bastion", "web1", "web2", "web3", "db1", "db2"},
+				"ungrouped": {"bastion"},
+				"web":       {"web1", "web2", "web3"},
+				"db":        {"db1", "db2"},
+				"replicas":  {"db2"},
+			},
+			expectVars: map[string]map[string]interface{}{
+				"bastion": {"env": "prod", "ansible_port": 2222},
+				"web1":    {"env": "prod", "http_port": 8080},
+				"web3":    {"env": "prod", "http_port": 80},
+			},
+		},
+		{
+			name: "directory of inventories and host list",
+			files: map[string]string{
+				"inventory/01-web":               "[web]\nweb1\n",
+				"inventory/02-db.yaml":           "db:\n  hosts:\n    db1:\n",
+				"inventory/README.md":            "not an inventory",
+				"inventory/.hidden":              "[hidden]\nh1\n",
+				"inventory/group_vars/web.yml":   "http_port: 80\n",
+				"inventory/host_vars/web1.yml":   "id: 1\n",
+				"inventory/subdir/ignored-hosts": "[ignored]\ni1\n",
+			},
+			sources:     []string{"inventory", "extra1, extra2,"},
+			expectHosts: []string{"web1", "db1", "extra1", "extra2"},
+			expectGroups: map[string][]string{
+				"web":       {"web1"},
+				"db":        {"db1"},
+				"ungrouped": {"extra1", "extra2"},
+				"hidden":    nil,
+			},
+		},
+		{
+			name:              "missing inventory",
+			sources:           []string{"missing"},
+			expectErr:         true,
+			expectErrContains: "failed to read inventory",
+		},
+		{
+			name: "invalid INI section",
+			files: map[string]string{
+				"hosts": "[web:bogus]\nweb1\n",
+			},
+			sources:           []string{"hosts"},
+			expectErr:         true,
+			expectErrContains: `line 1: invalid section "[web:bogus]": unknown suffix "bogus"`,
+		},
+		{
+			name: "invalid INI host variable",
+			files: map[string]string{
+				"hosts": "[web]\nweb1 novalue\n",
+			},
+			sources:           []string{"hosts"},
+			expectErr:         true,
+			expectErrContains: `line 2: invalid host variable "novalue": expected key=value`,
+		},
+		{
+			name: "unterminated quote",
+			files: map[string]string{
+				"hosts": "web1 msg='oops\n",
+			},
+			sources:           []string{"hosts"},
+			expectErr:         true,
+			expectErrContains: "unterminated quote",
+		},
+		{
+			name: "invalid YAML group key",
+			files: map[string]string{
+				"hosts.yml": "web:\n  host:\n    web1:\n",
+			},
+			sources:           []string{"hosts.yml"},
+			expectErr:         true,
+			expectErrContains: `group "web": invalid key "host"`,
+		},
+		{
+			name: "YAML hosts as a list",
+			files: map[string]string{
+				"hosts.yml": "web:\n  hosts:\n    - web1\n",
+			},
+			sources:           []string{"hosts.yml"},
+			expectErr:         true,
+			expectErrContains: "hosts must be a mapping",
+		},
+		{
+			name: "invalid YAML host range",
+			files: map[string]string{
+				"hosts.yml": "web:\n  hosts:\n    web[3:1]:\n",
+			},
+			sources:           []string{"hosts.yml"},
+			expectErr:         true,
+			expectErrContains: `invalid host range "web[3:1]"`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			dir, err := os.MkdirTemp(s.tmpDir, "case-*")
+			s.Require().NoError(err)
+
+			for name, content := range tc.files {
+				path := filepath.Join(dir, name)
+				s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0o755))
+				s.Require().NoError(os.WriteFile(path, []byte(content), 0o644))
+			}
+
+			sources := make([]string, len(tc.sources))
+			for i, source := range tc.sources {
+				sources[i] = source
+				if _, err := os.Stat(filepath.Join(dir, source)); err == nil || source == "missing" {
+					sources[i] = filepath.Join(dir, source)
+				}
+			}
+
+			inv, err := inventory.Load(sources...)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expectHosts, inv.HostNames())
+			for group, hosts := range tc.expectGroups {
+				s.Equal(hosts, inv.GroupHosts(group), group)
+			}
+			for host, vars := range tc.expectVars {
+				s.Equal(vars, inv.HostVars(host), host)
+			}
+		})
+	}
+}
+
+func TestLoadPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(LoadPublicTestSuite))
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// errNotScript reports an executable file that is not an inventory script:
+// it cannot be run or its output is not JSON.
+var errNotScript = errors.New("not an inventory script")
+
+// loadScript runs an executable inventory script with --list and adds the
+// groups and hosts of its JSON output. Host variables come from
+// _meta.hostvars, or from running the script with --host for every host when
+// the output has no _meta. It reports false, leaving the inventory as it
+// was, when the file turns out not to be a script.
+func (i *Inventory) loadScript(
+	path string,
+) (bool, error) {
+	root, err := runScript(path, "--list")
+	switch {
+	case errors.Is(err, errNotScript):
+		return false, err
+	case err != nil:
+		return true, err
+	}
+
+	return true, i.addScriptOutput(path, root)
+}
+
+// addScriptOutput adds the groups, hosts and variables of the --list
+// output of an inventory script.
+func (i *Inventory) addScriptOutput(
+	path string,
+	root *yaml.Node,
+) error {
+	var (
+		meta  *yaml.Node
+		hosts []string
+	)
+	for k := 0; k < len(root.Content); k += 2 {
+		name, node := root.Content[k].Value, root.Content[k+1]
+		if name == "_meta" {
+			meta = node
+			continue
+		}
+
+		added, err := i.parseScriptGroup(name, node)
+		if err != nil {
+			return err
+		}
+		hosts = append(hosts, added...)
+	}
+
+	if meta != nil {
+		hostVars := mappingValue(meta, "hostvars")
+		if hostVars == nil {
+			return nil
+		}
+		if hostVars.Kind != yaml.MappingNode {
+			return fmt.Errorf("_meta.hostvars must be a mapping")
+		}
+
+		for k := 0; k < len(hostVars.Content); k += 2 {
+			h, ok := i.hosts[hostVars.Content[k].Value]
+			if !ok {
+				continue
+			}
+			if err := decodeVars(hostVars.Content[k+1], h.Vars); err != nil {
+				return fmt.Errorf("host %q: %w", h.Name, err)
+			}
+		}
+
+		return nil
+	}
+
+	for _, name := range hosts {
+		node, err := runScript(path, "--host", name)
+		if err != nil {
+			return err
+		}
+		if err := decodeVars(node, i.hosts[name].Vars); err != nil {
+			return fmt.Errorf("host %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// parseScriptGroup parses a group of inventory script output, given either
+// as a list of hosts or as a mapping with hosts, vars and children lists.
+// Other keys are ignored. It returns the hosts added to the group.
+func (i *Inventory) parseScriptGroup(
+	name string,
+	node *yaml.Node,
+) ([]string, error) {
+	i.AddGroup(name)
+	if isNull(node) {
+		return nil, nil
+	}
+
+	hostsNode := node
+	if node.Kind == yaml.MappingNode {
+		hostsNode = mappingValue(node, "hosts")
+
+		if err := decodeVars(mappingValue(node, "vars"), i.groups[name].Vars); err != nil {
+			return nil, fmt.Errorf("group %q: %w", name, err)
+		}
+
+		var children []string
+		if err := decodeList(mappingValue(node, "children"), &children); err != nil {
+			return nil, fmt.Errorf("group %q: children %w", name, err)
+		}
+		for _, child := range children {
+			i.AddChild(name, child)
+		}
+	}
+
+	var hosts []string
+	if err := decodeList(hostsNode, &hosts); err != nil {
+		return nil, fmt.Errorf("group %q: hosts %w", name, err)
+	}
+	for _, host := range hosts {
+		i.AddHost(host, name)
+	}
+
+	return hosts, nil
+}
+
+// runScript runs an inventory script and decodes its JSON object output.
+func runScript(
+	path string,
+	args ...string,
+) (*yaml.Node, error) {
+	// a relative path would be looked up in $PATH
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve inventory script %s: %w", path, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(abs, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			// the file could not be executed at all
+			err = fmt.Errorf("%w: %w", errNotScript, err)
+		}
+		return nil, fmt.Errorf(
+			"failed to run inventory script %s: %w: %s",
+			strings.Join(cmd.Args, " "),
+			err,
+			strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	if !json.Valid(stdout.Bytes()) {
+		return nil, fmt.Errorf(
+			"%w: output of %s is not JSON",
+			errNotScript,
+			strings.Join(cmd.Args, " "),
+		)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(stdout.Bytes(), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory script output: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("inventory script output must be a JSON object")
+	}
+
+	return doc.Content[0], nil
+}
+
+// mappingValue returns the value of a key of a mapping node, or nil when the
+// node is not a mapping or lacks the key.
+func mappingValue(
+	node *yaml.Node,
+	key string,
+) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for k := 0; k < len(node.Content); k += 2 {
+		if node.Content[k].Value == key {
+			return node.Content[k+1]
+		}
+	}
+
+	return nil
+}
+
+// decodeList decodes a list of strings into dst.
+func decodeList(
+	node *yaml.Node,
+	dst *[]string,
+) error {
+	if isNull(node) {
+		return nil
+	}
+
+	if err := node.Decode(dst); err != nil {
+		return fmt.Errorf("must be a list: %w", err)
+	}
+
+	return nil
+}
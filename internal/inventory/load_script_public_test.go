@@ -0,0 +1,240 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/inventory"
+)
+
+type LoadScriptPublicTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+}
+
+func (s *LoadScriptPublicTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-inventory-script-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+}
+
+func (s *LoadScriptPublicTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func (s *LoadScriptPublicTestSuite) TestLoadScript() {
+	tests := []struct {
+		name              string
+		script            string
+		expectHosts       []string
+		expectGroups      map[string][]string
+		expectVars        map[string]map[string]interface{}
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "list with _meta hostvars",
+			script: `#!/bin/sh
+[ "$1" = "--list" ] || exit 1
+cat <<'JSON'
+{
+  "web": {
+    "hosts": ["web2", "web1"],
+    "vars": {"http_port": 80},
+    "children": ["canary"]
+  },
+  "canary": ["web3"],
+  "db": {"hosts": ["db1"]},
+  "_meta": {
+    "hostvars": {
+      "web1": {"ansible_host": "10.0.0.1"},
+      "db1": {"ansible_port": 5432},
+      "unknown": {"ignored": true}
+    }
+  }
+}
+JSON
+`,
+			expectHosts: []string{"web2", "web1", "web3", "db1"},
+			expectGroups: map[string][]string{
+				"web":    {"web2", "web1", "web3"},
+				"canary": {"web3"},
+				"db":     {"db1"},
+			},
+			expectVars: map[string]map[string]interface{}{
+				"web1": {"http_port": 80, "ansible_host": "10.0.0.1"},
+				"web3": {"http_port": 80},
+				"db1":  {"ansible_port": 5432},
+			},
+		},
+		{
+			name: "host vars from --host",
+			script: `#!/bin/sh
+case "$1" in
+--list)
+  echo '{"web": ["web1", "web2"]}'
+  ;;
+--host)
+  echo "{\"name\": \"$2\"}"
+  ;;
+esac
+`,
+			expectHosts: []string{"web1", "web2"},
+			expectGroups: map[string][]string{
+				"all": {"web1", "web2"},
+			},
+			expectVars: map[string]map[string]interface{}{
+				"web1": {"name": "web1"},
+				"web2": {"name": "web2"},
+			},
+		},
+		{
+			name: "_meta without hostvars skips --host",
+			script: `#!/bin/sh
+[ "$1" = "--list" ] || exit 1
+echo '{"web": ["web1"], "_meta": {}}'
+`,
+			expectHosts: []string{"web1"},
+			expectVars: map[string]map[string]interface{}{
+				"web1": {},
+			},
+		},
+		{
+			name: "script fails",
+			script: `#!/bin/sh
+echo "no credentials" >&2
+exit 2
+`,
+			expectErr:         true,
+			expectErrContains: "--list: exit status 2: no credentials",
+		},
+		{
+			name: "output is not an object",
+			script: `#!/bin/sh
+echo '["web1"]'
+`,
+			expectErr:         true,
+			expectErrContains: "inventory script output must be a JSON object",
+		},
+		{
+			name: "invalid hosts",
+			script: `#!/bin/sh
+echo '{"web": {"hosts": {"web1": {}}}}'
+`,
+			expectErr:         true,
+			expectErrContains: `group "web": hosts must be a list`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			dir, err := os.MkdirTemp(s.tmpDir, "case-*")
+			s.Require().NoError(err)
+
+			path := filepath.Join(dir, "inventory.py")
+			s.Require().NoError(os.WriteFile(path, []byte(tc.script), 0o755))
+
+			inv, err := inventory.Load(path)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expectHosts, inv.HostNames())
+			for group, hosts := range tc.expectGroups {
+				s.Equal(hosts, inv.GroupHosts(group), group)
+			}
+			for host, vars := range tc.expectVars {
+				s.Equal(vars, inv.HostVars(host), host)
+			}
+		})
+	}
+}
+
+func (s *LoadScriptPublicTestSuite) TestLoadScriptInDirectory() {
+	script := "#!/bin/sh\necho '{\"db\": [\"db1\"]}'\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(s.tmpDir, "cloud"), []byte(script), 0o755))
+	s.Require().NoError(os.WriteFile(filepath.Join(s.tmpDir, "static"), []byte("[web]\nweb1\n"), 0o644))
+
+	inv, err := inventory.Load(s.tmpDir)
+	s.Require().NoError(err)
+
+	s.Equal([]string{"db1", "web1"}, inv.HostNames())
+	s.Equal([]string{"db1"}, inv.GroupHosts("db"))
+	s.Equal([]string{"web1"}, inv.GroupHosts("web"))
+}
+
+func (s *LoadScriptPublicTestSuite) TestLoadScriptRelativePath() {
+	script := "#!/bin/sh\necho '{\"web\": [\"web1\"]}'\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(s.tmpDir, "inv.sh"), []byte(script), 0o755))
+	s.T().Chdir(s.tmpDir)
+
+	inv, err := inventory.Load("inv.sh")
+	s.Require().NoError(err)
+
+	s.Equal([]string{"web1"}, inv.GroupHosts("web"))
+}
+
+func (s *LoadScriptPublicTestSuite) TestLoadExecutableStaticInventory() {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "ini",
+			file:    "hosts",
+			content: "[web]\nweb1\n",
+		},
+		{
+			name:    "yaml",
+			file:    "hosts.yml",
+			content: "web:\n  hosts:\n    web1:\n",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			path := filepath.Join(s.tmpDir, tc.file)
+			s.Require().NoError(os.WriteFile(path, []byte(tc.content), 0o755))
+
+			inv, err := inventory.Load(path)
+			s.Require().NoError(err)
+
+			s.Equal([]string{"web1"}, inv.GroupHosts("web"))
+		})
+	}
+}
+
+func TestLoadScriptPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(LoadScriptPublicTestSuite))
+}
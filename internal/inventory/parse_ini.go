@@ -0,0 +1,274 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// iniSection is the kind of section an INI inventory line belongs to.
+type iniSection int
+
+const (
+	iniHosts iniSection = iota
+	iniChildren
+	iniVars
+)
+
+// parseINI adds the groups and hosts of an Ansible INI inventory. Hosts
+// before the first section are ungrouped.
+func (i *Inventory) parseINI(
+	data []byte,
+) error {
+	group := UngroupedGroup
+	section := iniHosts
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		var err error
+		switch {
+		case strings.HasPrefix(line, "["):
+			group, section, err = parseINISection(line)
+			if err == nil {
+				i.AddGroup(group)
+			}
+		case section == iniChildren:
+			i.AddChild(group, line)
+		case section == iniVars:
+			err = i.parseINIGroupVar(group, line)
+		default:
+			err = i.parseINIHost(group, line)
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseINISection parses a [group], [group:children] or [group:vars]
+// header.
+func parseINISection(
+	line string,
+) (string, iniSection, error) {
+	if !strings.HasSuffix(line, "]") {
+		return "", 0, fmt.Errorf("invalid section %q", line)
+	}
+
+	name, kind, _ := strings.Cut(line[1:len(line)-1], ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", 0, fmt.Errorf("invalid section %q", line)
+	}
+
+	switch kind {
+	case "":
+		return name, iniHosts, nil
+	case "children":
+		return name, iniChildren, nil
+	case "vars":
+		return name, iniVars, nil
+	default:
+		return "", 0, fmt.Errorf("invalid section %q: unknown suffix %q", line, kind)
+	}
+}
+
+// parseINIHost parses a host line: a host pattern followed by key=value
+// variables.
+func (i *Inventory) parseINIHost(
+	group string,
+	line string,
+) error {
+	fields, err := splitFields(line)
+	if err != nil {
+		return err
+	}
+
+	pattern, port := splitPort(fields[0])
+	names, err := expandHostPattern(pattern)
+	if err != nil {
+		return err
+	}
+
+	hostVars := make(map[string]interface{})
+	if port != "" {
+		hostVars["ansible_port"] = parseINIValue(port)
+	}
+	for _, field := range fields[1:] {
+		k, v, ok := strings.Cut(field, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("invalid host variable %q: expected key=value", field)
+		}
+		hostVars[k] = parseINIValue(v)
+	}
+
+	for _, name := range names {
+		h := i.AddHost(name, group)
+		maps.Copy(h.Vars, hostVars)
+	}
+
+	return nil
+}
+
+// parseINIGroupVar parses a key=value line of a [group:vars] section.
+func (i *Inventory) parseINIGroupVar(
+	group string,
+	line string,
+) error {
+	k, v, ok := strings.Cut(line, "=")
+	k = strings.TrimSpace(k)
+	if !ok || k == "" {
+		return fmt.Errorf("invalid group variable %q: expected key=value", line)
+	}
+
+	i.AddGroup(group).Vars[k] = parseINIValue(strings.TrimSpace(v))
+
+	return nil
+}
+
+// splitFields splits a host line on whitespace outside of quotes and drops
+// a trailing comment.
+func splitFields(
+	line string,
+) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	var quote rune
+	inField := false
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			field.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inField = true
+			field.WriteRune(r)
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		case r == '#' && !inField:
+			return fields, nil
+		default:
+			inField = true
+			field.WriteRune(r)
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+
+	return fields, nil
+}
+
+// splitPort separates a trailing :port from a host pattern. Colons inside
+// ranges and IPv6 addresses are not ports.
+func splitPort(
+	pattern string,
+) (string, string) {
+	idx := strings.LastIndex(pattern, ":")
+	if idx < 0 {
+		return pattern, ""
+	}
+
+	host, port := pattern[:idx], pattern[idx+1:]
+	if _, err := strconv.Atoi(port); err != nil {
+		return pattern, ""
+	}
+	if strings.Count(host, "[") != strings.Count(host, "]") {
+		return pattern, ""
+	}
+	if strings.Contains(stripRanges(host), ":") {
+		return pattern, ""
+	}
+
+	return host, port
+}
+
+// stripRanges removes the [...] ranges of a host pattern.
+func stripRanges(
+	pattern string,
+) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range pattern {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// parseINIValue converts an INI value the way Ansible does: quoted values
+// stay strings, while integers, floats and the Python booleans True and
+// False are converted.
+func parseINIValue(
+	value string,
+) interface{} {
+	if len(value) >= 2 {
+		if q := value[0]; (q == '"' || q == '\'') && value[len(value)-1] == q {
+			return value[1 : len(value)-1]
+		}
+	}
+
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+
+	switch value {
+	case "True":
+		return true
+	case "False":
+		return false
+	}
+
+	return value
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory
+
+import (
+	"fmt"
+	"maps"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAML adds the groups and hosts of an Ansible YAML inventory. The
+// document is decoded node by node to keep hosts in definition order.
+func (i *Inventory) parseYAML(
+	data []byte,
+) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse inventory YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("inventory must be a mapping of groups")
+	}
+
+	for k := 0; k < len(root.Content); k += 2 {
+		if err := i.parseYAMLGroup(root.Content[k].Value, root.Content[k+1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseYAMLGroup parses the hosts, vars and children of a group.
+func (i *Inventory) parseYAMLGroup(
+	name string,
+	node *yaml.Node,
+) error {
+	i.AddGroup(name)
+	if isNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("group %q must be a mapping", name)
+	}
+
+	for k := 0; k < len(node.Content); k += 2 {
+		key, value := node.Content[k].Value, node.Content[k+1]
+
+		var err error
+		switch key {
+		case "hosts":
+			err = i.parseYAMLHosts(name, value)
+		case "vars":
+			err = decodeVars(value, i.groups[name].Vars)
+		case "children":
+			err = i.parseYAMLChildren(name, value)
+		default:
+			err = fmt.Errorf("invalid key %q", key)
+		}
+		if err != nil {
+			return fmt.Errorf("group %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// parseYAMLHosts parses the host patterns of a group and their variables.
+func (i *Inventory) parseYAMLHosts(
+	group string,
+	node *yaml.Node,
+) error {
+	if isNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("hosts must be a mapping")
+	}
+
+	for k := 0; k < len(node.Content); k += 2 {
+		pattern, value := node.Content[k].Value, node.Content[k+1]
+
+		hostVars := make(map[string]interface{})
+		if err := decodeVars(value, hostVars); err != nil {
+			return fmt.Errorf("host %q: %w", pattern, err)
+		}
+
+		pattern, port := splitPort(pattern)
+		if port != "" {
+			hostVars["ansible_port"] = parseINIValue(port)
+		}
+
+		names, err := expandHostPattern(pattern)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			h := i.AddHost(name, group)
+			maps.Copy(h.Vars, hostVars)
+		}
+	}
+
+	return nil
+}
+
+// parseYAMLChildren parses the child groups of a group.
+func (i *Inventory) parseYAMLChildren(
+	parent string,
+	node *yaml.Node,
+) error {
+	if isNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("children must be a mapping")
+	}
+
+	for k := 0; k < len(node.Content); k += 2 {
+		child := node.Content[k].Value
+		i.AddChild(parent, child)
+
+		if err := i.parseYAMLGroup(child, node.Content[k+1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeVars decodes a mapping of variables into dst.
+func decodeVars(
+	node *yaml.Node,
+	dst map[string]interface{},
+) error {
+	if isNull(node) {
+		return nil
+	}
+
+	var vars map[string]interface{}
+	if err := node.Decode(&vars); err != nil {
+		return fmt.Errorf("vars must be a mapping: %w", err)
+	}
+	maps.Copy(dst, vars)
+
+	return nil
+}
+
+// isNull reports whether a node is empty or an explicit null.
+func isNull(
+	node *yaml.Node,
+) bool {
+	return node == nil || node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
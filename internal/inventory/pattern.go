@@ -0,0 +1,339 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// localhostNames lists the names that resolve to the implicit localhost when
+// the inventory does not define them.
+var localhostNames = []string{"localhost", "127.0.0.1", "::1"}
+
+// patternSeparator matches the terms of a colon separated pattern, keeping
+// bracketed subscripts such as [0:2] whole.
+var patternSeparator = regexp.MustCompile(`(?:[^\s:\[\]]|\[[^\]]*\])+`)
+
+// subscriptPattern matches a term ending in an [n], [start:end] or [start:]
+// subscript, or its [start-end] or [start-] form.
+var subscriptPattern = regexp.MustCompile(`^(.+)\[(?:(-?[0-9]+)|([0-9]+)[:-]([0-9]*))\]$`)
+
+// ListHosts returns the hosts matching a host pattern, restricted to the
+// limit when one is set. A pattern is a comma or colon separated list of
+// terms. Each term is a group or host name, a glob, or a regular expression
+// prefixed with ~, optionally followed by an [n], [start:end] or
+// [start-end] subscript. Terms prefixed with & intersect and terms prefixed
+// with ! exclude.
+func (i *Inventory) ListHosts(
+	pattern string,
+) ([]string, error) {
+	hosts, err := i.evaluate(splitPattern(pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	if i.limit == nil {
+		return hosts, nil
+	}
+
+	limited, err := i.evaluate(i.limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(hosts, func(h string) bool {
+		return !slices.Contains(limited, h)
+	}), nil
+}
+
+// Limit restricts every later host list to the hosts matching pattern. A
+// pattern starting with @ names a file listing one pattern per line.
+func (i *Inventory) Limit(
+	pattern string,
+) error {
+	if file, ok := strings.CutPrefix(pattern, "@"); ok {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read limit file: %w", err)
+		}
+		pattern = strings.Join(strings.Fields(string(data)), ",")
+	}
+
+	terms := splitPattern(pattern)
+	if _, err := i.evaluate(terms); err != nil {
+		return err
+	}
+	i.limit = terms
+
+	return nil
+}
+
+// splitPattern splits a pattern into its terms. Commas take precedence over
+// colons, and a single IPv6 address is not split.
+func splitPattern(
+	pattern string,
+) []string {
+	pattern = strings.TrimSpace(pattern)
+
+	var terms []string
+	switch {
+	case strings.Contains(pattern, ","):
+		terms = strings.Split(pattern, ",")
+	case isIPv6(pattern):
+		terms = []string{pattern}
+	default:
+		terms = patternSeparator.FindAllString(pattern, -1)
+	}
+
+	var out []string
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			out = append(out, term)
+		}
+	}
+
+	return out
+}
+
+// evaluate combines the hosts of each term: plain terms first, in order,
+// then intersections and finally exclusions. Without plain terms the
+// selection starts from all.
+func (i *Inventory) evaluate(
+	terms []string,
+) ([]string, error) {
+	var plain, intersect, exclude []string
+	for _, term := range terms {
+		switch term[0] {
+		case '&':
+			intersect = append(intersect, term)
+		case '!':
+			exclude = append(exclude, term)
+		default:
+			plain = append(plain, term)
+		}
+	}
+	if len(plain) == 0 {
+		plain = []string{AllGroup}
+	}
+
+	var hosts []string
+	for _, term := range slices.Concat(plain, intersect, exclude) {
+		if _, ok := i.hosts[term]; ok {
+			if !slices.Contains(hosts, term) {
+				hosts = append(hosts, term)
+			}
+			continue
+		}
+
+		matched, err := i.matchTerm(strings.TrimLeft(term, "&!"))
+		if err != nil {
+			return nil, err
+		}
+
+		switch term[0] {
+		case '&':
+			hosts = slices.DeleteFunc(hosts, func(h string) bool {
+				return !slices.Contains(matched, h)
+			})
+		case '!':
+			hosts = slices.DeleteFunc(hosts, func(h string) bool {
+				return slices.Contains(matched, h)
+			})
+		default:
+			for _, h := range matched {
+				if !slices.Contains(hosts, h) {
+					hosts = append(hosts, h)
+				}
+			}
+		}
+	}
+
+	return hosts, nil
+}
+
+// matchTerm returns the hosts matching a single term and applies its
+// subscript.
+func (i *Inventory) matchTerm(
+	term string,
+) ([]string, error) {
+	term, subscript := splitSubscript(term)
+
+	hosts, err := i.enumerate(term)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscript == nil {
+		return hosts, nil
+	}
+
+	return subscript(hosts), nil
+}
+
+// enumerate returns the hosts of the groups matching term, and the hosts
+// matching it when no group does or when term is a glob or regular
+// expression. Unknown localhost names resolve to the implicit localhost.
+func (i *Inventory) enumerate(
+	term string,
+) ([]string, error) {
+	match, err := matcher(term)
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []string
+	add := func(names []string) {
+		for _, h := range names {
+			if !slices.Contains(hosts, h) {
+				hosts = append(hosts, h)
+			}
+		}
+	}
+
+	matchedGroup := false
+	for _, name := range i.GroupNames() {
+		if match(name) {
+			matchedGroup = true
+			add(i.GroupHosts(name))
+		}
+	}
+
+	if !matchedGroup || term[0] == '~' || strings.ContainsAny(term, ".?*[") {
+		var named []string
+		for _, h := range i.hostOrder {
+			if match(h) {
+				named = append(named, h)
+			}
+		}
+		add(named)
+	}
+
+	if len(hosts) == 0 && slices.Contains(localhostNames, term) {
+		i.implicitLocalhost(term)
+		hosts = []string{term}
+	}
+
+	return hosts, nil
+}
+
+// implicitLocalhost registers a local host that belongs to no group, as
+// Ansible does for localhost when the inventory does not define it.
+func (i *Inventory) implicitLocalhost(
+	name string,
+) {
+	if _, ok := i.hosts[name]; ok {
+		return
+	}
+
+	i.hosts[name] = &Host{
+		Name: name,
+		Vars: map[string]interface{}{"ansible_connection": "local"},
+	}
+}
+
+// matcher returns a function reporting whether a name matches term: a
+// regular expression anchored at the start when term begins with ~, and a
+// shell glob otherwise.
+func matcher(
+	term string,
+) (func(string) bool, error) {
+	if expr, ok := strings.CutPrefix(term, "~"); ok {
+		re, err := regexp.Compile("^(?:" + expr + ")")
+		if err != nil {
+			return nil, fmt.Errorf("invalid host pattern %q: %w", term, err)
+		}
+		return re.MatchString, nil
+	}
+
+	if _, err := path.Match(term, ""); err != nil {
+		return nil, fmt.Errorf("invalid host pattern %q: %w", term, err)
+	}
+
+	return func(name string) bool {
+		ok, _ := path.Match(term, name)
+		return ok
+	}, nil
+}
+
+// splitSubscript separates an [n], [start:end] or [start:] subscript from
+// a term and returns a function applying it. Ranges are inclusive, may use
+// - in place of :, and negative indexes count from the end. Regular expressions take no
+// subscript.
+func splitSubscript(
+	term string,
+) (string, func([]string) []string) {
+	if strings.HasPrefix(term, "~") {
+		return term, nil
+	}
+
+	m := subscriptPattern.FindStringSubmatch(term)
+	if m == nil {
+		return term, nil
+	}
+
+	if m[2] != "" {
+		idx, _ := strconv.Atoi(m[2])
+		return m[1], func(hosts []string) []string {
+			pos := idx
+			if pos < 0 {
+				pos += len(hosts)
+			}
+			if pos < 0 || pos >= len(hosts) {
+				return nil
+			}
+			return []string{hosts[pos]}
+		}
+	}
+
+	start, _ := strconv.Atoi(m[3])
+	end := -1
+	if m[4] != "" {
+		end, _ = strconv.Atoi(m[4])
+	}
+
+	return m[1], func(hosts []string) []string {
+		last := end
+		if last < 0 || last >= len(hosts) {
+			last = len(hosts) - 1
+		}
+		if start > last {
+			return nil
+		}
+		return hosts[start : last+1]
+	}
+}
+
+// isIPv6 reports whether s looks like a bare IPv6 address.
+func isIPv6(
+	s string,
+) bool {
+	if strings.Count(s, ":") < 2 {
+		return false
+	}
+
+	return strings.Trim(strings.ToLower(s), "0123456789abcdef:") == ""
+}
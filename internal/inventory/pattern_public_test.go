@@ -0,0 +1,262 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/inventory"
+)
+
+type PatternPublicTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+}
+
+func (s *PatternPublicTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-pattern-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+}
+
+func (s *PatternPublicTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+// load returns a fresh copy of the test inventory.
+func (s *PatternPublicTestSuite) load() *inventory.Inventory {
+	path := filepath.Join(s.tmpDir, "hosts")
+	s.Require().NoError(os.WriteFile(path, []byte(`
+bastion
+
+[web]
+web1
+web2
+web3
+canary1
+
+[db]
+db1
+db2
+
+[prod:children]
+web
+db
+
+[staging]
+web3
+stage-db
+`), 0o644))
+
+	inv, err := inventory.Load(path)
+	s.Require().NoError(err)
+
+	return inv
+}
+
+func (s *PatternPublicTestSuite) TestListHosts() {
+	tests := []struct {
+		name              string
+		pattern           string
+		limit             string
+		expected          []string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:     "all",
+			pattern:  "all",
+			expected: []string{"bastion", "web1", "web2", "web3", "canary1", "db1", "db2", "stage-db"},
+		},
+		{
+			name:     "group",
+			pattern:  "db",
+			expected: []string{"db1", "db2"},
+		},
+		{
+			name:     "host",
+			pattern:  "web2",
+			expected: []string{"web2"},
+		},
+		{
+			name:     "union with colons",
+			pattern:  "db:web1",
+			expected: []string{"db1", "db2", "web1"},
+		},
+		{
+			name:     "union with commas",
+			pattern:  "web1, db, web1",
+			expected: []string{"web1", "db1", "db2"},
+		},
+		{
+			name:     "intersection",
+			pattern:  "web:&staging",
+			expected: []string{"web3"},
+		},
+		{
+			name:     "exclusion",
+			pattern:  "prod:!canary*:!db2",
+			expected: []string{"web1", "web2", "web3", "db1"},
+		},
+		{
+			name:     "exclusion only starts from all",
+			pattern:  "!prod",
+			expected: []string{"bastion", "stage-db"},
+		},
+		{
+			name:     "exclusions apply after unions regardless of order",
+			pattern:  "!web1:web",
+			expected: []string{"web2", "web3", "canary1"},
+		},
+		{
+			name:     "glob on hosts",
+			pattern:  "*db*",
+			expected: []string{"db1", "db2", "stage-db"},
+		},
+		{
+			name:     "glob on groups",
+			pattern:  "sta*",
+			expected: []string{"web3", "stage-db"},
+		},
+		{
+			name:     "regular expression",
+			pattern:  `~web\d+`,
+			expected: []string{"web1", "web2", "web3"},
+		},
+		{
+			name:     "inclusive slice",
+			pattern:  "web[0:2]",
+			expected: []string{"web1", "web2", "web3"},
+		},
+		{
+			name:     "inclusive slice with a dash",
+			pattern:  "web[0-1]",
+			expected: []string{"web1", "web2"},
+		},
+		{
+			name:     "open slice with a dash",
+			pattern:  "web[1-]",
+			expected: []string{"web2", "web3", "canary1"},
+		},
+		{
+			name:     "open slice",
+			pattern:  "web[2:]",
+			expected: []string{"web3", "canary1"},
+		},
+		{
+			name:     "index and negative index",
+			pattern:  "web[0]:web[-1]",
+			expected: []string{"web1", "canary1"},
+		},
+		{
+			name:     "index out of range",
+			pattern:  "db[5]",
+			expected: nil,
+		},
+		{
+			name:     "unknown pattern",
+			pattern:  "missing",
+			expected: nil,
+		},
+		{
+			name:     "implicit localhost",
+			pattern:  "localhost",
+			expected: []string{"localhost"},
+		},
+		{
+			name:     "limit",
+			pattern:  "web",
+			limit:    "canary*:web1",
+			expected: []string{"web1", "canary1"},
+		},
+		{
+			name:     "limit excluding hosts",
+			pattern:  "all",
+			limit:    "!prod",
+			expected: []string{"bastion", "stage-db"},
+		},
+		{
+			name:              "invalid regular expression",
+			pattern:           "~web(",
+			expectErr:         true,
+			expectErrContains: `invalid host pattern "~web("`,
+		},
+		{
+			name:              "invalid limit",
+			pattern:           "all",
+			limit:             "~(",
+			expectErr:         true,
+			expectErrContains: `invalid host pattern "~("`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			inv := s.load()
+
+			var err error
+			if tc.limit != "" {
+				err = inv.Limit(tc.limit)
+			}
+
+			var hosts []string
+			if err == nil {
+				hosts, err = inv.ListHosts(tc.pattern)
+			}
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, hosts)
+		})
+	}
+}
+
+func (s *PatternPublicTestSuite) TestLimitFile() {
+	inv := s.load()
+
+	path := filepath.Join(s.tmpDir, "retry")
+	s.Require().NoError(os.WriteFile(path, []byte("web2\ndb1\n"), 0o644))
+	s.Require().NoError(inv.Limit("@" + path))
+
+	hosts, err := inv.ListHosts("all")
+	s.Require().NoError(err)
+	s.Equal([]string{"web2", "db1"}, hosts)
+
+	err = inv.Limit("@" + filepath.Join(s.tmpDir, "missing"))
+	s.Error(err)
+	s.Contains(err.Error(), "failed to read limit file")
+}
+
+func TestPatternPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(PatternPublicTestSuite))
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory
+
+// Inventory holds the hosts and groups plays run against.
+type Inventory struct {
+	// hosts maps host names to hosts.
+	hosts map[string]*Host
+	// groups maps group names to groups.
+	groups map[string]*Group
+	// hostOrder lists host names in the order they were first defined.
+	hostOrder []string
+	// limit holds the patterns set by Limit, which every host list is
+	// restricted to.
+	limit []string
+	// varsDirs holds the group_vars and host_vars loaded by LoadVarsDir, in
+	// increasing order of precedence.
+	varsDirs []*varsDir
+}
+
+// Host is a single inventory host.
+type Host struct {
+	// Name is the inventory hostname (e.g., "web01.example.com").
+	Name string
+	// Vars holds the variables set on the host itself.
+	Vars map[string]interface{}
+	// Groups lists the groups the host is a direct member of.
+	Groups []string
+}
+
+// Group is a named set of hosts and child groups.
+type Group struct {
+	// Name is the name of the group (e.g., "webservers").
+	Name string
+	// Vars holds the variables set on the group.
+	Vars map[string]interface{}
+	// Hosts lists the hosts that are direct members of the group, in order.
+	Hosts []string
+	// Children lists the child groups, in order.
+	Children []string
+	// Parents lists the groups this group is a child of.
+	Parents []string
+}
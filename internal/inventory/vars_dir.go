@@ -0,0 +1,215 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// varsExtensions lists the extensions of group_vars and host_vars files.
+// Files without an extension are loaded too.
+var varsExtensions = []string{".yml", ".yaml", ".json"}
+
+// varsDir holds the group_vars and host_vars found below a directory.
+type varsDir struct {
+	// path is the directory holding group_vars and host_vars.
+	path string
+	// groups maps group names to their variables.
+	groups map[string]map[string]interface{}
+	// hosts maps host names to their variables.
+	hosts map[string]map[string]interface{}
+}
+
+// LoadVarsDir loads the group_vars and host_vars directories below dir.
+// Directories loaded later take precedence, so the inventory directories
+// are loaded before the playbook directory. Loading a directory twice has
+// no effect.
+func (i *Inventory) LoadVarsDir(
+	dir string,
+) error {
+	dir = filepath.Clean(dir)
+	for _, vd := range i.varsDirs {
+		if vd.path == dir {
+			return nil
+		}
+	}
+
+	groups, err := loadVarsTree(filepath.Join(dir, "group_vars"))
+	if err != nil {
+		return err
+	}
+
+	hosts, err := loadVarsTree(filepath.Join(dir, "host_vars"))
+	if err != nil {
+		return err
+	}
+
+	i.varsDirs = append(i.varsDirs, &varsDir{
+		path:   dir,
+		groups: groups,
+		hosts:  hosts,
+	})
+
+	return nil
+}
+
+// loadVarsTree loads every entry of a group_vars or host_vars directory,
+// keyed by the group or host it belongs to. An entry is a YAML or JSON file
+// named after the group or host, with or without an extension, or a
+// directory of such files. A missing directory holds no variables.
+func loadVarsTree(
+	dir string,
+) (map[string]map[string]interface{}, error) {
+	tree := make(map[string]map[string]interface{})
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tree, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	// files come before directories of the same name
+	sort.SliceStable(entries, func(a, b int) bool {
+		return !entries[a].IsDir() && entries[b].IsDir()
+	})
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || isIgnored(name) {
+			continue
+		}
+
+		key := name
+		if !entry.IsDir() {
+			key = varsFileKey(name)
+		}
+
+		vars, ok := tree[key]
+		if !ok {
+			vars = make(map[string]interface{})
+			tree[key] = vars
+		}
+
+		if err := loadVarsEntry(filepath.Join(dir, name), vars); err != nil {
+			return nil, err
+		}
+	}
+
+	return tree, nil
+}
+
+// loadVarsEntry merges a vars file, or every vars file below a directory in
+// name order, into dst.
+func loadVarsEntry(
+	path string,
+	dst map[string]interface{},
+) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if !info.IsDir() {
+		return loadVarsFile(path, dst)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || isIgnored(name) {
+			continue
+		}
+		if !entry.IsDir() && !isVarsExtension(filepath.Ext(name)) {
+			continue
+		}
+
+		if err := loadVarsEntry(filepath.Join(path, name), dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadVarsFile merges the YAML or JSON mapping of a file into dst.
+func loadVarsFile(
+	path string,
+	dst map[string]interface{},
+) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var vars map[string]interface{}
+	if err := yaml.Unmarshal(data, &vars); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	maps.Copy(dst, vars)
+
+	return nil
+}
+
+// varsFileKey returns the group or host name a vars file belongs to. Only
+// the vars extensions are stripped, so host_vars/web1.example.com belongs to
+// web1.example.com.
+func varsFileKey(
+	name string,
+) string {
+	ext := filepath.Ext(name)
+	if isVarsExtension(ext) {
+		return strings.TrimSuffix(name, ext)
+	}
+
+	return name
+}
+
+// isVarsExtension reports whether files with the extension hold variables.
+func isVarsExtension(
+	ext string,
+) bool {
+	if ext == "" {
+		return true
+	}
+
+	for _, valid := range varsExtensions {
+		if strings.EqualFold(ext, valid) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package inventory_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/inventory"
+)
+
+type VarsDirPublicTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+}
+
+func (s *VarsDirPublicTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-vars-dir-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+}
+
+func (s *VarsDirPublicTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func (s *VarsDirPublicTestSuite) TestLoadVarsDir() {
+	hosts := `
+[web]
+web1.example.com tier=host
+
+[db]
+db1
+
+[prod:children]
+web
+db
+`
+
+	tests := []struct {
+		name              string
+		files             map[string]string
+		expectVars        map[string]map[string]interface{}
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "group and host files",
+			files: map[string]string{
+				"inventory/group_vars/all.yml":                "tier: all\nenv: dev\n",
+				"inventory/group_vars/prod.yaml":              "tier: prod\nenv: prod\n",
+				"inventory/group_vars/web":                    "tier: web\n",
+				"inventory/host_vars/web1.example.com":        "tier: host_vars\n",
+				"inventory/host_vars/db1.json":                `{"port": 5432}`,
+				"inventory/host_vars/.hidden.yml":             "tier: hidden\n",
+				"inventory/group_vars/web.yml.bak":            "tier: backup\n",
+				"inventory/group_vars/unknown_group.yml":      "tier: unknown\n",
+				"inventory/host_vars/web1.example.com.orig":   "tier: orig\n",
+				"inventory/host_vars/unknown.example.com.yml": "tier: unknown\n",
+			},
+			expectVars: map[string]map[string]interface{}{
+				"web1.example.com": {"tier": "host_vars", "env": "prod"},
+				"db1":              {"tier": "prod", "env": "prod", "port": 5432},
+			},
+		},
+		{
+			name: "directory of files",
+			files: map[string]string{
+				"inventory/group_vars/web.yml":         "a: file\nb: file\nc: file\n",
+				"inventory/group_vars/web/10-main.yml": "b: dir\nc: dir\n",
+				"inventory/group_vars/web/20-over.yml": "c: over\n",
+				"inventory/group_vars/web/notes.txt":   "c: notes\n",
+				"inventory/group_vars/web/sub/x.yml":   "d: nested\n",
+			},
+			expectVars: map[string]map[string]interface{}{
+				"web1.example.com": {
+					"tier": "host",
+					"a":    "file",
+					"b":    "dir",
+					"c":    "over",
+					"d":    "nested",
+				},
+			},
+		},
+		{
+			name: "playbook directory overrides inventory directory",
+			files: map[string]string{
+				"inventory/group_vars/all.yml":    "all_inv: inv\nall_both: inv\nparent: inv\n",
+				"playbook/group_vars/all.yml":     "all_both: playbook\nparent: playbook\n",
+				"inventory/group_vars/prod.yml":   "parent: prod_inv\nchild: prod_inv\n",
+				"inventory/group_vars/web.yml":    "child: web_inv\n",
+				"playbook/group_vars/prod.yml":    "child: prod_playbook\n",
+				"inventory/host_vars/db1.yml":     "host: inv\n",
+				"playbook/host_vars/db1.yml":      "host: playbook\n",
+				"playbook/group_vars/db/main.yml": "db: playbook\n",
+			},
+			expectVars: map[string]map[string]interface{}{
+				"web1.example.com": {
+					"tier":     "host",
+					"all_inv":  "inv",
+					"all_both": "playbook",
+					"parent":   "prod_inv",
+					"child":    "prod_playbook",
+				},
+				"db1": {
+					"all_inv":  "inv",
+					"all_both": "playbook",
+					"parent":   "prod_inv",
+					"child":    "prod_playbook",
+					"host":     "playbook",
+					"db":       "playbook",
+				},
+			},
+		},
+		{
+			name: "invalid vars file",
+			files: map[string]string{
+				"playbook/group_vars/all.yml": "- not\n- a mapping\n",
+			},
+			expectErr:         true,
+			expectErrContains: "failed to parse",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			dir, err := os.MkdirTemp(s.tmpDir, "case-*")
+			s.Require().NoError(err)
+
+			tc.files["inventory/hosts"] = hosts
+			for name, content := range tc.files {
+				path := filepath.Join(dir, name)
+				s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0o755))
+				s.Require().NoError(os.WriteFile(path, []byte(content), 0o644))
+			}
+
+			inv, err := inventory.Load(filepath.Join(dir, "inventory", "hosts"))
+			s.Require().NoError(err)
+
+			err = inv.LoadVarsDir(filepath.Join(dir, "playbook"))
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			for host, vars := range tc.expectVars {
+				s.Equal(vars, inv.HostVars(host), host)
+			}
+		})
+	}
+}
+
+func (s *VarsDirPublicTestSuite) TestImplicitInventory() {
+	path := filepath.Join(s.tmpDir, "group_vars", "all.yml")
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0o755))
+	s.Require().NoError(os.WriteFile(path, []byte("env: local\n"), 0o644))
+
+	inv := inventory.Implicit()
+	s.Require().NoError(inv.LoadVarsDir(s.tmpDir))
+	s.Require().NoError(inv.LoadVarsDir(s.tmpDir + "/"))
+
+	s.Equal(
+		map[string]interface{}{"ansible_connection": "local", "env": "local"},
+		inv.HostVars("localhost"),
+	)
+}
+
+func TestVarsDirPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(VarsDirPublicTestSuite))
+}
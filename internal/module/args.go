@@ -0,0 +1,120 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/retr0h/voidspan/internal/ansible"
+)
+
+// stringArg returns a string argument. A missing argument is empty.
+func stringArg(
+	args map[string]interface{},
+	name string,
+) string {
+	v, ok := args[name]
+	if !ok || v == nil {
+		return ""
+	}
+
+	return fmt.Sprint(v)
+}
+
+// boolArg returns a boolean argument, accepting the forms Ansible does
+// (yes, no, on, off, ...). A missing argument is def.
+func boolArg(
+	args map[string]interface{},
+	name string,
+	def bool,
+) (bool, error) {
+	v, ok := args[name]
+	if !ok || v == nil {
+		return def, nil
+	}
+
+	b, ok := ansible.ParseBool(v)
+	if !ok {
+		return false, fmt.Errorf("%s must be a boolean, got %v", name, v)
+	}
+
+	return b, nil
+}
+
+// intArg returns an integer argument, accepting numbers and numeric
+// strings. A missing argument is zero.
+func intArg(
+	args map[string]interface{},
+	name string,
+) (int, error) {
+	switch v := args[name].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	default:
+		n, err := strconv.Atoi(strings.TrimSpace(fmt.Sprint(v)))
+		if err != nil {
+			return 0, fmt.Errorf("%s must be an integer, got %v", name, v)
+		}
+		return n, nil
+	}
+}
+
+// toList returns v as a list, wrapping a single value.
+func toList(
+	v interface{},
+) []interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return val
+	default:
+		return []interface{}{val}
+	}
+}
+
+// listArg returns a list of strings argument, accepting a list or a
+// comma-separated string. A missing argument is empty.
+func listArg(
+	args map[string]interface{},
+	name string,
+) []string {
+	if s, ok := args[name].(string); ok {
+		if s == "" {
+			return nil
+		}
+		return strings.Split(s, ",")
+	}
+
+	list := toList(args[name])
+	values := make([]string, 0, len(list))
+	for _, v := range list {
+		values = append(values, fmt.Sprint(v))
+	}
+
+	return values
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+)
+
+// assertModule checks conditionals, like ansible.builtin.assert.
+type assertModule struct{}
+
+// Run evaluates every conditional of that and fails at the first false one
+// with fail_msg (or msg). Otherwise it reports success_msg, unless quiet
+// is set.
+func (m *assertModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	that := toList(args["that"])
+	if len(that) == 0 {
+		return nil, fmt.Errorf("missing required argument: that")
+	}
+
+	quiet, err := boolArg(args, "quiet", false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cond := range that {
+		ok, err := ctx.Conditional(fmt.Sprint(cond))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			continue
+		}
+
+		msg := "Assertion failed"
+		for _, key := range []string{"fail_msg", "msg"} {
+			if v, set := args[key]; set {
+				msg = formatValue(v)
+				break
+			}
+		}
+
+		return &Result{
+			Failed: true,
+			Msg:    msg,
+			Data: map[string]interface{}{
+				"assertion":    cond,
+				"evaluated_to": false,
+			},
+		}, nil
+	}
+
+	msg := "All assertions passed"
+	if v, ok := args["success_msg"]; ok {
+		msg = formatValue(v)
+	}
+
+	result := &Result{Data: map[string]interface{}{"msg": msg}}
+	if !quiet {
+		result.Msg = msg
+	}
+
+	return result, nil
+}
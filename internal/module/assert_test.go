@@ -0,0 +1,163 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AssertTestSuite struct {
+	suite.Suite
+}
+
+func (s *AssertTestSuite) TestRun() {
+	// conditional treats "true" as true, "error" as an error and anything
+	// else as false
+	conditional := func(cond string) (bool, error) {
+		if cond == "error" {
+			return false, fmt.Errorf("boom")
+		}
+		return cond == "true", nil
+	}
+
+	tests := []struct {
+		name              string
+		args              map[string]interface{}
+		expected          *Result
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "single conditional passes",
+			args: map[string]interface{}{
+				"that": "true",
+			},
+			expected: &Result{
+				Msg:  "All assertions passed",
+				Data: map[string]interface{}{"msg": "All assertions passed"},
+			},
+		},
+		{
+			name: "success_msg is reported",
+			args: map[string]interface{}{
+				"that":        []interface{}{"true", "true"},
+				"success_msg": "all good",
+			},
+			expected: &Result{
+				Msg:  "all good",
+				Data: map[string]interface{}{"msg": "all good"},
+			},
+		},
+		{
+			name: "quiet hides the message",
+			args: map[string]interface{}{
+				"that":  "true",
+				"quiet": true,
+			},
+			expected: &Result{
+				Data: map[string]interface{}{"msg": "All assertions passed"},
+			},
+		},
+		{
+			name: "false conditional fails",
+			args: map[string]interface{}{
+				"that": []interface{}{"true", "false"},
+			},
+			expected: &Result{
+				Failed: true,
+				Msg:    "Assertion failed",
+				Data: map[string]interface{}{
+					"assertion":    "false",
+					"evaluated_to": false,
+				},
+			},
+		},
+		{
+			name: "fail_msg is reported",
+			args: map[string]interface{}{
+				"that":     "false",
+				"fail_msg": "not good",
+				"msg":      "ignored",
+			},
+			expected: &Result{
+				Failed: true,
+				Msg:    "not good",
+				Data: map[string]interface{}{
+					"assertion":    "false",
+					"evaluated_to": false,
+				},
+			},
+		},
+		{
+			name: "msg is an alias of fail_msg",
+			args: map[string]interface{}{
+				"that": "false",
+				"msg":  "not good",
+			},
+			expected: &Result{
+				Failed: true,
+				Msg:    "not good",
+				Data: map[string]interface{}{
+					"assertion":    "false",
+					"evaluated_to": false,
+				},
+			},
+		},
+		{
+			name:              "missing that",
+			args:              map[string]interface{}{},
+			expectErr:         true,
+			expectErrContains: "missing required argument: that",
+		},
+		{
+			name: "conditional error",
+			args: map[string]interface{}{
+				"that": "error",
+			},
+			expectErr:         true,
+			expectErrContains: "boom",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m := &assertModule{}
+
+			result, err := m.Run(&Context{Conditional: conditional}, tc.args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, result)
+		})
+	}
+}
+
+func TestAssertTestSuite(t *testing.T) {
+	suite.Run(t, new(AssertTestSuite))
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Defaults of the marker args of blockinfile.
+const (
+	defaultMarker      = "# {mark} ANSIBLE MANAGED BLOCK"
+	defaultMarkerBegin = "BEGIN"
+	defaultMarkerEnd   = "END"
+)
+
+// multilineFlag matches a leading inline flag group turning on multiline
+// mode, in which blockinfile matches insertafter and insertbefore against
+// the whole file rather than line by line.
+var multilineFlag = regexp.MustCompile(`^\(\?[a-zA-Z]*m[a-zA-Z]*\)`)
+
+// blockinfileModule manages a block of lines between marker lines, like
+// ansible.builtin.blockinfile.
+type blockinfileModule struct{}
+
+// Run replaces the lines between the begin and end markers of path, the
+// marker with {mark} replaced by marker_begin and marker_end, with block.
+// Without markers in the file the block goes after the last line matching
+// insertafter, or before the last line matching insertbefore, or at EOF or
+// BOF. With state absent, or an empty block, the markers and the lines
+// between them are removed.
+func (m *blockinfileModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	if args["insertbefore"] != nil && args["insertafter"] != nil {
+		return nil, fmt.Errorf("parameters are mutually exclusive: insertbefore|insertafter")
+	}
+
+	state := stringArg(args, "state")
+	switch state {
+	case "", "present", "absent":
+	default:
+		return nil, fmt.Errorf("value of state must be one of: absent, present, got: %s", state)
+	}
+	present := state != "absent"
+
+	prependNewline, err := boolArg(args, "prepend_newline", false)
+	if err != nil {
+		return nil, err
+	}
+	appendNewline, err := boolArg(args, "append_newline", false)
+	if err != nil {
+		return nil, err
+	}
+
+	block := stringArg(args, "block")
+	if block == "" {
+		block = stringArg(args, "content")
+	}
+	marker := stringArg(args, "marker")
+	if marker == "" {
+		marker = defaultMarker
+	}
+	markerBegin := stringArg(args, "marker_begin")
+	if markerBegin == "" {
+		markerBegin = defaultMarkerBegin
+	}
+	markerEnd := stringArg(args, "marker_end")
+	if markerEnd == "" {
+		markerEnd = defaultMarkerEnd
+	}
+
+	insertAfter := stringArg(args, "insertafter")
+	insertBefore := stringArg(args, "insertbefore")
+	if insertAfter == "" && insertBefore == "" {
+		insertAfter = "EOF"
+	}
+
+	var insertRe *regexp.Regexp
+	switch {
+	case insertAfter != "" && insertAfter != "EOF":
+		insertRe, err = compilePython(insertAfter)
+	case insertBefore != "" && insertBefore != "BOF":
+		insertRe, err = compilePython(insertBefore)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	openArgs := args
+	if !present {
+		openArgs = withCreate(args)
+	}
+	f, failed, err := openText(ctx, openArgs, "Path %s does not exist !")
+	if err != nil || failed != nil {
+		return failed, err
+	}
+	if !present && !f.info.exists {
+		return &Result{Msg: fmt.Sprintf("File %s not present", f.path)}, nil
+	}
+
+	original := string(f.content)
+	lines := splitLinesKeep(original)
+
+	marker0 := strings.ReplaceAll(marker, "{mark}", markerBegin) + "\n"
+	marker1 := strings.ReplaceAll(marker, "{mark}", markerEnd) + "\n"
+	var blockLines []string
+	if present && block != "" {
+		if !strings.HasSuffix(block, "\n") {
+			block += "\n"
+		}
+		blockLines = append([]string{marker0}, splitLinesKeep(block)...)
+		blockLines = append(blockLines, marker1)
+	}
+
+	n0, n1 := -1, -1
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r\n")
+		if line+"\n" == marker0 {
+			n0 = i
+		}
+		if line+"\n" == marker1 {
+			n1 = i
+		}
+	}
+
+	switch {
+	case n0 == -1 || n1 == -1:
+		n0 = insertPosition(lines, original, insertRe, insertAfter, insertBefore)
+	case n0 < n1:
+		lines = slices.Delete(lines, n0, n1+1)
+	default:
+		lines = slices.Delete(lines, n1, n0+1)
+		n0 = n1
+	}
+
+	// the block starts on a line of its own
+	if n0 > 0 && !strings.HasSuffix(lines[n0-1], "\n") {
+		lines[n0-1] += "\n"
+	}
+
+	if prependNewline && present && n0 != 0 && lines[n0-1] != "\n" {
+		lines = slices.Insert(lines, n0, "\n")
+		n0++
+	}
+
+	lines = slices.Insert(lines, n0, blockLines...)
+
+	if appendNewline && present {
+		after := n0 + len(blockLines)
+		if after < len(lines) && lines[after] != "\n" {
+			lines = slices.Insert(lines, after, "\n")
+		}
+	}
+
+	content := strings.Join(lines, "")
+	changed, msg := true, ""
+	switch {
+	case f.info.exists && content == original:
+		changed = false
+	case !f.info.exists:
+		msg = "File created"
+	case len(blockLines) == 0:
+		msg = "Block removed"
+	default:
+		msg = "Block inserted"
+	}
+
+	return f.save(ctx, []byte(content), changed, msg, args)
+}
+
+// insertPosition returns the line a block without markers in the file is
+// inserted at. The last line matching insertRe is used, or in multiline
+// mode its first match in the whole content. A pattern matching nothing
+// means EOF.
+func insertPosition(
+	lines []string,
+	content string,
+	insertRe *regexp.Regexp,
+	insertAfter string,
+	insertBefore string,
+) int {
+	switch {
+	case insertRe == nil && insertBefore != "":
+		// BOF
+		return 0
+	case insertRe == nil:
+		// EOF
+		return len(lines)
+	}
+
+	if multilineFlag.MatchString(insertRe.String()) {
+		match := insertRe.FindStringIndex(content)
+		switch {
+		case match == nil:
+			return len(lines)
+		case insertAfter != "":
+			return strings.Count(content[:match[1]], "\n")
+		default:
+			return strings.Count(content[:match[0]], "\n")
+		}
+	}
+
+	n := -1
+	for i, line := range lines {
+		if insertRe.MatchString(trimNewline(line)) {
+			n = i
+		}
+	}
+	switch {
+	case n == -1:
+		return len(lines)
+	case insertAfter != "":
+		return n + 1
+	default:
+		return n
+	}
+}
+
+// splitLinesKeep splits content at \n, \r\n and \r into lines that keep
+// their line break, as Python's splitlines(True) does.
+func splitLinesKeep(
+	content string,
+) []string {
+	var lines []string
+	for len(content) > 0 {
+		i := strings.IndexAny(content, "\r\n")
+		if i < 0 {
+			lines = append(lines, content)
+			break
+		}
+
+		end := i + 1
+		if content[i] == '\r' && end < len(content) && content[end] == '\n' {
+			end++
+		}
+		lines = append(lines, content[:end])
+		content = content[end:]
+	}
+
+	return lines
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BlockinfileTestSuite struct {
+	suite.Suite
+}
+
+func (s *BlockinfileTestSuite) TestRun() {
+	tests := []goldenCase{
+		{
+			name:          "insert_eof",
+			args:          map[string]interface{}{"block": "x\ny"},
+			expectChanged: true,
+			expectMsg:     "Block inserted",
+		},
+		{
+			name:          "replace_block",
+			args:          map[string]interface{}{"block": "new1\nnew2\n"},
+			expectChanged: true,
+			expectMsg:     "Block inserted",
+		},
+		{
+			name:          "reversed_markers",
+			args:          map[string]interface{}{"content": "new"},
+			expectChanged: true,
+			expectMsg:     "Block inserted",
+		},
+		{
+			name:          "absent",
+			args:          map[string]interface{}{"block": "new", "state": "absent"},
+			expectChanged: true,
+			expectMsg:     "Block removed",
+		},
+		{
+			name:          "empty_block",
+			args:          map[string]interface{}{"block": ""},
+			expectChanged: true,
+			expectMsg:     "Block removed",
+		},
+		{
+			name: "custom_marker",
+			args: map[string]interface{}{
+				"block":        "<p/>",
+				"marker":       "<!-- {mark} managed -->",
+				"marker_begin": "start",
+				"marker_end":   "stop",
+				"insertbefore": "^</html>",
+			},
+			expectChanged: true,
+			expectMsg:     "Block inserted",
+		},
+		{
+			name: "insertafter",
+			args: map[string]interface{}{
+				"block":       "m=3",
+				"insertafter": `^\[a\]`,
+			},
+			expectChanged: true,
+			expectMsg:     "Block inserted",
+		},
+		{
+			name: "insertafter_no_match",
+			args: map[string]interface{}{
+				"block":       "x",
+				"insertafter": "^zzz",
+			},
+			expectChanged: true,
+			expectMsg:     "Block inserted",
+		},
+		{
+			name: "insertbefore_bof",
+			args: map[string]interface{}{
+				"block":        "x",
+				"insertbefore": "BOF",
+			},
+			expectChanged: true,
+			expectMsg:     "Block inserted",
+		},
+		{
+			name:          "no_trailing_newline",
+			args:          map[string]interface{}{"block": "x"},
+			expectChanged: true,
+			expectMsg:     "Block inserted",
+		},
+		{
+			name: "newlines",
+			args: map[string]interface{}{
+				"block":           "x",
+				"insertafter":     "^a$",
+				"prepend_newline": true,
+				"append_newline":  true,
+			},
+			expectChanged: true,
+			expectMsg:     "Block inserted",
+		},
+		{
+			name: "multiline",
+			args: map[string]interface{}{
+				"block":       "x",
+				"insertafter": `(?m)^\[a\]\nk=1\n`,
+			},
+			expectChanged: true,
+			expectMsg:     "Block inserted",
+		},
+		{
+			name: "backup",
+			args: map[string]interface{}{
+				"block":  "new",
+				"backup": true,
+			},
+			expectChanged: true,
+			expectMsg:     "Block inserted",
+		},
+		{
+			name: "create",
+			args: map[string]interface{}{
+				"block":  "x",
+				"create": true,
+			},
+			expectChanged: true,
+			expectMsg:     "File created",
+		},
+		{
+			name:      "absent_missing_file",
+			args:      map[string]interface{}{"state": "absent"},
+			expectMsg: "not present",
+		},
+		{
+			name:         "missing_file",
+			args:         map[string]interface{}{"block": "x"},
+			expectFailed: true,
+			expectMsg:    "does not exist !",
+		},
+		{
+			name: "insertbefore_and_insertafter",
+			args: map[string]interface{}{
+				"block":        "x",
+				"insertafter":  "a",
+				"insertbefore": "b",
+			},
+			expectErr:         true,
+			expectErrContains: "parameters are mutually exclusive: insertbefore|insertafter",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			runGolden(&s.Suite, &blockinfileModule{}, "blockinfile", tc)
+		})
+	}
+}
+
+func (s *BlockinfileTestSuite) TestSplitLinesKeep() {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{name: "empty", content: "", expected: nil},
+		{name: "line feeds", content: "a\nb", expected: []string{"a\n", "b"}},
+		{name: "mixed breaks", content: "a\r\nb\rc\n", expected: []string{"a\r\n", "b\r", "c\n"}},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, splitLinesKeep(tc.content))
+		})
+	}
+}
+
+func TestBlockinfileTestSuite(t *testing.T) {
+	suite.Run(t, new(BlockinfileTestSuite))
+}
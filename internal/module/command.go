@@ -0,0 +1,269 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// tildePrefix matches a ~ or ~user prefix of a path.
+var tildePrefix = regexp.MustCompile(`^~[A-Za-z0-9._-]*`)
+
+// commandModule runs a command, like ansible.builtin.command, or a command
+// line through a shell, like ansible.builtin.shell.
+type commandModule struct {
+	// shell runs the command line with a shell, so that pipes, redirections
+	// and variables work. Otherwise the command line is split into
+	// arguments that reach the program as they are.
+	shell bool
+}
+
+// Run runs the free-form command (or cmd, or argv for command) in chdir,
+// unless the creates path exists or the removes path does not. It fails
+// when the command exits non-zero.
+func (m *commandModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	line := stringArg(args, "__value__")
+	if line == "" {
+		line = stringArg(args, "cmd")
+	}
+	argv := toList(args["argv"])
+
+	var cmd string
+	var display interface{}
+	switch {
+	case len(argv) > 0 && m.shell:
+		return nil, fmt.Errorf("unsupported parameter: argv")
+	case len(argv) > 0 && line != "":
+		return nil, fmt.Errorf("only command or argv can be given, not both")
+	case len(argv) > 0:
+		words := make([]string, 0, len(argv))
+		for _, arg := range argv {
+			words = append(words, fmt.Sprint(arg))
+		}
+		cmd, display = quoteArgs(words), words
+	case line == "":
+		return nil, fmt.Errorf("no command given")
+	case m.shell:
+		cmd, display = withExecutable(line, stringArg(args, "executable")), line
+	default:
+		words, err := connection.SplitArgs(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse command %q: %w", line, err)
+		}
+		cmd, display = quoteArgs(words), words
+	}
+
+	chdir := stringArg(args, "chdir")
+	if skipped, err := checkPaths(ctx, args, chdir); skipped != nil || err != nil {
+		return skipped, err
+	}
+
+	return execCommand(ctx, &connection.Command{Cmd: cmd, Dir: chdir}, display, args)
+}
+
+// checkPaths returns the result of a command that does not run because its
+// creates path exists or its removes path does not. Paths may be globs and
+// are relative to chdir. It returns nil when the command runs.
+func checkPaths(
+	ctx *Context,
+	args map[string]interface{},
+	chdir string,
+) (*Result, error) {
+	if creates := stringArg(args, "creates"); creates != "" {
+		exists, err := pathExists(ctx, creates, chdir)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return notRun(fmt.Sprintf("'%s' exists", creates)), nil
+		}
+	}
+
+	if removes := stringArg(args, "removes"); removes != "" {
+		exists, err := pathExists(ctx, removes, chdir)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return notRun(fmt.Sprintf("'%s' does not exist", removes)), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// notRun returns the result of a command skipped by creates or removes.
+func notRun(
+	reason string,
+) *Result {
+	return &Result{
+		Msg: "Did not run command since " + reason,
+		Data: map[string]interface{}{
+			"rc":           0,
+			"stdout":       "skipped, since " + reason,
+			"stdout_lines": []string{"skipped, since " + reason},
+			"stderr":       "",
+			"stderr_lines": []string{},
+		},
+	}
+}
+
+// pathExists reports whether anything on the host matches the glob
+// pattern, relative to dir.
+func pathExists(
+	ctx *Context,
+	pattern string,
+	dir string,
+) (bool, error) {
+	result, err := ctx.Conn.Exec(&connection.Command{
+		Cmd: fmt.Sprintf(
+			`for f in %s; do [ -e "$f" ] || [ -L "$f" ] && exit 0; done; exit 1`,
+			globQuote(pattern),
+		),
+		Dir: dir,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", pattern, err)
+	}
+
+	return result.RC == 0, nil
+}
+
+// execCommand runs cmd, feeding it the stdin argument, and returns rc,
+// stdout, stderr and their lines, along with cmd as display. It fails when
+// the command exits non-zero.
+func execCommand(
+	ctx *Context,
+	cmd *connection.Command,
+	display interface{},
+	args map[string]interface{},
+) (*Result, error) {
+	stripEmptyEnds, err := boolArg(args, "strip_empty_ends", true)
+	if err != nil {
+		return nil, err
+	}
+
+	if stdin, ok := args["stdin"]; ok && stdin != nil {
+		addNewline, err := boolArg(args, "stdin_add_newline", true)
+		if err != nil {
+			return nil, err
+		}
+
+		input := fmt.Sprint(stdin)
+		if addNewline && !strings.HasSuffix(input, "\n") {
+			input += "\n"
+		}
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	result, err := ctx.Conn.Exec(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr := string(result.Stdout), string(result.Stderr)
+	if stripEmptyEnds {
+		stdout = strings.TrimRight(stdout, "\r\n")
+		stderr = strings.TrimRight(stderr, "\r\n")
+	}
+
+	r := &Result{
+		Changed: true,
+		Data: map[string]interface{}{
+			"cmd":          display,
+			"rc":           result.RC,
+			"stdout":       stdout,
+			"stdout_lines": splitLines(stdout),
+			"stderr":       stderr,
+			"stderr_lines": splitLines(stderr),
+		},
+	}
+	if result.RC != 0 {
+		r.Failed = true
+		r.Msg = "non-zero return code"
+	}
+
+	return r, nil
+}
+
+// withExecutable returns the command line run by the shell executable, or
+// line itself when executable is empty.
+func withExecutable(
+	line string,
+	executable string,
+) string {
+	if executable == "" {
+		return line
+	}
+
+	return connection.ShellQuote(executable) + " -c " + connection.ShellQuote(line)
+}
+
+// quoteArgs joins words into a command line that passes each of them to
+// the program unchanged.
+func quoteArgs(
+	words []string,
+) string {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		quoted = append(quoted, connection.ShellQuote(word))
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// globQuote quotes pattern for a shell, leaving its *, ? and [...]
+// wildcards active and a leading ~ or ~user expanding to a home directory.
+func globQuote(
+	pattern string,
+) string {
+	var b strings.Builder
+	// the shell expands a tilde prefix only when none of it is quoted, up to
+	// an unquoted slash
+	prefix := tildePrefix.FindString(pattern)
+	b.WriteString(prefix)
+	for _, r := range pattern[len(prefix):] {
+		if !strings.ContainsRune("*?[]/", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// splitLines splits output into its lines, without line endings.
+func splitLines(
+	s string,
+) []string {
+	if s == "" {
+		return []string{}
+	}
+
+	return strings.Split(strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\r\n", "\n"), "\n")
+}
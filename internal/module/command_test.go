@@ -0,0 +1,319 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+type CommandTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+	ctx    *Context
+}
+
+func (s *CommandTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-command-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+	s.ctx = &Context{Conn: connection.NewLocal()}
+
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "present.txt"), nil, 0o644))
+}
+
+func (s *CommandTestSuite) TearDownTest() {
+	_ = s.ctx.Conn.Close()
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func (s *CommandTestSuite) TestRun() {
+	tests := []struct {
+		name              string
+		shell             bool
+		inTmpDir          bool
+		args              map[string]interface{}
+		expected          *Result
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "command passes arguments unchanged",
+			args: map[string]interface{}{
+				"__value__": `printf '%s|' "$HOME" 'a b'`,
+			},
+			expected: &Result{
+				Changed: true,
+				Data: map[string]interface{}{
+					"cmd":          []string{"printf", "%s|", "$HOME", "a b"},
+					"rc":           0,
+					"stdout":       "$HOME|a b|",
+					"stdout_lines": []string{"$HOME|a b|"},
+					"stderr":       "",
+					"stderr_lines": []string{},
+				},
+			},
+		},
+		{
+			name: "argv",
+			args: map[string]interface{}{
+				"argv": []interface{}{"echo", "one  two"},
+			},
+			expected: &Result{
+				Changed: true,
+				Data: map[string]interface{}{
+					"cmd":          []string{"echo", "one  two"},
+					"rc":           0,
+					"stdout":       "one  two",
+					"stdout_lines": []string{"one  two"},
+					"stderr":       "",
+					"stderr_lines": []string{},
+				},
+			},
+		},
+		{
+			name:     "shell runs pipes in chdir",
+			shell:    true,
+			inTmpDir: true,
+			args: map[string]interface{}{
+				"cmd": "ls | grep present; echo oops >&2",
+			},
+			expected: &Result{
+				Changed: true,
+				Data: map[string]interface{}{
+					"cmd":          "ls | grep present; echo oops >&2",
+					"rc":           0,
+					"stdout":       "present.txt",
+					"stdout_lines": []string{"present.txt"},
+					"stderr":       "oops",
+					"stderr_lines": []string{"oops"},
+				},
+			},
+		},
+		{
+			name:  "shell with executable",
+			shell: true,
+			args: map[string]interface{}{
+				"__value__":  "echo $0",
+				"executable": "/bin/sh",
+			},
+			expected: &Result{
+				Changed: true,
+				Data: map[string]interface{}{
+					"cmd":          "echo $0",
+					"rc":           0,
+					"stdout":       "/bin/sh",
+					"stdout_lines": []string{"/bin/sh"},
+					"stderr":       "",
+					"stderr_lines": []string{},
+				},
+			},
+		},
+		{
+			name:  "stdin and strip_empty_ends",
+			shell: true,
+			args: map[string]interface{}{
+				"__value__":        "cat; echo",
+				"stdin":            "line one\nline two",
+				"strip_empty_ends": false,
+			},
+			expected: &Result{
+				Changed: true,
+				Data: map[string]interface{}{
+					"cmd":          "cat; echo",
+					"rc":           0,
+					"stdout":       "line one\nline two\n\n",
+					"stdout_lines": []string{"line one", "line two", ""},
+					"stderr":       "",
+					"stderr_lines": []string{},
+				},
+			},
+		},
+		{
+			name:  "non-zero exit fails",
+			shell: true,
+			args: map[string]interface{}{
+				"__value__": "echo bad >&2; exit 3",
+			},
+			expected: &Result{
+				Changed: true,
+				Failed:  true,
+				Msg:     "non-zero return code",
+				Data: map[string]interface{}{
+					"cmd":          "echo bad >&2; exit 3",
+					"rc":           3,
+					"stdout":       "",
+					"stdout_lines": []string{},
+					"stderr":       "bad",
+					"stderr_lines": []string{"bad"},
+				},
+			},
+		},
+		{
+			name:     "creates glob that exists",
+			inTmpDir: true,
+			args: map[string]interface{}{
+				"__value__": "touch never",
+				"creates":   "pres*.txt",
+			},
+			expected: &Result{
+				Msg: "Did not run command since 'pres*.txt' exists",
+				Data: map[string]interface{}{
+					"rc":           0,
+					"stdout":       "skipped, since 'pres*.txt' exists",
+					"stdout_lines": []string{"skipped, since 'pres*.txt' exists"},
+					"stderr":       "",
+					"stderr_lines": []string{},
+				},
+			},
+		},
+		{
+			name:     "removes that does not exist",
+			inTmpDir: true,
+			args: map[string]interface{}{
+				"__value__": "rm missing.txt",
+				"removes":   "missing.txt",
+			},
+			expected: &Result{
+				Msg: "Did not run command since 'missing.txt' does not exist",
+				Data: map[string]interface{}{
+					"rc":           0,
+					"stdout":       "skipped, since 'missing.txt' does not exist",
+					"stdout_lines": []string{"skipped, since 'missing.txt' does not exist"},
+					"stderr":       "",
+					"stderr_lines": []string{},
+				},
+			},
+		},
+		{
+			name:              "missing command",
+			args:              map[string]interface{}{"chdir": "/tmp"},
+			expectErr:         true,
+			expectErrContains: "no command given",
+		},
+		{
+			name: "command and argv",
+			args: map[string]interface{}{
+				"__value__": "ls",
+				"argv":      []interface{}{"ls"},
+			},
+			expectErr:         true,
+			expectErrContains: "only command or argv can be given, not both",
+		},
+		{
+			name:  "argv with shell",
+			shell: true,
+			args: map[string]interface{}{
+				"argv": []interface{}{"ls"},
+			},
+			expectErr:         true,
+			expectErrContains: "unsupported parameter: argv",
+		},
+		{
+			name: "unterminated quote",
+			args: map[string]interface{}{
+				"__value__": "echo 'a",
+			},
+			expectErr:         true,
+			expectErrContains: "failed to parse command",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m := &commandModule{shell: tc.shell}
+
+			if tc.inTmpDir {
+				tc.args["chdir"] = s.tmpDir
+			}
+
+			result, err := m.Run(s.ctx, tc.args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, result)
+		})
+	}
+}
+
+func (s *CommandTestSuite) TestCreatesInHomeDir() {
+	s.T().Setenv("HOME", s.tmpDir)
+
+	m := &commandModule{}
+	result, err := m.Run(s.ctx, map[string]interface{}{
+		"__value__": "touch never",
+		"creates":   "~/pres*.txt",
+	})
+
+	s.Require().NoError(err)
+	s.False(result.Changed)
+	s.Equal("Did not run command since '~/pres*.txt' exists", result.Msg)
+}
+
+func (s *CommandTestSuite) TestGlobQuote() {
+	tests := []struct {
+		name     string
+		pattern  string
+		expected string
+	}{
+		{
+			name:     "wildcards stay active",
+			pattern:  "/tmp/a b/*.txt",
+			expected: `/\t\m\p/\a\ \b/*\.\t\x\t`,
+		},
+		{
+			name:     "home directory",
+			pattern:  "~/.done",
+			expected: `~/\.\d\o\n\e`,
+		},
+		{
+			name:     "home directory of a user",
+			pattern:  "~deploy/x",
+			expected: `~deploy/\x`,
+		},
+		{
+			name:     "tilde after the start",
+			pattern:  "a~",
+			expected: `\a\~`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, globQuote(tc.pattern))
+		})
+	}
+}
+
+func TestCommandTestSuite(t *testing.T) {
+	suite.Run(t, new(CommandTestSuite))
+}
@@ -0,0 +1,348 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// copyModule copies local files or content to the host, like
+// ansible.builtin.copy.
+type copyModule struct{}
+
+// Run writes content, or the local src found in the files directories of
+// the task's search paths, to dest. A src directory is copied recursively:
+// with a trailing slash its content goes into dest, otherwise the directory
+// itself does. Files are only written when their checksum differs.
+func (m *copyModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	dest := stringArg(args, "dest")
+	if dest == "" {
+		return nil, fmt.Errorf("missing required argument: dest")
+	}
+
+	content, hasContent := args["content"]
+	src := stringArg(args, "src")
+	switch {
+	case hasContent && src != "":
+		return nil, fmt.Errorf("src and content are mutually exclusive")
+	case !hasContent && src == "":
+		return nil, fmt.Errorf("src (or content) is required")
+	}
+
+	if hasContent {
+		info, err := statFile(ctx, dest)
+		if err != nil {
+			return nil, err
+		}
+		if info.isDir || strings.HasSuffix(dest, "/") {
+			return nil, fmt.Errorf("can not use content with a dir as dest")
+		}
+
+		return writeFile(ctx, []byte(formatValue(content)), dest, info, args)
+	}
+
+	local, err := findFile(ctx, "files", src)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := os.Stat(local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", local, err)
+	}
+	if st.IsDir() {
+		if !strings.HasSuffix(src, "/") {
+			dest = path.Join(dest, filepath.Base(local))
+		}
+		return m.copyDir(ctx, local, dest, args)
+	}
+
+	return copyFile(ctx, local, dest, args)
+}
+
+// copyDir copies the content of the local directory dir into dest.
+func (m *copyModule) copyDir(
+	ctx *Context,
+	dir string,
+	dest string,
+	args map[string]interface{},
+) (*Result, error) {
+	result := &Result{
+		Data: map[string]interface{}{"src": dir, "dest": dest},
+	}
+
+	err := filepath.WalkDir(dir, func(local string, d fs.DirEntry, err error) error {
+		if err != nil || result.Failed {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, local)
+		if err != nil {
+			return err
+		}
+		target := path.Join(dest, filepath.ToSlash(rel))
+
+		if d.IsDir() {
+			created, err := mkdirAll(ctx, target)
+			result.Changed = result.Changed || created
+			return err
+		}
+
+		fileResult, err := copyFile(ctx, local, target, args)
+		if err != nil {
+			return err
+		}
+		result.Changed = result.Changed || fileResult.Changed
+		if fileResult.Failed {
+			result.Failed = true
+			result.Msg = fileResult.Msg
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// copyFile copies the local file to dest, or into dest when it is a
+// directory.
+func copyFile(
+	ctx *Context,
+	local string,
+	dest string,
+	args map[string]interface{},
+) (*Result, error) {
+	args, err := preserveMode(args, local)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(dest, "/") {
+		dest = path.Join(dest, filepath.Base(local))
+	}
+	info, err := statFile(ctx, dest)
+	if err != nil {
+		return nil, err
+	}
+	if info.isDir {
+		dest = path.Join(dest, filepath.Base(local))
+		if info, err = statFile(ctx, dest); err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := os.ReadFile(local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", local, err)
+	}
+
+	result, err := writeFile(ctx, content, dest, info, args)
+	if err != nil {
+		return nil, err
+	}
+	result.Data["src"] = local
+
+	return result, nil
+}
+
+// preserveMode returns args with a mode of "preserve" replaced by the mode
+// of the local file.
+func preserveMode(
+	args map[string]interface{},
+	local string,
+) (map[string]interface{}, error) {
+	if stringArg(args, "mode") != "preserve" {
+		return args, nil
+	}
+
+	st, err := os.Stat(local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", local, err)
+	}
+
+	args = maps.Clone(args)
+	args["mode"] = fmt.Sprintf("%04o", st.Mode().Perm())
+
+	return args, nil
+}
+
+// writeFile writes content to dest, described by info, unless dest already
+// has that content, or exists at all when force is off. The content is
+// validated with the validate command before it replaces dest, and dest is
+// backed up first when backup is on. Then the mode, owner and group args
+// are applied.
+func writeFile(
+	ctx *Context,
+	content []byte,
+	dest string,
+	info *remoteFile,
+	args map[string]interface{},
+) (*Result, error) {
+	force, err := boolArg(args, "force", true)
+	if err != nil {
+		return nil, err
+	}
+	backup, err := boolArg(args, "backup", false)
+	if err != nil {
+		return nil, err
+	}
+	validate := stringArg(args, "validate")
+	if validate != "" && !strings.Contains(validate, "%s") {
+		return nil, fmt.Errorf("validate must contain %%s: %s", validate)
+	}
+
+	checksum := fmt.Sprintf("%x", sha1.Sum(content))
+	result := &Result{
+		Data: map[string]interface{}{
+			"dest":     dest,
+			"checksum": checksum,
+			"size":     len(content),
+		},
+	}
+
+	if info.exists && !force {
+		return result, nil
+	}
+
+	if !info.exists || info.checksum != checksum {
+		mode := fs.FileMode(0o644)
+		if info.exists {
+			mode = info.mode
+		}
+
+		tmp := path.Join(path.Dir(dest), fmt.Sprintf(".voidspan.%d.%s", time.Now().UnixNano(), path.Base(dest)))
+		if err := ctx.Conn.Put(bytes.NewReader(content), tmp, mode); err != nil {
+			return nil, err
+		}
+
+		if validate != "" {
+			failed, err := validateFile(ctx, validate, tmp)
+			if err != nil || failed != nil {
+				_ = run(ctx, "rm -f -- "+connection.ShellQuote(tmp))
+				if failed != nil {
+					maps.Copy(failed.Data, result.Data)
+				}
+				return failed, err
+			}
+		}
+
+		if backup && info.exists {
+			name, err := backupFile(ctx, dest)
+			if err != nil {
+				return nil, err
+			}
+			result.Data["backup_file"] = name
+		}
+
+		if err := run(ctx, "mv -f -- "+connection.ShellQuote(tmp)+" "+connection.ShellQuote(dest)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", dest, err)
+		}
+		result.Changed = true
+
+		if info, err = statFile(ctx, dest); err != nil {
+			return nil, err
+		}
+	}
+
+	changed, err := setAttributes(ctx, dest, info, args)
+	if err != nil {
+		return nil, err
+	}
+	result.Changed = result.Changed || changed
+
+	return result, nil
+}
+
+// validateFile runs the validate command on path, substituted for %s, and
+// returns a failed result when it exits non-zero.
+func validateFile(
+	ctx *Context,
+	validate string,
+	path string,
+) (*Result, error) {
+	result, err := ctx.Conn.Exec(&connection.Command{
+		Cmd: strings.ReplaceAll(validate, "%s", connection.ShellQuote(path)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate: %w", err)
+	}
+	if result.RC == 0 {
+		return nil, nil
+	}
+
+	return &Result{
+		Failed: true,
+		Msg:    "failed to validate",
+		Data: map[string]interface{}{
+			"exit_status": result.RC,
+			"stdout":      strings.TrimRight(string(result.Stdout), "\r\n"),
+			"stderr":      strings.TrimRight(string(result.Stderr), "\r\n"),
+		},
+	}, nil
+}
+
+// backupFile copies path to a timestamped backup next to it, named the way
+// Ansible names backups, and returns the name of the copy.
+func backupFile(
+	ctx *Context,
+	path string,
+) (string, error) {
+	backup := fmt.Sprintf("%s.%d.%s~", path, os.Getpid(), time.Now().Format("2006-01-02@15:04:05"))
+	if err := run(ctx, "cp -p -- "+connection.ShellQuote(path)+" "+connection.ShellQuote(backup)); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	return backup, nil
+}
+
+// mkdirAll creates the directory path on the host with its parents and
+// reports whether it had to be created.
+func mkdirAll(
+	ctx *Context,
+	path string,
+) (bool, error) {
+	q := connection.ShellQuote(path)
+	result, err := ctx.Conn.Exec(&connection.Command{
+		Cmd: fmt.Sprintf("[ -d %[1]s ] && exit 0; mkdir -p -- %[1]s && echo created", q),
+	})
+	if err != nil || result.RC != 0 {
+		return false, fmt.Errorf("failed to create directory %s: %w", path, execError(result, err))
+	}
+
+	return strings.TrimSpace(string(result.Stdout)) == "created", nil
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+type CopyTestSuite struct {
+	suite.Suite
+
+	srcDir string
+}
+
+func (s *CopyTestSuite) SetupSuite() {
+	s.srcDir = s.T().TempDir()
+
+	files := filepath.Join(s.srcDir, "files")
+	s.Require().NoError(os.MkdirAll(filepath.Join(files, "conf.d", "extra"), 0o755))
+	s.Require().NoError(os.WriteFile(filepath.Join(files, "app.conf"), []byte("port=80\n"), 0o600))
+	s.Require().NoError(os.WriteFile(filepath.Join(files, "conf.d", "a.conf"), []byte("a\n"), 0o644))
+	s.Require().NoError(os.WriteFile(filepath.Join(files, "conf.d", "extra", "b.conf"), []byte("b\n"), 0o644))
+}
+
+func (s *CopyTestSuite) TestRun() {
+	tests := []struct {
+		name              string
+		existing          map[string]string
+		dest              string
+		args              map[string]interface{}
+		expectChanged     bool
+		expectFailed      bool
+		expectChecksum    string
+		expectFiles       map[string]string
+		expectModes       map[string]fs.FileMode
+		expectBackup      bool
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "content creates dest",
+			dest: "out.txt",
+			args: map[string]interface{}{
+				"content": "hello\n",
+				"mode":    "0640",
+			},
+			expectChanged:  true,
+			expectChecksum: "f572d396fae9206628714fb2ce00f72e94f2258f",
+			expectFiles:    map[string]string{"out.txt": "hello\n"},
+			expectModes:    map[string]fs.FileMode{"out.txt": 0o640},
+		},
+		{
+			name:     "same content is not written",
+			existing: map[string]string{"out.txt": "hello\n"},
+			dest:     "out.txt",
+			args: map[string]interface{}{
+				"content": "hello\n",
+				"backup":  true,
+			},
+			expectChecksum: "f572d396fae9206628714fb2ce00f72e94f2258f",
+			expectFiles:    map[string]string{"out.txt": "hello\n"},
+		},
+		{
+			name:     "only the mode changes",
+			existing: map[string]string{"out.txt": "hello\n"},
+			dest:     "out.txt",
+			args: map[string]interface{}{
+				"content": "hello\n",
+				"mode":    0o600,
+			},
+			expectChanged:  true,
+			expectChecksum: "f572d396fae9206628714fb2ce00f72e94f2258f",
+			expectModes:    map[string]fs.FileMode{"out.txt": 0o600},
+		},
+		{
+			name:     "different content is backed up and replaced",
+			existing: map[string]string{"out.txt": "old\n"},
+			dest:     "out.txt",
+			args: map[string]interface{}{
+				"content": "hello\n",
+				"backup":  "yes",
+			},
+			expectChanged:  true,
+			expectChecksum: "f572d396fae9206628714fb2ce00f72e94f2258f",
+			expectFiles:    map[string]string{"out.txt": "hello\n"},
+			expectModes:    map[string]fs.FileMode{"out.txt": 0o644},
+			expectBackup:   true,
+		},
+		{
+			name:     "force off keeps an existing dest",
+			existing: map[string]string{"out.txt": "old\n"},
+			dest:     "out.txt",
+			args: map[string]interface{}{
+				"content": "hello\n",
+				"force":   false,
+			},
+			expectChecksum: "f572d396fae9206628714fb2ce00f72e94f2258f",
+			expectFiles:    map[string]string{"out.txt": "old\n"},
+		},
+		{
+			name: "non-string content is written as JSON",
+			dest: "out.json",
+			args: map[string]interface{}{
+				"content": map[string]interface{}{"port": 80},
+			},
+			expectChanged:  true,
+			expectChecksum: "076adbe8ad0f5ddcb9623ff6cd28fbd33cfd6995",
+			expectFiles:    map[string]string{"out.json": `{"port":80}`},
+		},
+		{
+			name:     "failed validation keeps dest",
+			existing: map[string]string{"out.txt": "old\n"},
+			dest:     "out.txt",
+			args: map[string]interface{}{
+				"content":  "bad\n",
+				"validate": "grep -q good %s",
+			},
+			expectFailed:   true,
+			expectChecksum: "e9b396d2dddffdb373bf2c6ad073696aa25b4f68",
+			expectFiles:    map[string]string{"out.txt": "old\n"},
+		},
+		{
+			name: "passed validation",
+			dest: "out.txt",
+			args: map[string]interface{}{
+				"content":  "good\n",
+				"validate": "grep -q good %s",
+			},
+			expectChanged:  true,
+			expectChecksum: "1f8acd3265e5ba098dec495eece41c11ba093463",
+			expectFiles:    map[string]string{"out.txt": "good\n"},
+		},
+		{
+			name:     "src file into a directory with preserved mode",
+			existing: map[string]string{"etc/.keep": ""},
+			dest:     "etc",
+			args: map[string]interface{}{
+				"src":  "app.conf",
+				"mode": "preserve",
+			},
+			expectChanged:  true,
+			expectChecksum: "f6f646f13e035c2c329fc95c9e9f2e35749eb556",
+			expectFiles:    map[string]string{"etc/app.conf": "port=80\n"},
+			expectModes:    map[string]fs.FileMode{"etc/app.conf": 0o600},
+		},
+		{
+			name: "src directory is copied with its name",
+			dest: "etc",
+			args: map[string]interface{}{
+				"src": "conf.d",
+			},
+			expectChanged: true,
+			expectFiles: map[string]string{
+				"etc/conf.d/a.conf":       "a\n",
+				"etc/conf.d/extra/b.conf": "b\n",
+			},
+		},
+		{
+			name:     "src directory content with a trailing slash",
+			existing: map[string]string{"etc/a.conf": "a\n", "etc/extra/b.conf": "b\n"},
+			dest:     "etc",
+			args: map[string]interface{}{
+				"src": "conf.d/",
+			},
+			expectFiles: map[string]string{
+				"etc/a.conf":       "a\n",
+				"etc/extra/b.conf": "b\n",
+			},
+		},
+		{
+			name:     "content with a directory dest",
+			existing: map[string]string{"etc/.keep": ""},
+			dest:     "etc",
+			args: map[string]interface{}{
+				"content": "x",
+			},
+			expectErr:         true,
+			expectErrContains: "can not use content with a dir as dest",
+		},
+		{
+			name: "src and content",
+			dest: "out.txt",
+			args: map[string]interface{}{
+				"content": "x",
+				"src":     "app.conf",
+			},
+			expectErr:         true,
+			expectErrContains: "src and content are mutually exclusive",
+		},
+		{
+			name:              "neither src nor content",
+			dest:              "out.txt",
+			args:              map[string]interface{}{},
+			expectErr:         true,
+			expectErrContains: "src (or content) is required",
+		},
+		{
+			name: "validate without %s",
+			dest: "out.txt",
+			args: map[string]interface{}{
+				"content":  "x",
+				"validate": "true",
+			},
+			expectErr:         true,
+			expectErrContains: "validate must contain %s",
+		},
+		{
+			name: "invalid mode",
+			dest: "out.txt",
+			args: map[string]interface{}{
+				"content": "x",
+				"mode":    "rw",
+			},
+			expectErr:         true,
+			expectErrContains: `invalid mode "rw"`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			dir := s.T().TempDir()
+			for name, content := range tc.existing {
+				s.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+				s.Require().NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+			}
+
+			args := maps.Clone(tc.args)
+			args["dest"] = filepath.Join(dir, tc.dest)
+			ctx := &Context{
+				Conn:        connection.NewLocal(),
+				SearchPaths: []string{s.srcDir},
+			}
+
+			result, err := (&copyModule{}).Run(ctx, args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expectChanged, result.Changed)
+			s.Equal(tc.expectFailed, result.Failed, result.Msg)
+			if tc.expectChecksum != "" {
+				s.Equal(tc.expectChecksum, result.Data["checksum"])
+			}
+			for name, content := range tc.expectFiles {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				s.Require().NoError(err)
+				s.Equal(content, string(data), name)
+			}
+			for name, mode := range tc.expectModes {
+				st, err := os.Stat(filepath.Join(dir, name))
+				s.Require().NoError(err)
+				s.Equal(mode, st.Mode().Perm(), name)
+			}
+
+			backups, err := filepath.Glob(filepath.Join(dir, "*~"))
+			s.Require().NoError(err)
+			if tc.expectBackup {
+				s.Require().Len(backups, 1)
+				s.Equal(backups[0], result.Data["backup_file"])
+				data, err := os.ReadFile(backups[0])
+				s.Require().NoError(err)
+				s.Equal(tc.existing[tc.dest], string(data))
+			} else {
+				s.Empty(backups)
+			}
+
+			leftovers, err := filepath.Glob(filepath.Join(dir, ".voidspan*"))
+			s.Require().NoError(err)
+			s.Empty(leftovers)
+		})
+	}
+}
+
+func TestCopyTestSuite(t *testing.T) {
+	suite.Run(t, new(CopyTestSuite))
+}
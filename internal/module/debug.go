@@ -0,0 +1,117 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// undefinedVariable is what debug reports for a var that is not defined.
+const undefinedVariable = "VARIABLE IS NOT DEFINED!"
+
+// identifier matches a valid variable name.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// debugModule prints a message or a variable, like ansible.builtin.debug.
+type debugModule struct{}
+
+// Run returns the rendered msg argument, defaulting to "Hello world!", or
+// the value of the var argument. It skips when the run is less verbose
+// than the verbosity argument.
+func (m *debugModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	verbosity, err := intArg(args, "verbosity")
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Verbosity < verbosity {
+		return &Result{Skipped: true, Msg: "verbosity threshold not met"}, nil
+	}
+
+	expr, hasVar := args["var"]
+	if _, hasMsg := args["msg"]; hasMsg && hasVar {
+		return nil, fmt.Errorf("'msg' and 'var' are incompatible options")
+	}
+
+	if hasVar {
+		name := strings.TrimSpace(fmt.Sprint(expr))
+		value, err := m.value(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Result{
+			Msg:  name + ": " + formatValue(value),
+			Data: map[string]interface{}{name: value},
+		}, nil
+	}
+
+	msg := "Hello world!"
+	if v, ok := args["msg"]; ok {
+		msg = formatValue(v)
+	}
+
+	return &Result{Msg: msg}, nil
+}
+
+// value evaluates the var argument of debug.
+func (m *debugModule) value(
+	ctx *Context,
+	name string,
+) (interface{}, error) {
+	if identifier.MatchString(name) {
+		value, ok := ctx.Vars[name]
+		if !ok {
+			return undefinedVariable, nil
+		}
+		if s, isString := value.(string); !isString || !strings.Contains(s, "{{") {
+			return value, nil
+		}
+	}
+
+	if ctx.Evaluate == nil {
+		return nil, fmt.Errorf("cannot evaluate %q", name)
+	}
+
+	return ctx.Evaluate(name)
+}
+
+// formatValue formats a value for output: strings as they are, anything
+// else as JSON.
+func formatValue(
+	v interface{},
+) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(encoded)
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DebugTestSuite struct {
+	suite.Suite
+}
+
+func (s *DebugTestSuite) TestRun() {
+	tests := []struct {
+		name              string
+		ctx               *Context
+		args              map[string]interface{}
+		expected          *Result
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "msg is returned",
+			args: map[string]interface{}{
+				"msg": "hello",
+			},
+			expected: &Result{Msg: "hello"},
+		},
+		{
+			name: "non-string msg is formatted",
+			args: map[string]interface{}{
+				"msg": 42,
+			},
+			expected: &Result{Msg: "42"},
+		},
+		{
+			name:     "missing msg uses default",
+			args:     map[string]interface{}{},
+			expected: &Result{Msg: "Hello world!"},
+		},
+		{
+			name: "non-string msg is formatted as JSON",
+			args: map[string]interface{}{
+				"msg": map[string]interface{}{"a": []interface{}{1, 2}},
+			},
+			expected: &Result{Msg: `{"a":[1,2]}`},
+		},
+		{
+			name: "var returns the variable",
+			ctx: &Context{
+				Vars: map[string]interface{}{"port": 80},
+			},
+			args: map[string]interface{}{
+				"var": "port",
+			},
+			expected: &Result{
+				Msg:  "port: 80",
+				Data: map[string]interface{}{"port": 80},
+			},
+		},
+		{
+			name: "var evaluates expressions",
+			ctx: &Context{
+				Evaluate: func(expr string) (interface{}, error) {
+					return "evaluated " + expr, nil
+				},
+			},
+			args: map[string]interface{}{
+				"var": "result.stdout",
+			},
+			expected: &Result{
+				Msg:  "result.stdout: evaluated result.stdout",
+				Data: map[string]interface{}{"result.stdout": "evaluated result.stdout"},
+			},
+		},
+		{
+			name: "undefined var is reported",
+			args: map[string]interface{}{
+				"var": "missing",
+			},
+			expected: &Result{
+				Msg:  "missing: VARIABLE IS NOT DEFINED!",
+				Data: map[string]interface{}{"missing": "VARIABLE IS NOT DEFINED!"},
+			},
+		},
+		{
+			name: "var evaluation error",
+			ctx: &Context{
+				Evaluate: func(string) (interface{}, error) {
+					return nil, fmt.Errorf("boom")
+				},
+			},
+			args: map[string]interface{}{
+				"var": "a.b",
+			},
+			expectErr:         true,
+			expectErrContains: "boom",
+		},
+		{
+			name: "msg and var are incompatible",
+			args: map[string]interface{}{
+				"msg": "hello",
+				"var": "port",
+			},
+			expectErr:         true,
+			expectErrContains: "'msg' and 'var' are incompatible options",
+		},
+		{
+			name: "skips below verbosity",
+			ctx:  &Context{Verbosity: 1},
+			args: map[string]interface{}{
+				"msg":       "hello",
+				"verbosity": 2,
+			},
+			expected: &Result{Skipped: true, Msg: "verbosity threshold not met"},
+		},
+		{
+			name: "runs at verbosity",
+			ctx:  &Context{Verbosity: 2},
+			args: map[string]interface{}{
+				"msg":       "hello",
+				"verbosity": "2",
+			},
+			expected: &Result{Msg: "hello"},
+		},
+		{
+			name: "invalid verbosity",
+			args: map[string]interface{}{
+				"verbosity": "loud",
+			},
+			expectErr:         true,
+			expectErrContains: "verbosity must be an integer",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m := &debugModule{}
+
+			ctx := tc.ctx
+			if ctx == nil {
+				ctx = &Context{}
+			}
+
+			result, err := m.Run(ctx, tc.args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, result)
+		})
+	}
+}
+
+func TestDebugTestSuite(t *testing.T) {
+	suite.Run(t, new(DebugTestSuite))
+}
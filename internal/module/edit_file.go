@@ -0,0 +1,182 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// textFile is a file on the host edited in place by lineinfile,
+// blockinfile and replace.
+type textFile struct {
+	// path is the real path of the file, symlinks resolved, so that the
+	// file a link points to is edited rather than the link replaced.
+	path string
+	// info describes the file.
+	info *remoteFile
+	// content holds the content of an existing file.
+	content []byte
+}
+
+// openText reads the file at the path given by the path arg of an editing
+// module, or by one of its dest, destfile and name aliases. A missing file
+// has no content. It returns a failed result for a directory, and for a
+// missing file unless create is set, with missing as the message.
+func openText(
+	ctx *Context,
+	args map[string]interface{},
+	missing string,
+) (*textFile, *Result, error) {
+	var name string
+	for _, arg := range []string{"path", "dest", "destfile", "name"} {
+		if name = stringArg(args, arg); name != "" {
+			break
+		}
+	}
+	if name == "" {
+		return nil, nil, fmt.Errorf("missing required argument: path")
+	}
+
+	create, err := boolArg(args, "create", false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolved, err := ctx.Conn.Exec(&connection.Command{
+		Cmd: "readlink -f -- " + connection.ShellQuote(name),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+	f := &textFile{path: strings.TrimRight(string(resolved.Stdout), "\n")}
+	if resolved.RC != 0 || f.path == "" {
+		// the parent directory is missing as well
+		f.path = name
+	}
+
+	if f.info, err = statFile(ctx, f.path); err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case f.info.isDir:
+		return nil, &Result{
+			Failed: true,
+			Msg:    fmt.Sprintf("Path %s is a directory !", name),
+			Data:   map[string]interface{}{"rc": 256},
+		}, nil
+	case !f.info.exists && !create:
+		return nil, &Result{
+			Failed: true,
+			Msg:    fmt.Sprintf(missing, name),
+			Data:   map[string]interface{}{"rc": 257},
+		}, nil
+	case !f.info.exists:
+		return f, nil, nil
+	}
+
+	var content bytes.Buffer
+	if err := ctx.Conn.Fetch(f.path, &content); err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", f.path, err)
+	}
+	f.content = content.Bytes()
+
+	return f, nil, nil
+}
+
+// save writes content to the file when changed, backing the file up first
+// with backup and validating it with validate, then applies the mode,
+// owner and group args. As Ansible does, a change of attributes is added
+// to msg.
+func (f *textFile) save(
+	ctx *Context,
+	content []byte,
+	changed bool,
+	msg string,
+	args map[string]interface{},
+) (*Result, error) {
+	result := &Result{
+		Changed: changed,
+		Data:    map[string]interface{}{},
+	}
+
+	if changed {
+		if !f.info.exists {
+			if _, err := mkdirAll(ctx, path.Dir(f.path)); err != nil {
+				return nil, err
+			}
+		}
+
+		written, err := writeFile(ctx, content, f.path, f.info, map[string]interface{}{
+			"backup":   args["backup"],
+			"validate": args["validate"],
+		})
+		if err != nil || written.Failed {
+			return written, err
+		}
+		if name, ok := written.Data["backup_file"]; ok {
+			result.Data["backup_file"] = name
+		}
+
+		if f.info, err = statFile(ctx, f.path); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.info.exists {
+		attrs, err := setAttributes(ctx, f.path, f.info, args)
+		if err != nil {
+			return nil, err
+		}
+		if attrs {
+			if changed {
+				msg += " and "
+			}
+			msg += "ownership, perms or SE linux context changed"
+			result.Changed = true
+		}
+	}
+	result.Msg = msg
+
+	return result, nil
+}
+
+// splitKeep splits content into lines that keep their line break, as
+// Python's readlines does.
+func splitKeep(
+	content []byte,
+) []string {
+	if len(content) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
@@ -0,0 +1,316 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// goldenCase is a golden-file test of a module editing a file. The file
+// testdata/<module>/<name>.in, when it exists, is edited with args and must
+// then equal testdata/<module>/<name>.golden, or stay missing when there
+// is no golden file. Running the module again must change nothing.
+type goldenCase struct {
+	name              string
+	args              map[string]interface{}
+	expectChanged     bool
+	expectFailed      bool
+	expectMsg         string
+	expectData        map[string]interface{}
+	expectErr         bool
+	expectErrContains string
+}
+
+// runGolden runs the golden-file test tc of module m.
+func runGolden(
+	s *suite.Suite,
+	m Module,
+	module string,
+	tc goldenCase,
+) {
+	dir := s.T().TempDir()
+	path := filepath.Join(dir, "file")
+	base := filepath.Join("testdata", module, tc.name)
+
+	input, err := os.ReadFile(base + ".in")
+	if err == nil {
+		s.Require().NoError(os.WriteFile(path, input, 0o644))
+	} else {
+		s.Require().True(os.IsNotExist(err))
+	}
+
+	args := maps.Clone(tc.args)
+	args["path"] = path
+	ctx := &Context{Conn: connection.NewLocal()}
+
+	result, err := m.Run(ctx, args)
+
+	if tc.expectErr {
+		s.Require().Error(err)
+		s.Contains(err.Error(), tc.expectErrContains)
+		return
+	}
+
+	s.Require().NoError(err)
+	s.Equal(tc.expectChanged, result.Changed)
+	s.Equal(tc.expectFailed, result.Failed, result.Msg)
+	s.Contains(result.Msg, tc.expectMsg)
+	if tc.expectMsg == "" {
+		s.Empty(result.Msg)
+	}
+	for key, value := range tc.expectData {
+		s.Equal(value, result.Data[key], key)
+	}
+
+	golden, err := os.ReadFile(base + ".golden")
+	if os.IsNotExist(err) {
+		_, err := os.Stat(path)
+		s.True(os.IsNotExist(err), "file was created")
+		return
+	}
+	s.Require().NoError(err)
+
+	output, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal(string(golden), string(output))
+
+	backups, err := filepath.Glob(filepath.Join(dir, "file.*~"))
+	s.Require().NoError(err)
+	if backup, _ := boolArg(args, "backup", false); backup && tc.expectChanged {
+		s.Require().Len(backups, 1)
+		data, err := os.ReadFile(backups[0])
+		s.Require().NoError(err)
+		s.Equal(string(input), string(data))
+	} else {
+		s.Empty(backups)
+	}
+
+	if tc.expectFailed {
+		return
+	}
+
+	again, err := m.Run(ctx, args)
+	s.Require().NoError(err)
+	s.False(again.Changed, "second run changed the file: %s", again.Msg)
+	output, err = os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal(string(golden), string(output), "second run")
+}
+
+type EditFileTestSuite struct {
+	suite.Suite
+}
+
+func (s *EditFileTestSuite) TestOpenText() {
+	tests := []struct {
+		name              string
+		args              map[string]interface{}
+		expectPath        string
+		expectContent     string
+		expectExists      bool
+		expectFailedMsg   string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:          "file by path",
+			args:          map[string]interface{}{"path": "file"},
+			expectPath:    "file",
+			expectContent: "content\n",
+			expectExists:  true,
+		},
+		{
+			name:          "dest alias",
+			args:          map[string]interface{}{"dest": "file"},
+			expectPath:    "file",
+			expectContent: "content\n",
+			expectExists:  true,
+		},
+		{
+			name:          "symlinks are resolved",
+			args:          map[string]interface{}{"path": "link"},
+			expectPath:    "file",
+			expectContent: "content\n",
+			expectExists:  true,
+		},
+		{
+			name:       "missing file with create",
+			args:       map[string]interface{}{"path": "new/file", "create": true},
+			expectPath: "new/file",
+		},
+		{
+			name:            "missing file",
+			args:            map[string]interface{}{"path": "missing"},
+			expectFailedMsg: "missing does not exist !",
+		},
+		{
+			name:            "directory",
+			args:            map[string]interface{}{"path": "dir"},
+			expectFailedMsg: "dir is a directory !",
+		},
+		{
+			name:              "path is required",
+			args:              map[string]interface{}{},
+			expectErr:         true,
+			expectErrContains: "missing required argument: path",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			dir := s.T().TempDir()
+			s.Require().NoError(os.WriteFile(filepath.Join(dir, "file"), []byte("content\n"), 0o644))
+			s.Require().NoError(os.Symlink("file", filepath.Join(dir, "link")))
+			s.Require().NoError(os.Mkdir(filepath.Join(dir, "dir"), 0o755))
+
+			args := maps.Clone(tc.args)
+			for _, name := range []string{"path", "dest"} {
+				if v, ok := args[name].(string); ok {
+					args[name] = filepath.Join(dir, v)
+				}
+			}
+			ctx := &Context{Conn: connection.NewLocal()}
+
+			f, failed, err := openText(ctx, args, "Path %s does not exist !")
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			if tc.expectFailedMsg != "" {
+				s.Require().NotNil(failed)
+				s.True(failed.Failed)
+				s.Contains(failed.Msg, tc.expectFailedMsg)
+				return
+			}
+
+			s.Require().Nil(failed)
+			s.Equal(filepath.Join(dir, tc.expectPath), f.path)
+			s.Equal(tc.expectExists, f.info.exists)
+			s.Equal(tc.expectContent, string(f.content))
+		})
+	}
+}
+
+func (s *EditFileTestSuite) TestSave() {
+	tests := []struct {
+		name          string
+		content       string
+		changed       bool
+		args          map[string]interface{}
+		expectChanged bool
+		expectMsg     string
+		expectContent string
+	}{
+		{
+			name:          "changed content is written",
+			content:       "new\n",
+			changed:       true,
+			args:          map[string]interface{}{},
+			expectChanged: true,
+			expectMsg:     "line added",
+			expectContent: "new\n",
+		},
+		{
+			name:          "unchanged content is not written",
+			content:       "new\n",
+			args:          map[string]interface{}{},
+			expectContent: "old\n",
+		},
+		{
+			name:          "attributes alone",
+			content:       "old\n",
+			args:          map[string]interface{}{"mode": "0600"},
+			expectChanged: true,
+			expectMsg:     "ownership, perms or SE linux context changed",
+			expectContent: "old\n",
+		},
+		{
+			name:          "content and attributes",
+			content:       "new\n",
+			changed:       true,
+			args:          map[string]interface{}{"mode": "0600"},
+			expectChanged: true,
+			expectMsg:     "line added and ownership, perms or SE linux context changed",
+			expectContent: "new\n",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			path := filepath.Join(s.T().TempDir(), "file")
+			s.Require().NoError(os.WriteFile(path, []byte("old\n"), 0o644))
+			ctx := &Context{Conn: connection.NewLocal()}
+
+			args := maps.Clone(tc.args)
+			args["path"] = path
+			f, failed, err := openText(ctx, args, "Path %s does not exist !")
+			s.Require().NoError(err)
+			s.Require().Nil(failed)
+
+			msg := ""
+			if tc.changed {
+				msg = "line added"
+			}
+			result, err := f.save(ctx, []byte(tc.content), tc.changed, msg, args)
+
+			s.Require().NoError(err)
+			s.Equal(tc.expectChanged, result.Changed)
+			s.Equal(tc.expectMsg, result.Msg)
+			data, err := os.ReadFile(path)
+			s.Require().NoError(err)
+			s.Equal(tc.expectContent, string(data))
+		})
+	}
+}
+
+func (s *EditFileTestSuite) TestSplitKeep() {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{name: "empty", content: "", expected: nil},
+		{name: "trailing newline", content: "a\nb\n", expected: []string{"a\n", "b\n"}},
+		{name: "no trailing newline", content: "a\nb", expected: []string{"a\n", "b"}},
+		{name: "carriage returns stay", content: "a\r\nb\r", expected: []string{"a\r\n", "b\r"}},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, splitKeep([]byte(tc.content)))
+		})
+	}
+}
+
+func TestEditFileTestSuite(t *testing.T) {
+	suite.Run(t, new(EditFileTestSuite))
+}
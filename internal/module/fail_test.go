@@ -0,0 +1,67 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FailTestSuite struct {
+	suite.Suite
+}
+
+func (s *FailTestSuite) TestRun() {
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		expected *Result
+	}{
+		{
+			name: "msg is returned",
+			args: map[string]interface{}{
+				"msg": "stop",
+			},
+			expected: &Result{Failed: true, Msg: "stop"},
+		},
+		{
+			name:     "missing msg uses default",
+			args:     map[string]interface{}{},
+			expected: &Result{Failed: true, Msg: "Failed as requested from task"},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m := &failModule{}
+
+			result, err := m.Run(&Context{}, tc.args)
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, result)
+		})
+	}
+}
+
+func TestFailTestSuite(t *testing.T) {
+	suite.Run(t, new(FailTestSuite))
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// Path states, as the file module takes them and reports them.
+const (
+	stateAbsent    = "absent"
+	stateDirectory = "directory"
+	stateFile      = "file"
+	stateHard      = "hard"
+	stateLink      = "link"
+	stateTouch     = "touch"
+)
+
+// fileModule manages files, directories and links and their attributes,
+// like ansible.builtin.file.
+type fileModule struct{}
+
+// Run brings path to state, which defaults to directory for directories
+// and with recurse and to file otherwise, then applies the mode, owner and
+// group args, following links. Directories apply them to their content too
+// with recurse.
+func (m *fileModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	var target string
+	for _, name := range []string{"path", "dest", "name"} {
+		if target = stringArg(args, name); target != "" {
+			break
+		}
+	}
+	if target == "" {
+		return nil, fmt.Errorf("missing required argument: path")
+	}
+
+	prev, err := pathState(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	recurse, err := boolArg(args, "recurse", false)
+	if err != nil {
+		return nil, err
+	}
+
+	state := stringArg(args, "state")
+	switch {
+	case state != "":
+	case recurse || prev == stateDirectory:
+		state = stateDirectory
+	default:
+		state = stateFile
+	}
+	if recurse && state != stateDirectory {
+		return nil, fmt.Errorf("recurse option requires state to be 'directory'")
+	}
+	force, err := boolArg(args, "force", false)
+	if err != nil {
+		return nil, err
+	}
+
+	q := connection.ShellQuote(target)
+	result := &Result{
+		Data: map[string]interface{}{"path": target, "state": state},
+	}
+
+	switch state {
+	case stateAbsent:
+		if prev != stateAbsent {
+			if err := run(ctx, "rm -rf -- "+q); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", target, err)
+			}
+			result.Changed = true
+		}
+		return result, nil
+
+	case stateFile:
+		if prev != stateFile && prev != stateHard && prev != stateLink {
+			result.Failed = true
+			result.Msg = fmt.Sprintf("file (%s) is %s, cannot continue", target, prev)
+			return result, nil
+		}
+
+	case stateDirectory:
+		switch prev {
+		case stateAbsent:
+			if err := run(ctx, "mkdir -p -- "+q); err != nil {
+				return nil, fmt.Errorf("failed to create directory %s: %w", target, err)
+			}
+			result.Changed = true
+		case stateDirectory:
+		default:
+			info, err := statFile(ctx, target)
+			if err != nil {
+				return nil, err
+			}
+			if !info.isDir {
+				result.Failed = true
+				result.Msg = fmt.Sprintf("%s already exists as a %s", target, prev)
+				return result, nil
+			}
+		}
+
+	case stateTouch:
+		if err := run(ctx, "touch -- "+q); err != nil {
+			return nil, fmt.Errorf("failed to touch %s: %w", target, err)
+		}
+		result.Changed = true
+
+	case stateLink, stateHard:
+		src := stringArg(args, "src")
+		if src == "" {
+			return nil, fmt.Errorf("src is required for creating a %s", map[string]string{
+				stateLink: "symlink",
+				stateHard: "hard link",
+			}[state])
+		}
+		result.Data["src"] = src
+
+		changed, failure, err := m.link(ctx, state, src, target, prev, force)
+		if err != nil {
+			return nil, err
+		}
+		if failure != "" {
+			result.Failed = true
+			result.Msg = failure
+			return result, nil
+		}
+		result.Changed = changed
+
+	default:
+		return nil, fmt.Errorf(
+			"value of state must be one of: absent, directory, file, hard, link, touch, got: %s",
+			state,
+		)
+	}
+
+	info, err := statFile(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if !info.exists {
+		// a symlink to a missing target has no attributes to set
+		return result, nil
+	}
+
+	changed, err := setAttributes(ctx, target, info, args)
+	if err != nil {
+		return nil, err
+	}
+	result.Changed = result.Changed || changed
+
+	if recurse {
+		changed, err := setAttributesRecursive(ctx, target, args)
+		if err != nil {
+			return nil, err
+		}
+		result.Changed = result.Changed || changed
+	}
+
+	if info, err = statFile(ctx, target); err != nil {
+		return nil, err
+	}
+	result.Data["mode"] = fmt.Sprintf("%04o", info.mode)
+	result.Data["owner"] = info.owner
+	result.Data["group"] = info.group
+
+	return result, nil
+}
+
+// link makes path a symbolic or hard link to src, replacing what is there
+// only when force is set. It returns whether it changed anything, or why
+// it refused to.
+func (m *fileModule) link(
+	ctx *Context,
+	state string,
+	src string,
+	path string,
+	prev string,
+	force bool,
+) (bool, string, error) {
+	qs, qp := connection.ShellQuote(src), connection.ShellQuote(path)
+
+	if state == stateLink {
+		if prev == stateLink {
+			current, err := ctx.Conn.Exec(&connection.Command{Cmd: "readlink -- " + qp})
+			if err != nil || current.RC != 0 {
+				return false, "", fmt.Errorf("failed to read link %s: %w", path, execError(current, err))
+			}
+			if strings.TrimRight(string(current.Stdout), "\n") == src {
+				return false, "", nil
+			}
+		} else if prev != stateAbsent && !force {
+			return false, fmt.Sprintf("refusing to convert from %s to symlink for %s", prev, path), nil
+		}
+
+		cmd := "ln -sfn -- " + qs + " " + qp
+		if prev == stateDirectory {
+			cmd = "rm -rf -- " + qp + " && " + cmd
+		}
+		if err := run(ctx, cmd); err != nil {
+			return false, "", fmt.Errorf("failed to link %s: %w", path, err)
+		}
+
+		return true, "", nil
+	}
+
+	if prev != stateAbsent {
+		same, err := ctx.Conn.Exec(&connection.Command{Cmd: "[ " + qp + " -ef " + qs + " ]"})
+		if err != nil {
+			return false, "", fmt.Errorf("failed to compare %s: %w", path, err)
+		}
+		if same.RC == 0 {
+			return false, "", nil
+		}
+		if !force {
+			return false, fmt.Sprintf("refusing to convert from %s to hard link for %s", prev, path), nil
+		}
+	}
+
+	if err := run(ctx, "ln -f -- "+qs+" "+qp); err != nil {
+		return false, "", fmt.Errorf("failed to link %s: %w", path, err)
+	}
+
+	return true, "", nil
+}
+
+// pathState returns what path is on the host, without following a
+// symlink: absent, directory, file, hard (a file with several links) or
+// link.
+func pathState(
+	ctx *Context,
+	path string,
+) (string, error) {
+	q := connection.ShellQuote(path)
+	result, err := ctx.Conn.Exec(&connection.Command{
+		Cmd: fmt.Sprintf(
+			`if [ -L %[1]s ]; then echo link; elif [ -d %[1]s ]; then echo directory; `+
+				`elif [ -e %[1]s ]; then [ "$(stat -c %%h -- %[1]s)" -gt 1 ] && echo hard || echo file; `+
+				`else echo absent; fi`,
+			q,
+		),
+	})
+	if err != nil || result.RC != 0 {
+		return "", fmt.Errorf("failed to stat %s: %w", path, execError(result, err))
+	}
+
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// parseMode parses a mode argument: an octal string such as "0644", "644"
+// or "0o644", a number, which YAML already read as octal from 0644, or a
+// symbolic mode such as "u=rw,g+r,o-rwx" applied to current. X in a
+// symbolic mode only grants execute to directories and to files some
+// class can already execute.
+func parseMode(
+	v interface{},
+	current fs.FileMode,
+	isDir bool,
+) (fs.FileMode, error) {
+	var mode uint64
+	switch val := v.(type) {
+	case int:
+		mode = uint64(val)
+	case float64:
+		mode = uint64(val)
+	default:
+		s := strings.TrimSpace(fmt.Sprint(v))
+		if strings.ContainsAny(s, "+-=") {
+			return symbolicMode(s, current, isDir)
+		}
+
+		parsed, err := strconv.ParseUint(strings.TrimPrefix(s, "0o"), 8, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid mode %q: expected an octal number or a symbolic mode", s)
+		}
+		mode = parsed
+	}
+
+	if mode > 0o7777 {
+		return 0, fmt.Errorf("invalid mode %v: out of range", v)
+	}
+
+	return fs.FileMode(mode), nil
+}
+
+// classShifts maps the user classes of a symbolic mode to the position of
+// their permission bits.
+var classShifts = map[rune]uint{'u': 6, 'g': 3, 'o': 0}
+
+// classSpecial maps the user classes of a symbolic mode to the special bit
+// s or t sets for them.
+var classSpecial = map[rune]fs.FileMode{'u': 0o4000, 'g': 0o2000, 'o': 0o1000}
+
+// symbolicMode applies a symbolic mode, as chmod takes it, to current. A
+// clause without user classes applies to all of them.
+func symbolicMode(
+	s string,
+	current fs.FileMode,
+	isDir bool,
+) (fs.FileMode, error) {
+	invalid := fmt.Errorf("invalid mode %q: expected an octal number or a symbolic mode", s)
+	mode := current
+
+	for _, clause := range strings.Split(s, ",") {
+		i := strings.IndexAny(clause, "+-=")
+		if i < 0 {
+			return 0, invalid
+		}
+
+		who := clause[:i]
+		if strings.Trim(who, "ugoa") != "" {
+			return 0, invalid
+		}
+		if who == "" || strings.Contains(who, "a") {
+			who = "ugo"
+		}
+
+		for rest := clause[i:]; rest != ""; {
+			op := rest[0]
+			end := strings.IndexAny(rest[1:], "+-=") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			perms := rest[1:end]
+			rest = rest[end:]
+
+			if strings.Trim(perms, "rwxXstugo") != "" {
+				return 0, invalid
+			}
+
+			var bits, mask fs.FileMode
+			for _, class := range who {
+				shift := classShifts[class]
+				mask |= 0o7<<shift | classSpecial[class]
+				bits |= permBits(perms, mode, isDir)<<shift | specialBits(perms, class)
+			}
+
+			switch op {
+			case '+':
+				mode |= bits
+			case '-':
+				mode &^= bits
+			case '=':
+				mode = mode&^mask | bits
+			}
+		}
+	}
+
+	return mode, nil
+}
+
+// permBits returns the read, write and execute bits perms grants a single
+// class, given the mode before the change.
+func permBits(
+	perms string,
+	mode fs.FileMode,
+	isDir bool,
+) fs.FileMode {
+	var bits fs.FileMode
+	for _, p := range perms {
+		switch p {
+		case 'r':
+			bits |= 0o4
+		case 'w':
+			bits |= 0o2
+		case 'x':
+			bits |= 0o1
+		case 'X':
+			if isDir || mode&0o111 != 0 {
+				bits |= 0o1
+			}
+		case 'u', 'g', 'o':
+			bits |= mode >> classShifts[p] & 0o7
+		}
+	}
+
+	return bits
+}
+
+// specialBits returns the setuid, setgid and sticky bits perms grants a
+// class.
+func specialBits(
+	perms string,
+	class rune,
+) fs.FileMode {
+	var bits fs.FileMode
+	if strings.ContainsRune(perms, 's') && class != 'o' {
+		bits |= classSpecial[class]
+	}
+	if strings.ContainsRune(perms, 't') && class == 'o' {
+		bits |= classSpecial[class]
+	}
+
+	return bits
+}
+
+// setAttributes sets the mode, owner and group args on path where they
+// differ from info, and reports whether anything changed.
+func setAttributes(
+	ctx *Context,
+	path string,
+	info *remoteFile,
+	args map[string]interface{},
+) (bool, error) {
+	q := connection.ShellQuote(path)
+	changed := false
+
+	if owner := stringArg(args, "owner"); owner != "" && owner != info.owner {
+		if err := run(ctx, "chown -- "+connection.ShellQuote(owner)+" "+q); err != nil {
+			return false, fmt.Errorf("failed to set owner of %s: %w", path, err)
+		}
+		changed = true
+	}
+
+	if group := stringArg(args, "group"); group != "" && group != info.group {
+		if err := run(ctx, "chgrp -- "+connection.ShellQuote(group)+" "+q); err != nil {
+			return false, fmt.Errorf("failed to set group of %s: %w", path, err)
+		}
+		changed = true
+	}
+
+	if v, ok := args["mode"]; ok && v != nil {
+		mode, err := parseMode(v, info.mode, info.isDir)
+		if err != nil {
+			return false, err
+		}
+		if mode != info.mode {
+			if err := run(ctx, fmt.Sprintf("chmod %04o %s", mode, q)); err != nil {
+				return false, fmt.Errorf("failed to set mode of %s: %w", path, err)
+			}
+			changed = true
+		}
+	}
+
+	return changed, nil
+}
+
+// setAttributesRecursive sets the mode, owner and group args on everything
+// below dir, and reports whether anything changed.
+func setAttributesRecursive(
+	ctx *Context,
+	dir string,
+	args map[string]interface{},
+) (bool, error) {
+	var cmds []string
+	if owner := stringArg(args, "owner"); owner != "" {
+		cmds = append(cmds, "chown -R -c -- "+connection.ShellQuote(owner))
+	}
+	if group := stringArg(args, "group"); group != "" {
+		cmds = append(cmds, "chgrp -R -c -- "+connection.ShellQuote(group))
+	}
+	if v, ok := args["mode"]; ok && v != nil {
+		mode := fmt.Sprint(v)
+		switch val := v.(type) {
+		case int:
+			mode = fmt.Sprintf("%04o", val)
+		case float64:
+			mode = fmt.Sprintf("%04o", int(val))
+		}
+		cmds = append(cmds, "chmod -R -c -- "+connection.ShellQuote(mode))
+	}
+
+	changed := false
+	for _, cmd := range cmds {
+		result, err := ctx.Conn.Exec(&connection.Command{Cmd: cmd + " " + connection.ShellQuote(dir)})
+		if err != nil || result.RC != 0 {
+			return false, fmt.Errorf("failed to set attributes below %s: %w", dir, execError(result, err))
+		}
+		changed = changed || len(result.Stdout) > 0
+	}
+
+	return changed, nil
+}
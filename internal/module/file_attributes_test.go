@@ -0,0 +1,106 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FileAttributesTestSuite struct {
+	suite.Suite
+}
+
+func (s *FileAttributesTestSuite) TestParseMode() {
+	tests := []struct {
+		name              string
+		mode              interface{}
+		current           fs.FileMode
+		isDir             bool
+		expected          fs.FileMode
+		expectErr         bool
+		expectErrContains string
+	}{
+		{name: "octal string", mode: "0644", expected: 0o644},
+		{name: "octal string without leading zero", mode: "755", expected: 0o755},
+		{name: "python octal string", mode: "0o600", expected: 0o600},
+		{name: "special bits", mode: "4755", expected: 0o4755},
+		{name: "number read as octal", mode: 0o640, expected: 0o640},
+		{name: "number from JSON", mode: float64(0o700), expected: 0o700},
+		{name: "set classes", mode: "u=rw,g=r,o=", current: 0o777, expected: 0o640},
+		{name: "add and remove", mode: "g+w,o-rx", current: 0o755, expected: 0o770},
+		{name: "no class means all", mode: "+x", current: 0o644, expected: 0o755},
+		{name: "a is all", mode: "a-w", current: 0o664, expected: 0o444},
+		{name: "several operators", mode: "u+x-w", current: 0o644, expected: 0o544},
+		{name: "copy from a class", mode: "g=u", current: 0o604, expected: 0o664},
+		{name: "X on a directory", mode: "a+X", current: 0o600, isDir: true, expected: 0o711},
+		{name: "X on a plain file", mode: "a+X", current: 0o644, expected: 0o644},
+		{name: "X on an executable file", mode: "a+X", current: 0o744, expected: 0o755},
+		{name: "setuid and setgid", mode: "ug+s", current: 0o755, expected: 0o6755},
+		{name: "sticky", mode: "+t", current: 0o777, isDir: true, expected: 0o1777},
+		{name: "equals clears setuid", mode: "u=rwx", current: 0o4755, expected: 0o755},
+		{
+			name:              "invalid class",
+			mode:              "z+r",
+			expectErr:         true,
+			expectErrContains: `invalid mode "z+r"`,
+		},
+		{
+			name:              "invalid permission",
+			mode:              "u+q",
+			expectErr:         true,
+			expectErrContains: `invalid mode "u+q"`,
+		},
+		{
+			name:              "not octal",
+			mode:              "0899",
+			expectErr:         true,
+			expectErrContains: `invalid mode "0899"`,
+		},
+		{
+			name:              "out of range",
+			mode:              0o17777,
+			expectErr:         true,
+			expectErrContains: "out of range",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			mode, err := parseMode(tc.mode, tc.current, tc.isDir)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, mode, "%04o", mode)
+		})
+	}
+}
+
+func TestFileAttributesTestSuite(t *testing.T) {
+	suite.Run(t, new(FileAttributesTestSuite))
+}
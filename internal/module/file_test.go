@@ -0,0 +1,299 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+type FileTestSuite struct {
+	suite.Suite
+}
+
+func (s *FileTestSuite) TestRun() {
+	tests := []struct {
+		name              string
+		files             map[string]fs.FileMode
+		dirs              map[string]fs.FileMode
+		links             map[string]string
+		path              string
+		args              map[string]interface{}
+		expectChanged     bool
+		expectFailed      bool
+		expectMsg         string
+		expectState       string
+		expectModes       map[string]fs.FileMode
+		expectLinks       map[string]string
+		expectAbsent      []string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:          "directory is created",
+			path:          "a/b",
+			args:          map[string]interface{}{"state": "directory", "mode": "0750"},
+			expectChanged: true,
+			expectState:   "directory",
+			expectModes:   map[string]fs.FileMode{"a/b": 0o750},
+		},
+		{
+			name:        "existing directory is unchanged",
+			dirs:        map[string]fs.FileMode{"a": 0o755},
+			path:        "a",
+			args:        map[string]interface{}{"state": "directory", "mode": "0755"},
+			expectState: "directory",
+		},
+		{
+			name:        "state defaults to directory for a directory",
+			dirs:        map[string]fs.FileMode{"a": 0o755},
+			path:        "a",
+			args:        map[string]interface{}{},
+			expectState: "directory",
+		},
+		{
+			name:          "file mode is set",
+			files:         map[string]fs.FileMode{"f": 0o644},
+			path:          "f",
+			args:          map[string]interface{}{"mode": "0600"},
+			expectChanged: true,
+			expectState:   "file",
+			expectModes:   map[string]fs.FileMode{"f": 0o600},
+		},
+		{
+			name:          "symbolic mode",
+			files:         map[string]fs.FileMode{"f": 0o644},
+			path:          "f",
+			args:          map[string]interface{}{"state": "file", "mode": "u+x,g-r,o-r"},
+			expectChanged: true,
+			expectState:   "file",
+			expectModes:   map[string]fs.FileMode{"f": 0o700},
+		},
+		{
+			name:         "missing file fails",
+			path:         "f",
+			args:         map[string]interface{}{"state": "file"},
+			expectFailed: true,
+			expectMsg:    "is absent, cannot continue",
+		},
+		{
+			name:         "directory over a file fails",
+			files:        map[string]fs.FileMode{"f": 0o644},
+			path:         "f",
+			args:         map[string]interface{}{"state": "directory"},
+			expectFailed: true,
+			expectMsg:    "already exists as a file",
+		},
+		{
+			name:          "touch creates a file",
+			path:          "f",
+			args:          map[string]interface{}{"state": "touch", "mode": "0640"},
+			expectChanged: true,
+			expectState:   "touch",
+			expectModes:   map[string]fs.FileMode{"f": 0o640},
+		},
+		{
+			name:          "absent removes a directory tree",
+			files:         map[string]fs.FileMode{"a/f": 0o644},
+			path:          "a",
+			args:          map[string]interface{}{"state": "absent"},
+			expectChanged: true,
+			expectState:   "absent",
+			expectAbsent:  []string{"a"},
+		},
+		{
+			name:        "absent on a missing path is unchanged",
+			path:        "a",
+			args:        map[string]interface{}{"state": "absent"},
+			expectState: "absent",
+		},
+		{
+			name:          "symlink is created",
+			files:         map[string]fs.FileMode{"f": 0o644},
+			path:          "l",
+			args:          map[string]interface{}{"state": "link", "src": "f"},
+			expectChanged: true,
+			expectState:   "link",
+			expectLinks:   map[string]string{"l": "f"},
+		},
+		{
+			name:        "symlink to the same src is unchanged",
+			files:       map[string]fs.FileMode{"f": 0o644},
+			links:       map[string]string{"l": "f"},
+			path:        "l",
+			args:        map[string]interface{}{"state": "link", "src": "f"},
+			expectState: "link",
+			expectLinks: map[string]string{"l": "f"},
+		},
+		{
+			name:          "symlink is repointed",
+			files:         map[string]fs.FileMode{"f": 0o644, "g": 0o644},
+			links:         map[string]string{"l": "f"},
+			path:          "l",
+			args:          map[string]interface{}{"state": "link", "src": "g"},
+			expectChanged: true,
+			expectState:   "link",
+			expectLinks:   map[string]string{"l": "g"},
+		},
+		{
+			name:         "symlink over a file needs force",
+			files:        map[string]fs.FileMode{"f": 0o644, "l": 0o644},
+			path:         "l",
+			args:         map[string]interface{}{"state": "link", "src": "f"},
+			expectFailed: true,
+			expectMsg:    "refusing to convert from file to symlink",
+		},
+		{
+			name:          "symlink replaces a directory with force",
+			files:         map[string]fs.FileMode{"f": 0o644, "l/x": 0o644},
+			path:          "l",
+			args:          map[string]interface{}{"state": "link", "src": "f", "force": true},
+			expectChanged: true,
+			expectState:   "link",
+			expectLinks:   map[string]string{"l": "f"},
+		},
+		{
+			name:          "hard link is created",
+			files:         map[string]fs.FileMode{"f": 0o644},
+			path:          "h",
+			args:          map[string]interface{}{"state": "hard", "src": "f"},
+			expectChanged: true,
+			expectState:   "hard",
+		},
+		{
+			name:          "recurse sets the mode of the content",
+			dirs:          map[string]fs.FileMode{"a": 0o755, "a/b": 0o755},
+			files:         map[string]fs.FileMode{"a/b/f": 0o644},
+			path:          "a",
+			args:          map[string]interface{}{"recurse": true, "mode": "u=rwX,go="},
+			expectChanged: true,
+			expectState:   "directory",
+			expectModes: map[string]fs.FileMode{
+				"a":     0o700,
+				"a/b":   0o700,
+				"a/b/f": 0o600,
+			},
+		},
+		{
+			name:        "recurse with nothing to change",
+			dirs:        map[string]fs.FileMode{"a": 0o700},
+			files:       map[string]fs.FileMode{"a/f": 0o600},
+			path:        "a",
+			args:        map[string]interface{}{"recurse": true, "mode": "u=rwX,go="},
+			expectState: "directory",
+		},
+		{
+			name:              "recurse needs a directory",
+			path:              "f",
+			args:              map[string]interface{}{"state": "file", "recurse": true},
+			expectErr:         true,
+			expectErrContains: "recurse option requires state to be 'directory'",
+		},
+		{
+			name:              "link needs src",
+			path:              "l",
+			args:              map[string]interface{}{"state": "link"},
+			expectErr:         true,
+			expectErrContains: "src is required for creating a symlink",
+		},
+		{
+			name:              "invalid state",
+			path:              "f",
+			args:              map[string]interface{}{"state": "gone"},
+			expectErr:         true,
+			expectErrContains: "value of state must be one of",
+		},
+		{
+			name:              "missing path",
+			args:              map[string]interface{}{},
+			expectErr:         true,
+			expectErrContains: "missing required argument: path",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			dir := s.T().TempDir()
+			for name, mode := range tc.dirs {
+				s.Require().NoError(os.MkdirAll(filepath.Join(dir, name), 0o755))
+				s.Require().NoError(os.Chmod(filepath.Join(dir, name), mode))
+			}
+			for name, mode := range tc.files {
+				s.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+				s.Require().NoError(os.WriteFile(filepath.Join(dir, name), nil, mode))
+				s.Require().NoError(os.Chmod(filepath.Join(dir, name), mode))
+			}
+			for name, src := range tc.links {
+				s.Require().NoError(os.Symlink(filepath.Join(dir, src), filepath.Join(dir, name)))
+			}
+
+			args := maps.Clone(tc.args)
+			if tc.path != "" {
+				args["path"] = filepath.Join(dir, tc.path)
+			}
+			if src, ok := args["src"].(string); ok {
+				args["src"] = filepath.Join(dir, src)
+			}
+			ctx := &Context{Conn: connection.NewLocal()}
+
+			result, err := (&fileModule{}).Run(ctx, args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expectChanged, result.Changed)
+			s.Equal(tc.expectFailed, result.Failed, result.Msg)
+			s.Contains(result.Msg, tc.expectMsg)
+			if tc.expectState != "" {
+				s.Equal(tc.expectState, result.Data["state"])
+			}
+			for name, mode := range tc.expectModes {
+				st, err := os.Stat(filepath.Join(dir, name))
+				s.Require().NoError(err)
+				s.Equal(mode, st.Mode().Perm(), name)
+			}
+			for name, src := range tc.expectLinks {
+				target, err := os.Readlink(filepath.Join(dir, name))
+				s.Require().NoError(err)
+				s.Equal(filepath.Join(dir, src), target, name)
+			}
+			for _, name := range tc.expectAbsent {
+				_, err := os.Lstat(filepath.Join(dir, name))
+				s.True(os.IsNotExist(err), name)
+			}
+		})
+	}
+}
+
+func TestFileTestSuite(t *testing.T) {
+	suite.Run(t, new(FileTestSuite))
+}
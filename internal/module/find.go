@@ -0,0 +1,427 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// ageUnits holds the seconds of each age suffix find takes.
+var ageUnits = map[byte]int64{
+	's': 1,
+	'm': 60,
+	'h': 60 * 60,
+	'd': 24 * 60 * 60,
+	'w': 7 * 24 * 60 * 60,
+}
+
+// sizeUnits holds the bytes of each size suffix find takes.
+var sizeUnits = map[byte]int64{
+	'b': 1,
+	'k': 1 << 10,
+	'm': 1 << 20,
+	'g': 1 << 30,
+	't': 1 << 40,
+}
+
+// findModule lists the files under directories matching the given
+// criteria, like ansible.builtin.find.
+type findModule struct{}
+
+// findFilter holds the criteria a file found must meet.
+type findFilter struct {
+	patterns  []matcher
+	excludes  []matcher
+	contains  *regexp.Regexp
+	fileType  string
+	hidden    bool
+	age       int64
+	ageStamp  string
+	size      int64
+	sizeIsSet bool
+	ageIsSet  bool
+	now       int64
+}
+
+// matcher matches the base name of a file.
+type matcher func(name string) bool
+
+// Run walks paths, recursing with recurse down to depth, and returns the
+// files whose base name matches patterns and none of excludes, as globs or
+// regular expressions with use_regex, of file_type, older than age (newer
+// when negative), at least size (at most when negative) and, for regular
+// files, with a line matching contains. Hidden files are left out unless
+// hidden is set.
+func (m *findModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	var paths []string
+	for _, name := range []string{"paths", "path", "name"} {
+		if paths = listArg(args, name); len(paths) > 0 {
+			break
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("missing required argument: paths")
+	}
+
+	filter, err := m.filter(args)
+	if err != nil {
+		return nil, err
+	}
+
+	recurse, err := boolArg(args, "recurse", false)
+	if err != nil {
+		return nil, err
+	}
+	follow, err := boolArg(args, "follow", false)
+	if err != nil {
+		return nil, err
+	}
+	getChecksum, err := boolArg(args, "get_checksum", false)
+	if err != nil {
+		return nil, err
+	}
+	depth, err := intArg(args, "depth")
+	if err != nil {
+		return nil, err
+	}
+	if !recurse {
+		depth = 1
+	}
+
+	findFlags, statFlags := "", ""
+	if follow {
+		findFlags, statFlags = "-L ", "-L "
+	}
+	maxDepth := ""
+	if depth > 0 {
+		maxDepth = fmt.Sprintf(" -maxdepth %d", depth)
+	}
+
+	files := make([]interface{}, 0)
+	skipped := make(map[string]interface{})
+	examined := 0
+	for _, dir := range paths {
+		q := connection.ShellQuote(dir)
+		result, err := ctx.Conn.Exec(&connection.Command{
+			Cmd: fmt.Sprintf(
+				"[ -d %[1]s ] || exit 3; printf '%%s\\0' \"$(date +%%s)\"; find %[2]s%[1]s -mindepth 1%[3]s -exec stat %[4]s--printf '%[5]s' -- {} + 2>/dev/null; true",
+				q, findFlags, maxDepth, statFlags, statFormat,
+			),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find in %s: %w", dir, err)
+		}
+		if result.RC == 3 {
+			skipped[dir] = fmt.Sprintf(
+				"%s was skipped as it does not seem to be a valid directory or it cannot be accessed",
+				dir,
+			)
+			continue
+		}
+		if result.RC != 0 {
+			return nil, fmt.Errorf("failed to find in %s: %w", dir, execError(result, nil))
+		}
+
+		// records end in a NUL, leaving an empty last element
+		records := strings.Split(string(result.Stdout), "\x00")
+		if filter.now, err = strconv.ParseInt(records[0], 10, 64); err != nil {
+			return nil, fmt.Errorf("failed to find in %s: unexpected output %q", dir, records[0])
+		}
+
+		for _, record := range records[1 : len(records)-1] {
+			stat, err := parseStat(record)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find in %s: %w", dir, err)
+			}
+			examined++
+
+			ok, err := filter.match(ctx, stat)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+
+			if getChecksum && stat["isreg"] == true {
+				if stat["checksum"], err = checksum(ctx, stat["path"].(string)); err != nil {
+					return nil, err
+				}
+			}
+			files = append(files, stat)
+		}
+	}
+
+	return &Result{
+		Msg: "All paths examined",
+		Data: map[string]interface{}{
+			"files":         files,
+			"matched":       len(files),
+			"examined":      examined,
+			"skipped_paths": skipped,
+		},
+	}, nil
+}
+
+// filter reads the criteria of find from its args.
+func (m *findModule) filter(
+	args map[string]interface{},
+) (*findFilter, error) {
+	useRegex, err := boolArg(args, "use_regex", false)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := boolArg(args, "hidden", false)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &findFilter{
+		fileType: stringArg(args, "file_type"),
+		ageStamp: stringArg(args, "age_stamp"),
+		hidden:   hidden,
+	}
+	if f.fileType == "" {
+		f.fileType = "file"
+	}
+	switch f.fileType {
+	case "any", "directory", "file", "link":
+	default:
+		return nil, fmt.Errorf(
+			"value of file_type must be one of: any, directory, file, link, got: %s",
+			f.fileType,
+		)
+	}
+	if f.ageStamp == "" {
+		f.ageStamp = "mtime"
+	}
+	switch f.ageStamp {
+	case "atime", "ctime", "mtime":
+	default:
+		return nil, fmt.Errorf(
+			"value of age_stamp must be one of: atime, ctime, mtime, got: %s",
+			f.ageStamp,
+		)
+	}
+
+	patterns := listArg(args, "patterns")
+	if len(patterns) == 0 {
+		patterns = listArg(args, "pattern")
+	}
+	if f.patterns, err = matchers(patterns, useRegex); err != nil {
+		return nil, err
+	}
+	excludes := listArg(args, "excludes")
+	if len(excludes) == 0 {
+		excludes = listArg(args, "exclude")
+	}
+	if f.excludes, err = matchers(excludes, useRegex); err != nil {
+		return nil, err
+	}
+
+	if contains := stringArg(args, "contains"); contains != "" {
+		if f.contains, err = regexp.Compile(contains); err != nil {
+			return nil, fmt.Errorf("invalid contains %q: %w", contains, err)
+		}
+	}
+	if age := stringArg(args, "age"); age != "" {
+		if f.age, err = parseSuffixed(age, ageUnits, 's'); err != nil {
+			return nil, fmt.Errorf("failed to process age %q", age)
+		}
+		f.ageIsSet = true
+	}
+	if size := stringArg(args, "size"); size != "" {
+		if f.size, err = parseSuffixed(size, sizeUnits, 'b'); err != nil {
+			return nil, fmt.Errorf("failed to process size %q", size)
+		}
+		f.sizeIsSet = true
+	}
+
+	return f, nil
+}
+
+// match reports whether a file, as parseStat describes it, meets the
+// criteria.
+func (f *findFilter) match(
+	ctx *Context,
+	stat map[string]interface{},
+) (bool, error) {
+	name := path.Base(stat["path"].(string))
+	if !f.hidden && strings.HasPrefix(name, ".") {
+		return false, nil
+	}
+
+	switch f.fileType {
+	case "directory":
+		if stat["isdir"] != true {
+			return false, nil
+		}
+	case "file":
+		if stat["isreg"] != true {
+			return false, nil
+		}
+	case "link":
+		if stat["islnk"] != true {
+			return false, nil
+		}
+	}
+
+	if len(f.patterns) > 0 && !matchAny(f.patterns, name) {
+		return false, nil
+	}
+	if matchAny(f.excludes, name) {
+		return false, nil
+	}
+
+	if f.ageIsSet {
+		age := f.now - stat[f.ageStamp].(int64)
+		if f.age >= 0 && age < f.age || f.age < 0 && age > -f.age {
+			return false, nil
+		}
+	}
+	if f.sizeIsSet && stat["isreg"] == true {
+		size := stat["size"].(int64)
+		if f.size >= 0 && size < f.size || f.size < 0 && size > -f.size {
+			return false, nil
+		}
+	}
+
+	if f.contains != nil {
+		if stat["isreg"] != true {
+			return false, nil
+		}
+
+		var content bytes.Buffer
+		if err := ctx.Conn.Fetch(stat["path"].(string), &content); err != nil {
+			return false, fmt.Errorf("failed to read %s: %w", stat["path"], err)
+		}
+
+		found := false
+		for _, line := range strings.Split(content.String(), "\n") {
+			if f.contains.MatchString(line) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// matchers compiles patterns into matchers of base names: globs, or
+// regular expressions matching from the start of the name with useRegex.
+func matchers(
+	patterns []string,
+	useRegex bool,
+) ([]matcher, error) {
+	list := make([]matcher, 0, len(patterns))
+	for _, pattern := range patterns {
+		if !useRegex {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			list = append(list, func(name string) bool {
+				ok, _ := path.Match(pattern, name)
+				return ok
+			})
+			continue
+		}
+
+		re, err := regexp.Compile(`^(?:` + pattern + `)`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		list = append(list, re.MatchString)
+	}
+
+	return list, nil
+}
+
+// matchAny reports whether any matcher matches name.
+func matchAny(
+	list []matcher,
+	name string,
+) bool {
+	for _, m := range list {
+		if m(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseSuffixed parses a number followed by an optional unit suffix, as
+// the age and size of find take them, in the base unit. Without a suffix
+// the number is in def.
+func parseSuffixed(
+	v string,
+	units map[byte]int64,
+	def byte,
+) (int64, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	unit := def
+	if v != "" {
+		if _, ok := units[v[len(v)-1]]; ok {
+			unit = v[len(v)-1]
+			v = v[:len(v)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return n * units[unit], nil
+}
+
+// checksum returns the SHA-1 of the content of a file on the host.
+func checksum(
+	ctx *Context,
+	path string,
+) (string, error) {
+	result, err := ctx.Conn.Exec(&connection.Command{
+		// read from stdin, as sha1sum escapes the output for a name holding
+		// a newline
+		Cmd: "sha1sum < " + connection.ShellQuote(path),
+	})
+	if err != nil || result.RC != 0 {
+		return "", fmt.Errorf("failed to checksum %s: %w", path, execError(result, err))
+	}
+
+	sum, _, _ := strings.Cut(string(result.Stdout), " ")
+
+	return sum, nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findFile returns the local path of the file name. A relative name is
+// looked up in subdir (e.g., "files") of every search path, then in the
+// search path itself, as Ansible does.
+func findFile(
+	ctx *Context,
+	subdir string,
+	name string,
+) (string, error) {
+	if filepath.IsAbs(name) {
+		if _, err := os.Stat(name); err != nil {
+			return "", fmt.Errorf("could not find or access %q: %w", name, err)
+		}
+		return name, nil
+	}
+
+	var tried []string
+	for _, dir := range ctx.SearchPaths {
+		for _, candidate := range []string{
+			filepath.Join(dir, subdir, name),
+			filepath.Join(dir, name),
+		} {
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate, nil
+			}
+			tried = append(tried, candidate)
+		}
+	}
+
+	return "", fmt.Errorf(
+		"could not find or access %q, searched in: %s",
+		name,
+		strings.Join(tried, ", "),
+	)
+}
@@ -0,0 +1,296 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+type FindTestSuite struct {
+	suite.Suite
+
+	dir string
+}
+
+func (s *FindTestSuite) SetupSuite() {
+	s.dir = s.T().TempDir()
+
+	files := map[string]string{
+		"app.conf":          "port=80\n",
+		"app.log":           "started\nlisten on 80\n",
+		"big.log":           string(make([]byte, 4096)),
+		".hidden.conf":      "x\n",
+		"conf.d/a.conf":     "a\n",
+		"conf.d/deep/b.log": "b\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(s.dir, name)
+		s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0o755))
+		s.Require().NoError(os.WriteFile(path, []byte(content), 0o644))
+	}
+	s.Require().NoError(os.Symlink("app.conf", filepath.Join(s.dir, "current.conf")))
+
+	old := time.Now().Add(-3 * 24 * time.Hour)
+	s.Require().NoError(os.Chtimes(filepath.Join(s.dir, "app.log"), old, old))
+}
+
+func (s *FindTestSuite) TestRun() {
+	tests := []struct {
+		name              string
+		args              map[string]interface{}
+		expectFiles       []string
+		expectSkipped     []string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:        "regular files at the top",
+			args:        map[string]interface{}{},
+			expectFiles: []string{"app.conf", "app.log", "big.log"},
+		},
+		{
+			name:        "patterns as a comma-separated string",
+			args:        map[string]interface{}{"patterns": "*.conf,*.txt"},
+			expectFiles: []string{"app.conf"},
+		},
+		{
+			name: "recurse with excludes",
+			args: map[string]interface{}{
+				"patterns": []interface{}{"*.conf", "*.log"},
+				"excludes": "big.*",
+				"recurse":  true,
+			},
+			expectFiles: []string{"app.conf", "app.log", "conf.d/a.conf", "conf.d/deep/b.log"},
+		},
+		{
+			name: "depth limits recursion",
+			args: map[string]interface{}{
+				"patterns": "*.log",
+				"recurse":  "yes",
+				"depth":    2,
+			},
+			expectFiles: []string{"app.log", "big.log"},
+		},
+		{
+			name: "regular expressions",
+			args: map[string]interface{}{
+				"patterns":  `app\.(conf|log)`,
+				"use_regex": true,
+			},
+			expectFiles: []string{"app.conf", "app.log"},
+		},
+		{
+			name: "hidden files",
+			args: map[string]interface{}{
+				"patterns": "*.conf",
+				"hidden":   true,
+			},
+			expectFiles: []string{".hidden.conf", "app.conf"},
+		},
+		{
+			name:        "directories",
+			args:        map[string]interface{}{"file_type": "directory", "recurse": true},
+			expectFiles: []string{"conf.d", "conf.d/deep"},
+		},
+		{
+			name:        "links",
+			args:        map[string]interface{}{"file_type": "link"},
+			expectFiles: []string{"current.conf"},
+		},
+		{
+			name:        "links are followed",
+			args:        map[string]interface{}{"patterns": "current.*", "follow": true},
+			expectFiles: []string{"current.conf"},
+		},
+		{
+			name:        "older than an age",
+			args:        map[string]interface{}{"age": "2d"},
+			expectFiles: []string{"app.log"},
+		},
+		{
+			name:        "newer than an age",
+			args:        map[string]interface{}{"age": "-1h"},
+			expectFiles: []string{"app.conf", "big.log"},
+		},
+		{
+			name:        "at least a size",
+			args:        map[string]interface{}{"size": "4k"},
+			expectFiles: []string{"big.log"},
+		},
+		{
+			name:        "at most a size",
+			args:        map[string]interface{}{"size": "-10"},
+			expectFiles: []string{"app.conf"},
+		},
+		{
+			name:        "contains",
+			args:        map[string]interface{}{"contains": "^listen on [0-9]+$"},
+			expectFiles: []string{"app.log"},
+		},
+		{
+			name: "missing paths are skipped",
+			args: map[string]interface{}{
+				"paths":    []interface{}{"missing", "conf.d"},
+				"patterns": "*.conf",
+			},
+			expectFiles:   []string{"conf.d/a.conf"},
+			expectSkipped: []string{"missing"},
+		},
+		{
+			name:              "invalid file type",
+			args:              map[string]interface{}{"file_type": "socket"},
+			expectErr:         true,
+			expectErrContains: "value of file_type must be one of",
+		},
+		{
+			name:              "invalid age",
+			args:              map[string]interface{}{"age": "2y"},
+			expectErr:         true,
+			expectErrContains: `failed to process age "2y"`,
+		},
+		{
+			name:              "invalid regular expression",
+			args:              map[string]interface{}{"patterns": "(", "use_regex": true},
+			expectErr:         true,
+			expectErrContains: `invalid pattern "("`,
+		},
+		{
+			name:              "paths are required",
+			args:              map[string]interface{}{"paths": []interface{}{}},
+			expectErr:         true,
+			expectErrContains: "missing required argument: paths",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			args := tc.args
+			paths := []interface{}{s.dir}
+			if list, ok := args["paths"].([]interface{}); ok {
+				paths = make([]interface{}, 0, len(list))
+				for _, p := range list {
+					paths = append(paths, filepath.Join(s.dir, p.(string)))
+				}
+			}
+			args["paths"] = paths
+			ctx := &Context{Conn: connection.NewLocal()}
+
+			result, err := (&findModule{}).Run(ctx, args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.False(result.Changed)
+
+			files, ok := result.Data["files"].([]interface{})
+			s.Require().True(ok)
+			found := make([]string, 0, len(files))
+			for _, f := range files {
+				rel, err := filepath.Rel(s.dir, f.(map[string]interface{})["path"].(string))
+				s.Require().NoError(err)
+				found = append(found, rel)
+			}
+			sort.Strings(found)
+			s.Equal(tc.expectFiles, found)
+			s.Equal(len(tc.expectFiles), result.Data["matched"])
+
+			skipped, ok := result.Data["skipped_paths"].(map[string]interface{})
+			s.Require().True(ok)
+			s.Len(skipped, len(tc.expectSkipped))
+			for _, p := range tc.expectSkipped {
+				s.Contains(skipped, filepath.Join(s.dir, p))
+			}
+		})
+	}
+}
+
+func (s *FindTestSuite) TestRunNameWithNewline() {
+	dir := s.T().TempDir()
+	for _, name := range []string{"new\nline.log", "plain.log"} {
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, name), []byte("hello\n"), 0o644))
+	}
+
+	ctx := &Context{Conn: connection.NewLocal()}
+	result, err := (&findModule{}).Run(ctx, map[string]interface{}{
+		"paths":        dir,
+		"patterns":     "*.log",
+		"get_checksum": true,
+	})
+	s.Require().NoError(err)
+
+	files, ok := result.Data["files"].([]interface{})
+	s.Require().True(ok)
+	found := make([]string, 0, len(files))
+	for _, f := range files {
+		stat := f.(map[string]interface{})
+		found = append(found, filepath.Base(stat["path"].(string)))
+		s.Equal("f572d396fae9206628714fb2ce00f72e94f2258f", stat["checksum"])
+	}
+	sort.Strings(found)
+	s.Equal([]string{"new\nline.log", "plain.log"}, found)
+}
+
+func (s *FindTestSuite) TestParseSuffixed() {
+	tests := []struct {
+		name      string
+		value     string
+		units     map[byte]int64
+		def       byte
+		expected  int64
+		expectErr bool
+	}{
+		{name: "age in weeks", value: "2w", units: ageUnits, def: 's', expected: 2 * 7 * 24 * 60 * 60},
+		{name: "negative age", value: "-30m", units: ageUnits, def: 's', expected: -30 * 60},
+		{name: "age without unit", value: "90", units: ageUnits, def: 's', expected: 90},
+		{name: "size in megabytes", value: "1M", units: sizeUnits, def: 'b', expected: 1 << 20},
+		{name: "invalid", value: "big", units: sizeUnits, def: 'b', expectErr: true},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			n, err := parseSuffixed(tc.value, tc.units, tc.def)
+
+			if tc.expectErr {
+				s.Error(err)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, n)
+		})
+	}
+}
+
+func TestFindTestSuite(t *testing.T) {
+	suite.Run(t, new(FindTestSuite))
+}
@@ -0,0 +1,352 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// lineinfileModule ensures a line is in a file, or is not, like
+// ansible.builtin.lineinfile.
+type lineinfileModule struct{}
+
+// lineSpec holds the args of lineinfile deciding where a line goes.
+type lineSpec struct {
+	line         string
+	regexp       *regexp.Regexp
+	searchString *string
+	insertAfter  string
+	insertBefore string
+	backrefs     bool
+	firstMatch   bool
+}
+
+// Run ensures line is in path, replacing the last line matching regexp
+// (the first with firstmatch) or containing search_string, or when none
+// does, inserting it after the last line matching insertafter or before
+// the last line matching insertbefore, or at EOF or BOF. With backrefs the
+// groups of regexp are expanded in line and nothing is inserted when it
+// does not match. With state absent, every line matching regexp or
+// search_string, or equal to line, is removed.
+func (m *lineinfileModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	for _, pair := range [][2]string{
+		{"insertbefore", "insertafter"},
+		{"regexp", "search_string"},
+		{"backrefs", "search_string"},
+	} {
+		if args[pair[0]] != nil && args[pair[1]] != nil {
+			return nil, fmt.Errorf("parameters are mutually exclusive: %s|%s", pair[0], pair[1])
+		}
+	}
+
+	spec := &lineSpec{
+		insertAfter:  stringArg(args, "insertafter"),
+		insertBefore: stringArg(args, "insertbefore"),
+	}
+
+	var err error
+	if spec.backrefs, err = boolArg(args, "backrefs", false); err != nil {
+		return nil, err
+	}
+	if spec.firstMatch, err = boolArg(args, "firstmatch", false); err != nil {
+		return nil, err
+	}
+
+	pattern, hasRegexp := args["regexp"]
+	if !hasRegexp {
+		pattern, hasRegexp = args["regex"]
+	}
+	if hasRegexp && pattern != nil {
+		if spec.regexp, err = compilePython(fmt.Sprint(pattern)); err != nil {
+			return nil, err
+		}
+	}
+	if s, ok := args["search_string"]; ok && s != nil {
+		search := fmt.Sprint(s)
+		spec.searchString = &search
+	}
+
+	line, hasLine := args["line"]
+	if !hasLine {
+		line, hasLine = args["value"]
+	}
+	hasLine = hasLine && line != nil
+	if hasLine {
+		spec.line = fmt.Sprint(line)
+	}
+
+	state := stringArg(args, "state")
+	switch state {
+	case "", "present":
+		if spec.backrefs && spec.regexp == nil {
+			return nil, fmt.Errorf("regexp is required with backrefs=true")
+		}
+		if !hasLine {
+			return nil, fmt.Errorf("line is required with state=present")
+		}
+		if spec.insertAfter == "" && spec.insertBefore == "" {
+			spec.insertAfter = "EOF"
+		}
+	case "absent":
+		if spec.regexp == nil && spec.searchString == nil && !hasLine {
+			return nil, fmt.Errorf("one of line, search_string, or regexp is required with state=absent")
+		}
+	default:
+		return nil, fmt.Errorf("value of state must be one of: absent, present, got: %s", state)
+	}
+
+	var insertRe *regexp.Regexp
+	switch {
+	case spec.insertAfter != "" && spec.insertAfter != "BOF" && spec.insertAfter != "EOF":
+		insertRe, err = compilePython(spec.insertAfter)
+	case spec.insertBefore != "" && spec.insertBefore != "BOF":
+		insertRe, err = compilePython(spec.insertBefore)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if state == "absent" {
+		return m.absent(ctx, args, spec, hasLine)
+	}
+
+	return m.present(ctx, args, spec, insertRe)
+}
+
+// present ensures the line is in the file, following Ansible's
+// lineinfile step for step.
+func (m *lineinfileModule) present(
+	ctx *Context,
+	args map[string]interface{},
+	spec *lineSpec,
+	insertRe *regexp.Regexp,
+) (*Result, error) {
+	f, failed, err := openText(ctx, args, "Destination %s does not exist !")
+	if err != nil || failed != nil {
+		return failed, err
+	}
+	lines := splitKeep(f.content)
+
+	// index[0] is the line the regexp, search string or line itself was
+	// found at, index[1] the line insertafter or insertbefore was found at
+	index := [2]int{-1, -1}
+	var match []int
+	matchLine := ""
+
+	// a regexp or search string match replaces the line it matches and
+	// leaves insertafter and insertbefore unused
+	switch {
+	case spec.regexp != nil:
+		for i, cur := range lines {
+			if found := spec.regexp.FindStringSubmatchIndex(trimNewline(cur)); found != nil {
+				index[0], match, matchLine = i, found, trimNewline(cur)
+				if spec.firstMatch {
+					break
+				}
+			}
+		}
+	case spec.searchString != nil:
+		for i, cur := range lines {
+			if strings.Contains(cur, *spec.searchString) {
+				index[0], match = i, []int{}
+				if spec.firstMatch {
+					break
+				}
+			}
+		}
+	}
+
+	if match == nil {
+		for i, cur := range lines {
+			switch {
+			case spec.line == strings.TrimRight(cur, "\r\n"):
+				index[0] = i
+			case insertRe != nil && insertRe.MatchString(trimNewline(cur)):
+				if spec.insertAfter != "" {
+					index[1] = i + 1
+				} else {
+					index[1] = i
+				}
+			default:
+				continue
+			}
+			if index[1] != -1 && spec.firstMatch {
+				break
+			}
+		}
+	}
+
+	changed, msg := false, ""
+	add := func(at int) {
+		lines = slices.Insert(lines, at, spec.line+"\n")
+		changed, msg = true, "line added"
+	}
+
+	switch {
+	case index[0] != -1:
+		newLine := spec.line
+		if spec.backrefs && match != nil {
+			if newLine, err = expandPython(spec.line, spec.regexp, matchLine, match); err != nil {
+				return nil, err
+			}
+		}
+		if !strings.HasSuffix(newLine, "\n") {
+			newLine += "\n"
+		}
+		if lines[index[0]] != newLine {
+			lines[index[0]] = newLine
+			changed, msg = true, "line replaced"
+		}
+
+	case spec.backrefs:
+		// the line cannot be generated without a match to fill in the
+		// backreferences
+
+	case spec.insertBefore == "BOF" || spec.insertAfter == "BOF":
+		add(0)
+
+	case spec.insertAfter == "EOF" || index[1] == -1:
+		if len(lines) > 0 && !endsWithNewline(lines[len(lines)-1]) {
+			lines = append(lines, "\n")
+		}
+		add(len(lines))
+
+	case spec.insertAfter != "":
+		if !endsWithNewline(lines[len(lines)-1]) {
+			lines[len(lines)-1] += "\n"
+		}
+
+		// the line is not inserted again right after the match
+		if index[1] == len(lines) {
+			if strings.TrimRight(lines[index[1]-1], "\r\n") != spec.line {
+				add(index[1])
+			}
+		} else if strings.TrimRight(lines[index[1]], "\r\n") != spec.line {
+			add(index[1])
+		}
+
+	default:
+		add(index[1])
+	}
+
+	result, err := f.save(ctx, []byte(strings.Join(lines, "")), changed, msg, args)
+	if err != nil || result.Failed {
+		return result, err
+	}
+	result.Data["backup"] = backupName(result)
+
+	return result, nil
+}
+
+// absent removes every line matching the regexp or containing the search
+// string, or equal to the line.
+func (m *lineinfileModule) absent(
+	ctx *Context,
+	args map[string]interface{},
+	spec *lineSpec,
+	hasLine bool,
+) (*Result, error) {
+	f, failed, err := openText(ctx, withCreate(args), "")
+	if err != nil || failed != nil {
+		return failed, err
+	}
+	if !f.info.exists {
+		return &Result{Msg: "file not present"}, nil
+	}
+
+	var kept []string
+	found := 0
+	for _, cur := range splitKeep(f.content) {
+		var matched bool
+		switch {
+		case spec.regexp != nil:
+			matched = spec.regexp.MatchString(trimNewline(cur))
+		case spec.searchString != nil:
+			matched = strings.Contains(cur, *spec.searchString)
+		default:
+			matched = hasLine && spec.line == strings.TrimRight(cur, "\r\n")
+		}
+
+		if matched {
+			found++
+		} else {
+			kept = append(kept, cur)
+		}
+	}
+
+	msg := ""
+	if found > 0 {
+		msg = fmt.Sprintf("%d line(s) removed", found)
+	}
+
+	result, err := f.save(ctx, []byte(strings.Join(kept, "")), found > 0, msg, args)
+	if err != nil || result.Failed {
+		return result, err
+	}
+	result.Data["found"] = found
+	result.Data["backup"] = backupName(result)
+
+	return result, nil
+}
+
+// withCreate returns args with create set, for reading a file that may
+// be missing.
+func withCreate(
+	args map[string]interface{},
+) map[string]interface{} {
+	args = maps.Clone(args)
+	args["create"] = true
+
+	return args
+}
+
+// trimNewline strips the line feed ending a line, which Python's $ matches
+// before.
+func trimNewline(
+	line string,
+) string {
+	return strings.TrimSuffix(line, "\n")
+}
+
+// endsWithNewline reports whether a line ends with a line break.
+func endsWithNewline(
+	line string,
+) bool {
+	return strings.HasSuffix(line, "\n") || strings.HasSuffix(line, "\r")
+}
+
+// backupName returns the backup made by a save, which lineinfile reports
+// as backup, empty when there is none.
+func backupName(
+	result *Result,
+) string {
+	name, _ := result.Data["backup_file"].(string)
+	delete(result.Data, "backup_file")
+
+	return name
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LineinfileTestSuite struct {
+	suite.Suite
+}
+
+func (s *LineinfileTestSuite) TestRun() {
+	tests := []goldenCase{
+		{
+			name: "regexp",
+			args: map[string]interface{}{
+				"regexp": "^PermitRootLogin",
+				"line":   "PermitRootLogin prohibit-password",
+			},
+			expectChanged: true,
+			expectMsg:     "line replaced",
+			expectData:    map[string]interface{}{"backup": ""},
+		},
+		{
+			name: "regexp_firstmatch",
+			args: map[string]interface{}{
+				"regexp":     "^PermitRootLogin",
+				"line":       "PermitRootLogin prohibit-password",
+				"firstmatch": true,
+			},
+			expectChanged: true,
+			expectMsg:     "line replaced",
+		},
+		{
+			name: "regexp_backup",
+			args: map[string]interface{}{
+				"regex":  `^Port\s`,
+				"line":   "Port 2222",
+				"backup": true,
+			},
+			expectChanged: true,
+			expectMsg:     "line replaced",
+		},
+		{
+			name: "line_present",
+			args: map[string]interface{}{"line": "Port 22"},
+		},
+		{
+			name:          "eof_without_newline",
+			args:          map[string]interface{}{"line": "c"},
+			expectChanged: true,
+			expectMsg:     "line added",
+		},
+		{
+			name:          "line_without_newline",
+			args:          map[string]interface{}{"line": "b"},
+			expectChanged: true,
+			expectMsg:     "line replaced",
+		},
+		{
+			name:          "crlf_line",
+			args:          map[string]interface{}{"line": "a"},
+			expectChanged: true,
+			expectMsg:     "line replaced",
+		},
+		{
+			name: "insertafter",
+			args: map[string]interface{}{
+				"line":        "added=1",
+				"insertafter": `^\[main\]`,
+			},
+			expectChanged: true,
+			expectMsg:     "line added",
+		},
+		{
+			name: "insertafter_firstmatch",
+			args: map[string]interface{}{
+				"line":        "added=1",
+				"insertafter": `^\[main\]`,
+				"firstmatch":  "yes",
+			},
+			expectChanged: true,
+			expectMsg:     "line added",
+		},
+		{
+			name: "insertafter_last_line",
+			args: map[string]interface{}{
+				"line":        "c",
+				"insertafter": "^b$",
+			},
+			expectChanged: true,
+			expectMsg:     "line added",
+		},
+		{
+			name: "insertafter_no_match",
+			args: map[string]interface{}{
+				"line":        "c",
+				"insertafter": "^zzz",
+			},
+			expectChanged: true,
+			expectMsg:     "line added",
+		},
+		{
+			name: "insertbefore",
+			args: map[string]interface{}{
+				"line":         "middle",
+				"insertbefore": "^# END",
+			},
+			expectChanged: true,
+			expectMsg:     "line added",
+		},
+		{
+			name: "insertbefore_bof",
+			args: map[string]interface{}{
+				"line":         "top",
+				"insertbefore": "BOF",
+			},
+			expectChanged: true,
+			expectMsg:     "line added",
+		},
+		{
+			name: "regexp_insertafter",
+			args: map[string]interface{}{
+				"regexp":      "^Listen ",
+				"line":        "Listen 8080",
+				"insertafter": "^# Listen",
+			},
+			expectChanged: true,
+			expectMsg:     "line added",
+		},
+		{
+			name: "backrefs",
+			args: map[string]interface{}{
+				"regexp":   `^port=(\d+)$`,
+				"line":     `port=\g<1>0 # was \1`,
+				"backrefs": true,
+			},
+			expectChanged: true,
+			expectMsg:     "line replaced",
+		},
+		{
+			name: "backrefs_no_match",
+			args: map[string]interface{}{
+				"regexp":   "^missing=(.*)",
+				"line":     `missing=\1`,
+				"backrefs": true,
+			},
+		},
+		{
+			name: "search_string",
+			args: map[string]interface{}{
+				"search_string": "a*b",
+				"line":          "a*b=3",
+			},
+			expectChanged: true,
+			expectMsg:     "line replaced",
+		},
+		{
+			name: "absent_regexp",
+			args: map[string]interface{}{
+				"regexp": "^#",
+				"state":  "absent",
+			},
+			expectChanged: true,
+			expectMsg:     "2 line(s) removed",
+			expectData:    map[string]interface{}{"found": 2},
+		},
+		{
+			name: "absent_line",
+			args: map[string]interface{}{
+				"line":  "keep",
+				"state": "absent",
+			},
+			expectChanged: true,
+			expectMsg:     "1 line(s) removed",
+		},
+		{
+			name: "absent_search_string",
+			args: map[string]interface{}{
+				"search_string": "zzz",
+				"state":         "absent",
+			},
+			expectData: map[string]interface{}{"found": 0},
+		},
+		{
+			name: "absent_missing_file",
+			args: map[string]interface{}{
+				"line":  "x",
+				"state": "absent",
+			},
+			expectMsg: "file not present",
+		},
+		{
+			name: "create",
+			args: map[string]interface{}{
+				"line":   "hello",
+				"create": true,
+			},
+			expectChanged: true,
+			expectMsg:     "line added",
+		},
+		{
+			name:         "missing_file",
+			args:         map[string]interface{}{"line": "hello"},
+			expectFailed: true,
+			expectMsg:    "does not exist !",
+		},
+		{
+			name: "mode",
+			args: map[string]interface{}{
+				"line": "x",
+				"mode": "0600",
+			},
+			expectChanged: true,
+			expectMsg:     "ownership, perms or SE linux context changed",
+		},
+		{
+			name: "validate",
+			args: map[string]interface{}{
+				"line":     "new",
+				"validate": "grep -q hello %s",
+			},
+			expectFailed: true,
+			expectMsg:    "failed to validate",
+		},
+		{
+			name: "backrefs_without_regexp",
+			args: map[string]interface{}{
+				"line":     "x",
+				"backrefs": true,
+			},
+			expectErr:         true,
+			expectErrContains: "regexp is required with backrefs=true",
+		},
+		{
+			name:              "present_without_line",
+			args:              map[string]interface{}{"regexp": "x"},
+			expectErr:         true,
+			expectErrContains: "line is required with state=present",
+		},
+		{
+			name:              "absent_without_criteria",
+			args:              map[string]interface{}{"state": "absent"},
+			expectErr:         true,
+			expectErrContains: "one of line, search_string, or regexp is required with state=absent",
+		},
+		{
+			name: "insertbefore_and_insertafter",
+			args: map[string]interface{}{
+				"line":         "x",
+				"insertafter":  "a",
+				"insertbefore": "b",
+			},
+			expectErr:         true,
+			expectErrContains: "parameters are mutually exclusive: insertbefore|insertafter",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			runGolden(&s.Suite, &lineinfileModule{}, "lineinfile", tc)
+		})
+	}
+}
+
+func TestLineinfileTestSuite(t *testing.T) {
+	suite.Run(t, new(LineinfileTestSuite))
+}
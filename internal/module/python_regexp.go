@@ -0,0 +1,200 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// templateEscapes holds the character escapes Python expands in
+// replacement templates.
+var templateEscapes = map[byte]string{
+	'a':  "\a",
+	'b':  "\b",
+	'f':  "\f",
+	'n':  "\n",
+	'r':  "\r",
+	't':  "\t",
+	'v':  "\v",
+	'\\': "\\",
+}
+
+// compilePython compiles a Python regular expression, as the regexp args
+// of Ansible modules take them, with Go's syntax. The two mostly agree;
+// \Z is translated, lookarounds and backreferences are not supported.
+func compilePython(
+	pattern string,
+) (*regexp.Regexp, error) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '\\' && i+1 < len(pattern) {
+			i++
+			if pattern[i] == 'Z' {
+				b.WriteString(`\z`)
+			} else {
+				b.WriteByte('\\')
+				b.WriteByte(pattern[i])
+			}
+			continue
+		}
+		b.WriteByte(pattern[i])
+	}
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+	}
+
+	return re, nil
+}
+
+// expandPython expands a Python replacement template, as re.sub and
+// Match.expand do, with the submatches of re in src: \1 and \g<1> insert
+// a group by number, \g<name> by name, and \n and friends are escapes, as are
+// \0 and three octal digits such as \101. An unmatched group inserts
+// nothing.
+func expandPython(
+	template string,
+	re *regexp.Regexp,
+	src string,
+	match []int,
+) (string, error) {
+	group := func(ref string) (string, error) {
+		n, err := strconv.Atoi(ref)
+		if err != nil {
+			if n = re.SubexpIndex(ref); n < 0 {
+				return "", fmt.Errorf("unknown group name '%s'", ref)
+			}
+		}
+		if n < 0 || n > re.NumSubexp() {
+			return "", fmt.Errorf("invalid group reference %d", n)
+		}
+		if match[2*n] < 0 {
+			return "", nil
+		}
+
+		return src[match[2*n]:match[2*n+1]], nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '\\' || i+1 == len(template) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		c = template[i]
+		switch {
+		case c == '0':
+			// \0 is an octal escape of up to three digits, not group 0
+			end := i + 1
+			for end < len(template) && end < i+3 && isOctal(template[end]) {
+				end++
+			}
+			n, _ := strconv.ParseUint(template[i:end], 8, 8)
+			b.WriteByte(byte(n))
+			i = end - 1
+
+		case c >= '1' && c <= '9':
+			ref := string(c)
+			if i+1 < len(template) && template[i+1] >= '0' && template[i+1] <= '9' {
+				i++
+				ref += string(template[i])
+			}
+			// three octal digits are an octal escape, e.g. \101 for "A"
+			if len(ref) == 2 && isOctal(ref[0]) && isOctal(ref[1]) &&
+				i+1 < len(template) && isOctal(template[i+1]) {
+				i++
+				n, _ := strconv.ParseUint(ref+string(template[i]), 8, 16)
+				if n > 0o377 {
+					return "", fmt.Errorf("octal escape value \\%s%c outside of range 0-0o377", ref, template[i])
+				}
+				b.WriteByte(byte(n))
+				break
+			}
+			s, err := group(ref)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(s)
+
+		case c == 'g':
+			end := strings.IndexByte(template[i:], '>')
+			if i+1 == len(template) || template[i+1] != '<' || end < 0 {
+				return "", fmt.Errorf("missing group name in template %q", template)
+			}
+			s, err := group(template[i+2 : i+end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(s)
+			i += end
+
+		case templateEscapes[c] != "":
+			b.WriteString(templateEscapes[c])
+
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			return "", fmt.Errorf("bad escape \\%c in template %q", c, template)
+
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// isOctal reports whether c is an octal digit.
+func isOctal(
+	c byte,
+) bool {
+	return c >= '0' && c <= '7'
+}
+
+// subPython replaces every match of re in src with the expanded Python
+// template, as re.subn does, and returns the number of replacements.
+func subPython(
+	re *regexp.Regexp,
+	template string,
+	src string,
+) (string, int, error) {
+	var b strings.Builder
+	last, count := 0, 0
+	for _, match := range re.FindAllStringSubmatchIndex(src, -1) {
+		s, err := expandPython(template, re, src, match)
+		if err != nil {
+			return "", 0, err
+		}
+		b.WriteString(src[last:match[0]])
+		b.WriteString(s)
+		last = match[1]
+		count++
+	}
+	b.WriteString(src[last:])
+
+	return b.String(), count, nil
+}
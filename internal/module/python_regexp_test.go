@@ -0,0 +1,200 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PythonRegexpTestSuite struct {
+	suite.Suite
+}
+
+func (s *PythonRegexpTestSuite) TestSubPython() {
+	tests := []struct {
+		name              string
+		pattern           string
+		template          string
+		src               string
+		expected          string
+		expectCount       int
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:        "numbered groups",
+			pattern:     `(\w+)=(\w+)`,
+			template:    `\2=\1`,
+			src:         "a=1 b=2",
+			expected:    "1=a 2=b",
+			expectCount: 2,
+		},
+		{
+			name:        "named and numbered g groups",
+			pattern:     `(?P<k>\w+)=(\w+)`,
+			template:    `\g<k>:\g<2>0`,
+			src:         "a=1",
+			expected:    "a:10",
+			expectCount: 1,
+		},
+		{
+			name:        "unmatched group is empty",
+			pattern:     `a(b)?`,
+			template:    `[\1]`,
+			src:         "a",
+			expected:    "[]",
+			expectCount: 1,
+		},
+		{
+			name:        "escapes",
+			pattern:     `,`,
+			template:    `\n\t\\`,
+			src:         "a,b",
+			expected:    "a\n\t\\b",
+			expectCount: 1,
+		},
+		{
+			name:        "backslash zero is NUL, not group 0",
+			pattern:     `(a)`,
+			template:    `[\0]`,
+			src:         "a",
+			expected:    "[\x00]",
+			expectCount: 1,
+		},
+		{
+			name:        "octal escapes",
+			pattern:     `(a)`,
+			template:    `\012\101\1`,
+			src:         "a",
+			expected:    "\nAa",
+			expectCount: 1,
+		},
+		{
+			name:        "other escapes are kept",
+			pattern:     `x`,
+			template:    `\.`,
+			src:         "x",
+			expected:    `\.`,
+			expectCount: 1,
+		},
+		{
+			name:        "end of string",
+			pattern:     `b\Z`,
+			template:    `c`,
+			src:         "ab",
+			expected:    "ac",
+			expectCount: 1,
+		},
+		{
+			name:              "invalid group reference",
+			pattern:           `(a)`,
+			template:          `\2`,
+			src:               "a",
+			expectErr:         true,
+			expectErrContains: "invalid group reference 2",
+		},
+		{
+			name:              "unknown group name",
+			pattern:           `(a)`,
+			template:          `\g<name>`,
+			src:               "a",
+			expectErr:         true,
+			expectErrContains: "unknown group name 'name'",
+		},
+		{
+			name:              "octal escape out of range",
+			pattern:           `(a)`,
+			template:          `\400`,
+			src:               "a",
+			expectErr:         true,
+			expectErrContains: `octal escape value \400 outside of range 0-0o377`,
+		},
+		{
+			name:              "bad escape",
+			pattern:           `a`,
+			template:          `\q`,
+			src:               "a",
+			expectErr:         true,
+			expectErrContains: `bad escape \q`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			re, err := compilePython(tc.pattern)
+			s.Require().NoError(err)
+
+			result, count, err := subPython(re, tc.template, tc.src)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, result)
+			s.Equal(tc.expectCount, count)
+		})
+	}
+}
+
+func (s *PythonRegexpTestSuite) TestCompilePython() {
+	tests := []struct {
+		name              string
+		pattern           string
+		src               string
+		expected          bool
+		expectErr         bool
+		expectErrContains string
+	}{
+		{name: "named group", pattern: `^(?P<k>\w+)=`, src: "a=1", expected: true},
+		{name: "end of string", pattern: `1\Z`, src: "a=1", expected: true},
+		{name: "escaped backslash before Z", pattern: `\\Z`, src: `a\Z`, expected: true},
+		{
+			name:              "lookbehind",
+			pattern:           "(?<=a)b",
+			expectErr:         true,
+			expectErrContains: `invalid regular expression "(?<=a)b"`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			re, err := compilePython(tc.pattern)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, re.MatchString(tc.src))
+		})
+	}
+}
+
+func TestPythonRegexpTestSuite(t *testing.T) {
+	suite.Run(t, new(PythonRegexpTestSuite))
+}
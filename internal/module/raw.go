@@ -0,0 +1,47 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// rawModule runs a command line on the host as it is, like
+// ansible.builtin.raw.
+type rawModule struct{}
+
+// Run runs the free-form command line, with the executable shell when one
+// is given. It fails when the command exits non-zero.
+func (m *rawModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	line := stringArg(args, "__value__")
+	if line == "" {
+		return nil, fmt.Errorf("no command given")
+	}
+
+	cmd := &connection.Command{Cmd: withExecutable(line, stringArg(args, "executable"))}
+
+	return execCommand(ctx, cmd, line, args)
+}
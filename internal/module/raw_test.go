@@ -0,0 +1,108 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+type RawTestSuite struct {
+	suite.Suite
+}
+
+func (s *RawTestSuite) TestRun() {
+	tests := []struct {
+		name              string
+		args              map[string]interface{}
+		expected          *Result
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "runs the command line",
+			args: map[string]interface{}{
+				"__value__": "echo one; echo two",
+			},
+			expected: &Result{
+				Changed: true,
+				Data: map[string]interface{}{
+					"cmd":          "echo one; echo two",
+					"rc":           0,
+					"stdout":       "one\ntwo",
+					"stdout_lines": []string{"one", "two"},
+					"stderr":       "",
+					"stderr_lines": []string{},
+				},
+			},
+		},
+		{
+			name: "executable",
+			args: map[string]interface{}{
+				"__value__":  "echo $0; exit 1",
+				"executable": "/bin/sh",
+			},
+			expected: &Result{
+				Changed: true,
+				Failed:  true,
+				Msg:     "non-zero return code",
+				Data: map[string]interface{}{
+					"cmd":          "echo $0; exit 1",
+					"rc":           1,
+					"stdout":       "/bin/sh",
+					"stdout_lines": []string{"/bin/sh"},
+					"stderr":       "",
+					"stderr_lines": []string{},
+				},
+			},
+		},
+		{
+			name:              "missing command",
+			args:              map[string]interface{}{},
+			expectErr:         true,
+			expectErrContains: "no command given",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m := &rawModule{}
+
+			result, err := m.Run(&Context{Conn: connection.NewLocal()}, tc.args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, result)
+		})
+	}
+}
+
+func TestRawTestSuite(t *testing.T) {
+	suite.Run(t, new(RawTestSuite))
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// builtinNamespace is the collection that owns short module names.
+const builtinNamespace = "ansible.builtin."
+
+// Registry resolves module names to their Go implementations.
+type Registry struct {
+	modules map[string]Module
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		modules: make(map[string]Module),
+	}
+}
+
+// NewDefaultRegistry creates a Registry with the built-in modules registered.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	builtins := map[string]Module{
+		"ansible.builtin.assert":      &assertModule{},
+		"ansible.builtin.blockinfile": &blockinfileModule{},
+		"ansible.builtin.command":     &commandModule{},
+		"ansible.builtin.copy":        &copyModule{},
+		"ansible.builtin.debug":       &debugModule{},
+		"ansible.builtin.fail":        &failModule{},
+		"ansible.builtin.file":        &fileModule{},
+		"ansible.builtin.find":        &findModule{},
+		"ansible.builtin.lineinfile":  &lineinfileModule{},
+		"ansible.builtin.raw":         &rawModule{},
+		"ansible.builtin.replace":     &replaceModule{},
+		"ansible.builtin.script":      &scriptModule{},
+		"ansible.builtin.set_fact":    &setFactModule{},
+		"ansible.builtin.shell":       &commandModule{shell: true},
+		"ansible.builtin.stat":        &statModule{},
+		"ansible.builtin.template":    &templateModule{},
+	}
+	for name, m := range builtins {
+		// builtin names are unique, registration cannot fail
+		_ = r.Register(name, m)
+	}
+
+	return r
+}
+
+// Register adds a module under its fully qualified collection name (e.g.,
+// "ansible.builtin.debug") and under its short name ("debug"). Short names
+// belong to ansible.builtin; modules from other collections only claim the
+// short name while it is unused.
+func (r *Registry) Register(
+	fqcn string,
+	m Module,
+) error {
+	parts := strings.Split(fqcn, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return fmt.Errorf("module name %q is not a fully qualified collection name", fqcn)
+	}
+
+	if _, exists := r.modules[fqcn]; exists {
+		return fmt.Errorf("module %q is already registered", fqcn)
+	}
+	r.modules[fqcn] = m
+
+	short := parts[2]
+	if _, taken := r.modules[short]; !taken || strings.HasPrefix(fqcn, builtinNamespace) {
+		r.modules[short] = m
+	}
+
+	return nil
+}
+
+// Lookup returns the module registered under name.
+func (r *Registry) Lookup(
+	name string,
+) (Module, bool) {
+	m, ok := r.modules[name]
+	return m, ok
+}
+
+// Names returns every registered name in sorted order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.modules))
+	for name := range r.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
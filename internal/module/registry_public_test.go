@@ -0,0 +1,149 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/module"
+)
+
+type RegistryPublicTestSuite struct {
+	suite.Suite
+}
+
+func named(msg string) module.Module {
+	return module.Func(func(_ *module.Context, _ map[string]interface{}) (*module.Result, error) {
+		return &module.Result{Msg: msg}, nil
+	})
+}
+
+func (s *RegistryPublicTestSuite) TestRegister() {
+	tests := []struct {
+		name              string
+		register          []string
+		lookup            map[string]string
+		missing           []string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name:     "fqcn and short name",
+			register: []string{"acme.tools.deploy"},
+			lookup: map[string]string{
+				"acme.tools.deploy": "acme.tools.deploy",
+				"deploy":            "acme.tools.deploy",
+			},
+		},
+		{
+			name:     "first collection keeps the short name",
+			register: []string{"acme.tools.deploy", "other.tools.deploy"},
+			lookup: map[string]string{
+				"deploy":             "acme.tools.deploy",
+				"other.tools.deploy": "other.tools.deploy",
+			},
+		},
+		{
+			name:     "builtin takes over the short name",
+			register: []string{"acme.tools.ping", "ansible.builtin.ping"},
+			lookup: map[string]string{
+				"ping":            "ansible.builtin.ping",
+				"acme.tools.ping": "acme.tools.ping",
+			},
+		},
+		{
+			name:              "short name rejected",
+			register:          []string{"deploy"},
+			expectErr:         true,
+			expectErrContains: "is not a fully qualified collection name",
+		},
+		{
+			name:              "empty segment rejected",
+			register:          []string{"acme..deploy"},
+			expectErr:         true,
+			expectErrContains: "is not a fully qualified collection name",
+		},
+		{
+			name:              "duplicate fqcn rejected",
+			register:          []string{"acme.tools.deploy", "acme.tools.deploy"},
+			expectErr:         true,
+			expectErrContains: `module "acme.tools.deploy" is already registered`,
+		},
+		{
+			name:     "unknown name",
+			register: []string{"acme.tools.deploy"},
+			missing:  []string{"ansible.builtin.deploy", "tools.deploy"},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			r := module.NewRegistry()
+
+			var err error
+			for _, name := range tc.register {
+				if err = r.Register(name, named(name)); err != nil {
+					break
+				}
+			}
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			for name, owner := range tc.lookup {
+				m, ok := r.Lookup(name)
+				s.Require().True(ok, name)
+
+				result, err := m.Run(&module.Context{}, nil)
+				s.Require().NoError(err)
+				s.Equal(owner, result.Msg)
+			}
+			for _, name := range tc.missing {
+				_, ok := r.Lookup(name)
+				s.False(ok, name)
+			}
+		})
+	}
+}
+
+func (s *RegistryPublicTestSuite) TestNewDefaultRegistry() {
+	r := module.NewDefaultRegistry()
+
+	for _, name := range []string{
+		"assert", "blockinfile", "command", "copy", "debug", "fail", "file", "find",
+		"lineinfile", "raw", "replace", "script", "set_fact", "shell", "stat", "template",
+	} {
+		s.Contains(r.Names(), name)
+		s.Contains(r.Names(), "ansible.builtin."+name)
+	}
+}
+
+func TestRegistryPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(RegistryPublicTestSuite))
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// remoteFile describes a path on the host.
+type remoteFile struct {
+	// exists reports whether the path exists.
+	exists bool
+	// isDir reports whether the path is a directory.
+	isDir bool
+	// mode holds the permission bits.
+	mode fs.FileMode
+	// owner is the name of the owning user.
+	owner string
+	// group is the name of the owning group.
+	group string
+	// checksum is the SHA-1 of the content of a regular file.
+	checksum string
+}
+
+// statFile returns what is at path on the host, following symlinks.
+func statFile(
+	ctx *Context,
+	path string,
+) (*remoteFile, error) {
+	q := connection.ShellQuote(path)
+	result, err := ctx.Conn.Exec(&connection.Command{
+		Cmd: fmt.Sprintf(
+			`[ -e %[1]s ] || exit 0; stat -L -c '%%F|%%a|%%U|%%G' -- %[1]s && if [ -f %[1]s ]; then sha1sum -- %[1]s; fi`,
+			q,
+		),
+	})
+	if err != nil || result.RC != 0 {
+		return nil, fmt.Errorf("failed to stat %s: %w", path, execError(result, err))
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(result.Stdout)), "\n")
+	if lines[0] == "" {
+		return &remoteFile{}, nil
+	}
+
+	fields := strings.Split(lines[0], "|")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("failed to stat %s: unexpected output %q", path, lines[0])
+	}
+
+	mode, err := strconv.ParseUint(fields[1], 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: invalid mode %q", path, fields[1])
+	}
+
+	info := &remoteFile{
+		exists: true,
+		isDir:  fields[0] == "directory",
+		mode:   fs.FileMode(mode),
+		owner:  fields[2],
+		group:  fields[3],
+	}
+	if len(lines) > 1 {
+		info.checksum, _, _ = strings.Cut(lines[1], " ")
+	}
+
+	return info, nil
+}
+
+// run runs cmd on the host and fails unless it exits zero.
+func run(
+	ctx *Context,
+	cmd string,
+) error {
+	result, err := ctx.Conn.Exec(&connection.Command{Cmd: cmd})
+	if err != nil || result.RC != 0 {
+		return execError(result, err)
+	}
+
+	return nil
+}
+
+// execError describes a command that could not run or exited non-zero.
+func execError(
+	result *connection.ExecResult,
+	err error,
+) error {
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("exit code %d: %s", result.RC, strings.TrimSpace(string(result.Stderr)))
+}
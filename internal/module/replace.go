@@ -0,0 +1,101 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+)
+
+// replaceModule replaces every match of a regular expression in a file,
+// like ansible.builtin.replace.
+type replaceModule struct{}
+
+// Run replaces every match of regexp in path with replace, in which \1 and
+// \g<name> insert groups of the match. The regexp is in multiline mode. An
+// after and a before pattern limit the replacements to the text after the
+// first match of after and before the last match of before, or the first
+// one following after when both are set.
+func (m *replaceModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	pattern := stringArg(args, "regexp")
+	if pattern == "" {
+		return nil, fmt.Errorf("missing required argument: regexp")
+	}
+	re, err := compilePython("(?m)" + pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	f, failed, err := openText(ctx, args, "Path %s does not exist !")
+	if err != nil || failed != nil {
+		return failed, err
+	}
+	contents := string(f.content)
+
+	after, before := stringArg(args, "after"), stringArg(args, "before")
+	section := ""
+	switch {
+	case after != "" && before != "":
+		section = after + "(?P<subsection>.*?)" + before
+	case after != "":
+		section = after + "(?P<subsection>.*)"
+	case before != "":
+		section = "(?P<subsection>.*)" + before
+	}
+
+	start, end := 0, len(contents)
+	if section != "" {
+		sectionRe, err := compilePython("(?s)" + section)
+		if err != nil {
+			return nil, err
+		}
+
+		match := sectionRe.FindStringSubmatchIndex(contents)
+		if match == nil {
+			return &Result{
+				Msg:  "Pattern for before/after params did not match the given file: " + section,
+				Data: map[string]interface{}{"rc": 0},
+			}, nil
+		}
+		group := sectionRe.SubexpIndex("subsection")
+		start, end = match[2*group], match[2*group+1]
+	}
+
+	replaced, count, err := subPython(re, stringArg(args, "replace"), contents[start:end])
+	if err != nil {
+		return nil, err
+	}
+
+	changed, msg := false, ""
+	if count > 0 && replaced != contents[start:end] {
+		changed, msg = true, fmt.Sprintf("%d replacements made", count)
+	}
+
+	result, err := f.save(ctx, []byte(contents[:start]+replaced+contents[end:]), changed, msg, args)
+	if err != nil || result.Failed {
+		return result, err
+	}
+	result.Data["rc"] = 0
+
+	return result, nil
+}
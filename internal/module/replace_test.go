@@ -0,0 +1,150 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReplaceTestSuite struct {
+	suite.Suite
+}
+
+func (s *ReplaceTestSuite) TestRun() {
+	tests := []goldenCase{
+		{
+			name: "groups",
+			args: map[string]interface{}{
+				"regexp":  `^listen (\d+)( .*)?;$`,
+				"replace": `listen 127.0.0.1:\1\2;`,
+			},
+			expectChanged: true,
+			expectMsg:     "2 replacements made",
+			expectData:    map[string]interface{}{"rc": 0},
+		},
+		{
+			name: "named_groups",
+			args: map[string]interface{}{
+				"regexp":  `^(?P<key>\w+) = (?P<value>.*)$`,
+				"replace": `\g<key>=\g<value>`,
+			},
+			expectChanged: true,
+			expectMsg:     "2 replacements made",
+		},
+		{
+			name: "octal_escape",
+			args: map[string]interface{}{
+				"regexp":  ",",
+				"replace": `\200`,
+			},
+			expectChanged: true,
+			expectMsg:     "1 replacements made",
+		},
+		{
+			name: "after",
+			args: map[string]interface{}{
+				"regexp":  "^enabled=no$",
+				"replace": "enabled=yes",
+				"after":   `\[b\]`,
+			},
+			expectChanged: true,
+			expectMsg:     "1 replacements made",
+		},
+		{
+			name: "before",
+			args: map[string]interface{}{
+				"regexp":  "^enabled=no$",
+				"replace": "enabled=yes",
+				"before":  `\[b\]`,
+			},
+			expectChanged: true,
+			expectMsg:     "1 replacements made",
+		},
+		{
+			name: "after_before",
+			args: map[string]interface{}{
+				"regexp":  "^x=1$",
+				"replace": "x=2",
+				"after":   "# start",
+				"before":  "# end",
+			},
+			expectChanged: true,
+			expectMsg:     "2 replacements made",
+		},
+		{
+			name: "section_no_match",
+			args: map[string]interface{}{
+				"regexp":  "^x=1$",
+				"replace": "x=2",
+				"after":   "nomatch",
+			},
+			expectMsg: "Pattern for before/after params did not match the given file: nomatch(?P<subsection>.*)",
+		},
+		{
+			name: "no_match",
+			args: map[string]interface{}{
+				"regexp":  "zzz",
+				"replace": "x",
+			},
+		},
+		{
+			name: "delete_lines",
+			args: map[string]interface{}{
+				"regexp": `^#.*\n`,
+			},
+			expectChanged: true,
+			expectMsg:     "2 replacements made",
+		},
+		{
+			name: "backup",
+			args: map[string]interface{}{
+				"regexp": `^#.*\n`,
+				"backup": true,
+			},
+			expectChanged: true,
+			expectMsg:     "1 replacements made",
+		},
+		{
+			name:         "missing_file",
+			args:         map[string]interface{}{"regexp": "x"},
+			expectFailed: true,
+			expectMsg:    "does not exist !",
+		},
+		{
+			name:              "missing_regexp",
+			args:              map[string]interface{}{"replace": "x"},
+			expectErr:         true,
+			expectErrContains: "missing required argument: regexp",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			runGolden(&s.Suite, &replaceModule{}, "replace", tc)
+		})
+	}
+}
+
+func TestReplaceTestSuite(t *testing.T) {
+	suite.Run(t, new(ReplaceTestSuite))
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// scriptModule transfers a local script to the host and runs it, like
+// ansible.builtin.script.
+type scriptModule struct{}
+
+// Run copies the script, found in the files directories of the task's
+// search paths, to a temporary directory on the host and runs it with its
+// arguments in chdir, unless the creates path exists or the removes path
+// does not. The executable, a command line such as "python3 -u", runs the
+// script when given.
+func (m *scriptModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	line := stringArg(args, "__value__")
+	if line == "" {
+		line = stringArg(args, "cmd")
+	}
+
+	words, err := connection.SplitArgs(line)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse script %q: %w", line, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("no script given")
+	}
+
+	src, err := findFile(ctx, "files", words[0])
+	if err != nil {
+		return nil, err
+	}
+
+	chdir := stringArg(args, "chdir")
+	if skipped, err := checkPaths(ctx, args, chdir); skipped != nil || err != nil {
+		return skipped, err
+	}
+
+	tmp, err := ctx.Conn.Exec(&connection.Command{Cmd: "mktemp -d"})
+	if err != nil || tmp.RC != 0 {
+		return nil, fmt.Errorf("failed to create a temporary directory: %w", execError(tmp, err))
+	}
+	dir := strings.TrimSpace(string(tmp.Stdout))
+	defer func() {
+		_, _ = ctx.Conn.Exec(&connection.Command{Cmd: "rm -rf " + connection.ShellQuote(dir)})
+	}()
+
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open script: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	remote := path.Join(dir, filepath.Base(src))
+	if err := ctx.Conn.Put(f, remote, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to transfer script: %w", err)
+	}
+
+	cmd := quoteArgs(append([]string{remote}, words[1:]...))
+	if executable := stringArg(args, "executable"); executable != "" {
+		cmd = executable + " " + cmd
+	}
+
+	return execCommand(ctx, &connection.Command{Cmd: cmd, Dir: chdir}, line, args)
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+type ScriptTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+	ctx    *Context
+}
+
+func (s *ScriptTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-script-test-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+
+	role := filepath.Join(dir, "roles", "app")
+	s.Require().NoError(os.MkdirAll(filepath.Join(role, "files"), 0o755))
+	s.Require().NoError(os.MkdirAll(filepath.Join(role, "tasks"), 0o755))
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(role, "files", "greet.sh"),
+		[]byte("#!/bin/sh\necho \"hello $1 from $(basename \"$PWD\")\"\n"),
+		0o644,
+	))
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(role, "tasks", "count.txt"),
+		[]byte("echo \"$# args\"\n"),
+		0o644,
+	))
+
+	s.ctx = &Context{
+		Conn:        connection.NewLocal(),
+		SearchPaths: []string{role, filepath.Join(role, "tasks")},
+	}
+}
+
+func (s *ScriptTestSuite) TearDownTest() {
+	_ = s.ctx.Conn.Close()
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func (s *ScriptTestSuite) TestRun() {
+	tests := []struct {
+		name              string
+		args              map[string]interface{}
+		expectChanged     bool
+		expectStdout      string
+		expectMsg         string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "runs a script from files with args in chdir",
+			args: map[string]interface{}{
+				"__value__": "greet.sh 'big world'",
+				"chdir":     "/",
+			},
+			expectChanged: true,
+			expectStdout:  "hello big world from /",
+		},
+		{
+			name: "executable interprets the script",
+			args: map[string]interface{}{
+				"cmd":        "count.txt a b c",
+				"executable": "/bin/sh -e",
+			},
+			expectChanged: true,
+			expectStdout:  "3 args",
+		},
+		{
+			name: "creates skips the script",
+			args: map[string]interface{}{
+				"__value__": "greet.sh",
+				"creates":   "/",
+			},
+			expectStdout: "skipped, since '/' exists",
+			expectMsg:    "Did not run command since '/' exists",
+		},
+		{
+			name: "missing script",
+			args: map[string]interface{}{
+				"__value__": "missing.sh",
+			},
+			expectErr:         true,
+			expectErrContains: `could not find or access "missing.sh", searched in: `,
+		},
+		{
+			name:              "no script",
+			args:              map[string]interface{}{},
+			expectErr:         true,
+			expectErrContains: "no script given",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m := &scriptModule{}
+
+			result, err := m.Run(s.ctx, tc.args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.False(result.Failed, result.Data)
+			s.Equal(tc.expectChanged, result.Changed)
+			s.Equal(tc.expectStdout, result.Data["stdout"])
+			s.Equal(tc.expectMsg, result.Msg)
+		})
+	}
+}
+
+func TestScriptTestSuite(t *testing.T) {
+	suite.Run(t, new(ScriptTestSuite))
+}
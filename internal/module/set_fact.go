@@ -0,0 +1,71 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"maps"
+)
+
+// setFactModule sets variables on the host for later tasks, like
+// ansible.builtin.set_fact.
+type setFactModule struct{}
+
+// Run returns its args as facts. With cacheable set they are also added to
+// ansible_facts.
+func (m *setFactModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	cacheable, err := boolArg(args, "cacheable", false)
+	if err != nil {
+		return nil, err
+	}
+
+	facts := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		if k == "cacheable" {
+			continue
+		}
+
+		if !identifier.MatchString(k) {
+			return nil, fmt.Errorf("the variable name %q is not valid", k)
+		}
+		facts[k] = v
+	}
+
+	if len(facts) == 0 {
+		return nil, fmt.Errorf("no key/value pairs provided, at least one is required for this action to succeed")
+	}
+
+	data := map[string]interface{}{"ansible_facts": maps.Clone(facts)}
+
+	if cacheable {
+		ansibleFacts := make(map[string]interface{})
+		if existing, ok := ctx.Vars["ansible_facts"].(map[string]interface{}); ok {
+			maps.Copy(ansibleFacts, existing)
+		}
+		maps.Copy(ansibleFacts, facts)
+		facts["ansible_facts"] = ansibleFacts
+	}
+
+	return &Result{Facts: facts, Data: data}, nil
+}
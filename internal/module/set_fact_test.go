@@ -0,0 +1,130 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SetFactTestSuite struct {
+	suite.Suite
+}
+
+func (s *SetFactTestSuite) TestRun() {
+	tests := []struct {
+		name              string
+		vars              map[string]interface{}
+		args              map[string]interface{}
+		expected          *Result
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "args become facts",
+			args: map[string]interface{}{
+				"port":  8080,
+				"names": []interface{}{"a", "b"},
+			},
+			expected: &Result{
+				Facts: map[string]interface{}{
+					"port":  8080,
+					"names": []interface{}{"a", "b"},
+				},
+				Data: map[string]interface{}{
+					"ansible_facts": map[string]interface{}{
+						"port":  8080,
+						"names": []interface{}{"a", "b"},
+					},
+				},
+			},
+		},
+		{
+			name: "cacheable adds to ansible_facts",
+			vars: map[string]interface{}{
+				"ansible_facts": map[string]interface{}{"os": "linux"},
+			},
+			args: map[string]interface{}{
+				"port":      8080,
+				"cacheable": "yes",
+			},
+			expected: &Result{
+				Facts: map[string]interface{}{
+					"port": 8080,
+					"ansible_facts": map[string]interface{}{
+						"os":   "linux",
+						"port": 8080,
+					},
+				},
+				Data: map[string]interface{}{
+					"ansible_facts": map[string]interface{}{"port": 8080},
+				},
+			},
+		},
+		{
+			name: "no facts",
+			args: map[string]interface{}{
+				"cacheable": false,
+			},
+			expectErr:         true,
+			expectErrContains: "no key/value pairs provided",
+		},
+		{
+			name: "invalid variable name",
+			args: map[string]interface{}{
+				"not-valid": 1,
+			},
+			expectErr:         true,
+			expectErrContains: `the variable name "not-valid" is not valid`,
+		},
+		{
+			name: "invalid cacheable",
+			args: map[string]interface{}{
+				"port":      1,
+				"cacheable": "maybe",
+			},
+			expectErr:         true,
+			expectErrContains: "cacheable must be a boolean",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			m := &setFactModule{}
+
+			result, err := m.Run(&Context{Vars: tc.vars}, tc.args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, result)
+		})
+	}
+}
+
+func TestSetFactTestSuite(t *testing.T) {
+	suite.Run(t, new(SetFactTestSuite))
+}
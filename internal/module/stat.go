@@ -0,0 +1,230 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// statFormat is the GNU stat --printf format of the records parseStat
+// reads: the raw mode in hex, owner, sizes, times, inode, device, link
+// count and the name, ended by a NUL since a name may hold a newline.
+const statFormat = `%f|%u|%g|%U|%G|%s|%X|%Y|%Z|%i|%d|%h|%n\0`
+
+// File type bits of a raw mode.
+const (
+	modeType    = 0o170000
+	modeSocket  = 0o140000
+	modeSymlink = 0o120000
+	modeRegular = 0o100000
+	modeBlock   = 0o060000
+	modeDir     = 0o040000
+	modeChar    = 0o020000
+	modeFIFO    = 0o010000
+)
+
+// checksumCommands maps the checksum_algorithm values of stat to the
+// command computing them.
+var checksumCommands = map[string]string{
+	"md5":    "md5sum",
+	"sha1":   "sha1sum",
+	"sha224": "sha224sum",
+	"sha256": "sha256sum",
+	"sha384": "sha384sum",
+	"sha512": "sha512sum",
+}
+
+// statModule reports facts about a path, like ansible.builtin.stat.
+type statModule struct{}
+
+// Run returns the stat of path: whether it exists and, when it does, its
+// type, mode, ownership, size and times, access for the connecting user,
+// the checksum of a regular file and its MIME type. Links are followed
+// with follow.
+func (m *statModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	path := stringArg(args, "path")
+	if path == "" {
+		return nil, fmt.Errorf("missing required argument: path")
+	}
+
+	follow, err := boolArg(args, "follow", false)
+	if err != nil {
+		return nil, err
+	}
+	getChecksum, err := boolArg(args, "get_checksum", true)
+	if err != nil {
+		return nil, err
+	}
+	getMime, err := boolArg(args, "get_mime", true)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := stringArg(args, "checksum_algorithm")
+	if algorithm == "" {
+		algorithm = "sha1"
+	}
+	checksumCommand, ok := checksumCommands[algorithm]
+	if !ok {
+		return nil, fmt.Errorf(
+			"value of checksum_algorithm must be one of: md5, sha1, sha224, sha256, sha384, sha512, got: %s",
+			algorithm,
+		)
+	}
+
+	q := connection.ShellQuote(path)
+	statFlags, fileFlags, isRegular := "", "", "[ -f "+q+" ] && [ ! -L "+q+" ]"
+	if follow {
+		statFlags, fileFlags, isRegular = "-L ", "-L ", "[ -f "+q+" ]"
+	}
+
+	script := []string{
+		fmt.Sprintf("[ -e %[1]s ] || [ -L %[1]s ] || exit 0", q),
+		fmt.Sprintf("printf stat= && stat %s--printf '%s' -- %s", statFlags, statFormat, q),
+		fmt.Sprintf("[ -L %[1]s ] && printf lnk_target= && readlink -z -- %[1]s && printf lnk_source= && readlink -fz -- %[1]s", q),
+		fmt.Sprintf("[ -r %s ] && printf 'readable=1\\0'", q),
+		fmt.Sprintf("[ -w %s ] && printf 'writeable=1\\0'", q),
+		fmt.Sprintf("[ -x %s ] && printf 'executable=1\\0'", q),
+	}
+	if getChecksum {
+		script = append(script, fmt.Sprintf(
+			"%s && [ -r %s ] && printf 'checksum=%%s\\0' \"$(%s < %s | cut -d' ' -f1)\"",
+			isRegular, q, checksumCommand, q,
+		))
+	}
+	if getMime {
+		script = append(script, fmt.Sprintf(
+			"command -v file >/dev/null && printf 'mimetype=%%s\\0' \"$(file -b %[1]s--mime-type -- %[2]s)\" "+
+				"&& printf 'charset=%%s\\0' \"$(file -b %[1]s--mime-encoding -- %[2]s)\"",
+			fileFlags, q,
+		))
+	}
+	script = append(script, "true")
+
+	result, err := ctx.Conn.Exec(&connection.Command{Cmd: strings.Join(script, "\n")})
+	if err != nil || result.RC != 0 {
+		return nil, fmt.Errorf("failed to stat %s: %w", path, execError(result, err))
+	}
+
+	stat := map[string]interface{}{"exists": false}
+	for _, record := range strings.Split(string(result.Stdout), "\x00") {
+		key, value, ok := strings.Cut(record, "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "stat":
+			if stat, err = parseStat(value); err != nil {
+				return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+			}
+			stat["path"] = path
+		case "readable", "writeable", "executable":
+			stat[key] = true
+		default:
+			stat[key] = value
+		}
+	}
+
+	if stat["exists"] == true {
+		for _, key := range []string{"readable", "writeable", "executable"} {
+			if _, ok := stat[key]; !ok {
+				stat[key] = false
+			}
+		}
+	}
+
+	return &Result{Data: map[string]interface{}{"stat": stat}}, nil
+}
+
+// parseStat parses a record of stat output in statFormat, without its
+// NUL, into the facts Ansible reports for a path.
+func parseStat(
+	record string,
+) (map[string]interface{}, error) {
+	fields := strings.SplitN(record, "|", 13)
+	if len(fields) != 13 {
+		return nil, fmt.Errorf("unexpected stat output %q", record)
+	}
+
+	raw, err := strconv.ParseUint(fields[0], 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected stat mode %q", fields[0])
+	}
+
+	// uid, gid, then size through link count
+	numbers := make([]int64, 0, 9)
+	for i, field := range fields[1:12] {
+		if i == 2 || i == 3 {
+			continue
+		}
+		n, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected stat output %q", record)
+		}
+		numbers = append(numbers, n)
+	}
+
+	fileType := raw & modeType
+	perm := raw & 0o7777
+
+	return map[string]interface{}{
+		"exists":  true,
+		"path":    fields[12],
+		"mode":    fmt.Sprintf("%04o", perm),
+		"isdir":   fileType == modeDir,
+		"isreg":   fileType == modeRegular,
+		"islnk":   fileType == modeSymlink,
+		"isblk":   fileType == modeBlock,
+		"ischr":   fileType == modeChar,
+		"isfifo":  fileType == modeFIFO,
+		"issock":  fileType == modeSocket,
+		"isuid":   perm&0o4000 != 0,
+		"isgid":   perm&0o2000 != 0,
+		"rusr":    perm&0o400 != 0,
+		"wusr":    perm&0o200 != 0,
+		"xusr":    perm&0o100 != 0,
+		"rgrp":    perm&0o040 != 0,
+		"wgrp":    perm&0o020 != 0,
+		"xgrp":    perm&0o010 != 0,
+		"roth":    perm&0o004 != 0,
+		"woth":    perm&0o002 != 0,
+		"xoth":    perm&0o001 != 0,
+		"uid":     numbers[0],
+		"gid":     numbers[1],
+		"pw_name": fields[3],
+		"gr_name": fields[4],
+		"size":    numbers[2],
+		"atime":   numbers[3],
+		"mtime":   numbers[4],
+		"ctime":   numbers[5],
+		"inode":   numbers[6],
+		"dev":     numbers[7],
+		"nlink":   numbers[8],
+	}, nil
+}
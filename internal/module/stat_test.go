@@ -0,0 +1,279 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+type StatTestSuite struct {
+	suite.Suite
+
+	dir string
+}
+
+func (s *StatTestSuite) SetupSuite() {
+	s.dir = s.T().TempDir()
+
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, "hello"), []byte("hello\n"), 0o644))
+	s.Require().NoError(os.Chmod(filepath.Join(s.dir, "hello"), os.ModeSetuid|0o751))
+	s.Require().NoError(os.Mkdir(filepath.Join(s.dir, "sub"), 0o700))
+	s.Require().NoError(os.Symlink(filepath.Join(s.dir, "hello"), filepath.Join(s.dir, "link")))
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, "new\nline"), []byte("hello\n"), 0o644))
+	s.Require().NoError(os.Symlink(filepath.Join(s.dir, "new\nline"), filepath.Join(s.dir, "link\n")))
+}
+
+func (s *StatTestSuite) TestRun() {
+	tests := []struct {
+		name              string
+		path              string
+		args              map[string]interface{}
+		expectStat        map[string]interface{}
+		expectMissing     []string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "regular file",
+			path: "hello",
+			args: map[string]interface{}{"get_mime": false},
+			expectStat: map[string]interface{}{
+				"exists":    true,
+				"isreg":     true,
+				"isdir":     false,
+				"islnk":     false,
+				"mode":      "4751",
+				"isuid":     true,
+				"isgid":     false,
+				"rusr":      true,
+				"xgrp":      true,
+				"roth":      false,
+				"xoth":      true,
+				"size":      int64(6),
+				"nlink":     int64(1),
+				"readable":  true,
+				"writeable": true,
+				"checksum":  "f572d396fae9206628714fb2ce00f72e94f2258f",
+			},
+			expectMissing: []string{"mimetype", "lnk_source"},
+		},
+		{
+			name: "checksum algorithm",
+			path: "hello",
+			args: map[string]interface{}{"checksum_algorithm": "sha256", "get_mime": false},
+			expectStat: map[string]interface{}{
+				"checksum": "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+			},
+		},
+		{
+			name:          "without checksum",
+			path:          "hello",
+			args:          map[string]interface{}{"get_checksum": "no", "get_mime": false},
+			expectStat:    map[string]interface{}{"exists": true},
+			expectMissing: []string{"checksum"},
+		},
+		{
+			name: "directory",
+			path: "sub",
+			args: map[string]interface{}{"get_mime": false},
+			expectStat: map[string]interface{}{
+				"exists": true,
+				"isdir":  true,
+				"isreg":  false,
+				"mode":   "0700",
+			},
+			expectMissing: []string{"checksum"},
+		},
+		{
+			name: "link is not followed",
+			path: "link",
+			args: map[string]interface{}{"get_mime": false},
+			expectStat: map[string]interface{}{
+				"islnk":      true,
+				"isreg":      false,
+				"lnk_source": "hello",
+				"lnk_target": "hello",
+			},
+			expectMissing: []string{"checksum"},
+		},
+		{
+			name: "link is followed",
+			path: "link",
+			args: map[string]interface{}{"follow": true, "get_mime": false},
+			expectStat: map[string]interface{}{
+				"islnk":    false,
+				"isreg":    true,
+				"mode":     "4751",
+				"checksum": "f572d396fae9206628714fb2ce00f72e94f2258f",
+			},
+		},
+		{
+			name: "name with a newline",
+			path: "new\nline",
+			args: map[string]interface{}{"get_mime": false},
+			expectStat: map[string]interface{}{
+				"exists":   true,
+				"isreg":    true,
+				"mode":     "0644",
+				"checksum": "f572d396fae9206628714fb2ce00f72e94f2258f",
+			},
+		},
+		{
+			name: "link with a newline",
+			path: "link\n",
+			args: map[string]interface{}{"get_mime": false},
+			expectStat: map[string]interface{}{
+				"islnk":      true,
+				"lnk_source": "new\nline",
+				"lnk_target": "new\nline",
+			},
+		},
+		{
+			name:          "missing path",
+			path:          "missing",
+			args:          map[string]interface{}{},
+			expectStat:    map[string]interface{}{"exists": false},
+			expectMissing: []string{"isreg", "readable", "checksum"},
+		},
+		{
+			name:              "invalid checksum algorithm",
+			path:              "hello",
+			args:              map[string]interface{}{"checksum_algorithm": "crc32"},
+			expectErr:         true,
+			expectErrContains: "value of checksum_algorithm must be one of",
+		},
+		{
+			name:              "path is required",
+			args:              map[string]interface{}{},
+			expectErr:         true,
+			expectErrContains: "missing required argument: path",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			args := tc.args
+			if tc.path != "" {
+				args["path"] = filepath.Join(s.dir, tc.path)
+			}
+			ctx := &Context{Conn: connection.NewLocal()}
+
+			result, err := (&statModule{}).Run(ctx, args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.False(result.Changed)
+			stat, ok := result.Data["stat"].(map[string]interface{})
+			s.Require().True(ok)
+			for key, value := range tc.expectStat {
+				if key == "lnk_source" || key == "lnk_target" {
+					value = filepath.Join(s.dir, value.(string))
+				}
+				s.Equal(value, stat[key], key)
+			}
+			for _, key := range tc.expectMissing {
+				s.NotContains(stat, key)
+			}
+		})
+	}
+}
+
+func (s *StatTestSuite) TestParseStat() {
+	tests := []struct {
+		name              string
+		line              string
+		expectStat        map[string]interface{}
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "directory with sticky bit",
+			line: "43ff|0|0|root|root|4096|1700000000|1700000001|1700000002|12|2049|3|/tmp|x",
+			expectStat: map[string]interface{}{
+				"isdir":   true,
+				"mode":    "1777",
+				"pw_name": "root",
+				"uid":     int64(0),
+				"atime":   int64(1700000000),
+				"mtime":   int64(1700000001),
+				"ctime":   int64(1700000002),
+				"inode":   int64(12),
+				"dev":     int64(2049),
+				"nlink":   int64(3),
+				"path":    "/tmp|x",
+			},
+		},
+		{
+			name: "fifo",
+			line: "11a4|1000|1000|u|g|0|1|1|1|5|6|1|/run/p",
+			expectStat: map[string]interface{}{
+				"isfifo":  true,
+				"isreg":   false,
+				"mode":    "0644",
+				"gr_name": "g",
+			},
+		},
+		{
+			name:              "too few fields",
+			line:              "81a4|0|0",
+			expectErr:         true,
+			expectErrContains: `unexpected stat output "81a4|0|0"`,
+		},
+		{
+			name:              "invalid mode",
+			line:              "zz|0|0|root|root|1|1|1|1|1|1|1|/x",
+			expectErr:         true,
+			expectErrContains: `unexpected stat mode "zz"`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			stat, err := parseStat(tc.line)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			for key, value := range tc.expectStat {
+				s.Equal(value, stat[key], key)
+			}
+		})
+	}
+}
+
+func TestStatTestSuite(t *testing.T) {
+	suite.Run(t, new(StatTestSuite))
+}
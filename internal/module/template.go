@@ -0,0 +1,104 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ansibleManaged is the value of ansible_managed in templates.
+const ansibleManaged = "Ansible managed"
+
+// templateModule renders a local template to the host, like
+// ansible.builtin.template.
+type templateModule struct{}
+
+// Run renders src, found in the templates directories of the task's search
+// paths, and writes it to dest, or into dest when it is a directory. The
+// file is only written when its checksum differs.
+func (m *templateModule) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	src := stringArg(args, "src")
+	if src == "" {
+		return nil, fmt.Errorf("missing required argument: src")
+	}
+	dest := stringArg(args, "dest")
+	if dest == "" {
+		return nil, fmt.Errorf("missing required argument: dest")
+	}
+	if ctx.Template == nil {
+		return nil, fmt.Errorf("templates cannot be rendered here")
+	}
+
+	local, err := findFile(ctx, "templates", src)
+	if err != nil {
+		return nil, err
+	}
+	args, err = preserveMode(args, local)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(dest, "/") {
+		dest = path.Join(dest, filepath.Base(local))
+	}
+	info, err := statFile(ctx, dest)
+	if err != nil {
+		return nil, err
+	}
+	if info.isDir {
+		dest = path.Join(dest, filepath.Base(local))
+		if info, err = statFile(ctx, dest); err != nil {
+			return nil, err
+		}
+	}
+
+	fullPath, err := filepath.Abs(local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", local, err)
+	}
+	hostname, _ := os.Hostname()
+
+	content, err := ctx.Template(local, map[string]interface{}{
+		"ansible_managed":   ansibleManaged,
+		"template_destpath": dest,
+		"template_fullpath": fullPath,
+		"template_host":     hostname,
+		"template_path":     local,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := writeFile(ctx, []byte(content), dest, info, args)
+	if err != nil {
+		return nil, err
+	}
+	result.Data["src"] = local
+
+	return result, nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+type TemplateTestSuite struct {
+	suite.Suite
+
+	srcDir string
+}
+
+func (s *TemplateTestSuite) SetupSuite() {
+	s.srcDir = s.T().TempDir()
+
+	templates := filepath.Join(s.srcDir, "templates")
+	s.Require().NoError(os.MkdirAll(templates, 0o755))
+	s.Require().NoError(os.WriteFile(
+		filepath.Join(templates, "app.conf.j2"),
+		[]byte("# {{ ansible_managed }}\nport={{ port }}\n"),
+		0o644,
+	))
+}
+
+// render stands in for the Jinja2 renderer: it replaces "{{ name }}" with
+// the extra variables and port with 8080.
+func (s *TemplateTestSuite) render(
+	path string,
+	extra map[string]interface{},
+) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	content := strings.ReplaceAll(string(data), "{{ port }}", "8080")
+	for k, v := range extra {
+		content = strings.ReplaceAll(content, "{{ "+k+" }}", fmt.Sprint(v))
+	}
+
+	return content, nil
+}
+
+func (s *TemplateTestSuite) TestRun() {
+	tests := []struct {
+		name              string
+		existing          string
+		dest              string
+		args              map[string]interface{}
+		expectChanged     bool
+		expectFile        string
+		expectErr         bool
+		expectErrContains string
+	}{
+		{
+			name: "renders src to dest",
+			dest: "app.conf",
+			args: map[string]interface{}{
+				"src":  "app.conf.j2",
+				"mode": "0600",
+			},
+			expectChanged: true,
+			expectFile:    "app.conf",
+		},
+		{
+			name:     "unchanged output is not written",
+			existing: "# Ansible managed\nport=8080\n",
+			dest:     "app.conf",
+			args: map[string]interface{}{
+				"src": "app.conf.j2",
+			},
+			expectFile: "app.conf",
+		},
+		{
+			name: "into a directory",
+			dest: "",
+			args: map[string]interface{}{
+				"src": "app.conf.j2",
+			},
+			expectChanged: true,
+			expectFile:    "app.conf.j2",
+		},
+		{
+			name: "missing template",
+			dest: "app.conf",
+			args: map[string]interface{}{
+				"src": "missing.j2",
+			},
+			expectErr:         true,
+			expectErrContains: `could not find or access "missing.j2"`,
+		},
+		{
+			name:              "missing src",
+			dest:              "app.conf",
+			args:              map[string]interface{}{},
+			expectErr:         true,
+			expectErrContains: "missing required argument: src",
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			dir := s.T().TempDir()
+			if tc.existing != "" {
+				s.Require().NoError(os.WriteFile(filepath.Join(dir, tc.dest), []byte(tc.existing), 0o644))
+			}
+
+			tc.args["dest"] = filepath.Join(dir, tc.dest)
+			ctx := &Context{
+				Conn:        connection.NewLocal(),
+				SearchPaths: []string{s.srcDir},
+				Template:    s.render,
+			}
+
+			result, err := (&templateModule{}).Run(ctx, tc.args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Contains(err.Error(), tc.expectErrContains)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.False(result.Failed, result.Msg)
+			s.Equal(tc.expectChanged, result.Changed)
+			s.Equal(filepath.Join(dir, tc.expectFile), result.Data["dest"])
+			s.Equal(filepath.Join(s.srcDir, "templates", "app.conf.j2"), result.Data["src"])
+
+			data, err := os.ReadFile(filepath.Join(dir, tc.expectFile))
+			s.Require().NoError(err)
+			s.Equal("# Ansible managed\nport=8080\n", string(data))
+		})
+	}
+}
+
+func TestTemplateTestSuite(t *testing.T) {
+	suite.Run(t, new(TemplateTestSuite))
+}
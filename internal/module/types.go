@@ -0,0 +1,83 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package module
+
+import (
+	"github.com/retr0h/voidspan/internal/connection"
+)
+
+// Module is the Go implementation behind an Ansible module name.
+type Module interface {
+	// Run executes the module with its rendered args.
+	Run(ctx *Context, args map[string]interface{}) (*Result, error)
+}
+
+// Func adapts an ordinary function to the Module interface.
+type Func func(ctx *Context, args map[string]interface{}) (*Result, error)
+
+// Run calls f(ctx, args).
+func (f Func) Run(
+	ctx *Context,
+	args map[string]interface{},
+) (*Result, error) {
+	return f(ctx, args)
+}
+
+// Context carries the state of the run a module is invoked in.
+type Context struct {
+	// Host is the inventory name of the host the task targets.
+	Host string
+	// Task is the name of the task invoking the module.
+	Task string
+	// Vars is the merged variable context of the task.
+	Vars map[string]interface{}
+	// Conn is the connection to the host. It connects on first use.
+	Conn connection.Connection
+	// SearchPaths lists the local directories relative files, such as the
+	// src of copy, are looked up in, most specific first.
+	SearchPaths []string
+	// Verbosity is the verbosity the run was started with (-v, -vv, ...).
+	Verbosity int
+	// Evaluate evaluates a bare Jinja2 expression, such as "result.stdout",
+	// against Vars and returns its value.
+	Evaluate func(expr string) (interface{}, error)
+	// Conditional evaluates a conditional, as used by when, against Vars.
+	Conditional func(cond string) (bool, error)
+	// Template renders the local template file at path against Vars and
+	// extra, whose variables take precedence.
+	Template func(path string, extra map[string]interface{}) (string, error)
+}
+
+// Result is the structured outcome of a module run.
+type Result struct {
+	// Changed reports whether the module modified the host.
+	Changed bool
+	// Failed reports whether the module failed.
+	Failed bool
+	// Skipped reports whether the module decided not to run.
+	Skipped bool
+	// Msg is a human readable summary of the outcome.
+	Msg string
+	// Data holds any additional return values.
+	Data map[string]interface{}
+	// Facts holds variables the module sets on the host for later tasks.
+	Facts map[string]interface{}
+}
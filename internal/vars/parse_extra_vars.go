@@ -0,0 +1,71 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package vars
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseExtraVars parses a single --extra-vars value. Like ansible-playbook it
+// accepts "key=value" pairs separated by spaces, an inline YAML or JSON
+// mapping, or "@path" to read a YAML or JSON file.
+func ParseExtraVars(
+	value string,
+) (map[string]interface{}, error) {
+	value = strings.TrimSpace(value)
+
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read extra vars file: %w", err)
+		}
+		value = string(data)
+	} else if !strings.HasPrefix(value, "{") {
+		return parseKeyValues(value)
+	}
+
+	vars := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(value), &vars); err != nil {
+		return nil, fmt.Errorf("failed to parse extra vars: %w", err)
+	}
+
+	return vars, nil
+}
+
+// parseKeyValues parses space separated key=value pairs.
+func parseKeyValues(
+	value string,
+) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+	for _, pair := range strings.Fields(value) {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid extra var %q: expected key=value", pair)
+		}
+		vars[k] = v
+	}
+
+	return vars, nil
+}
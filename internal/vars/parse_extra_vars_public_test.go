@@ -0,0 +1,140 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package vars_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/vars"
+)
+
+type ParseExtraVarsPublicTestSuite struct {
+	suite.Suite
+
+	tmpDir string
+}
+
+func (s *ParseExtraVarsPublicTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "voidspan-extra-vars-*")
+	s.Require().NoError(err)
+	s.tmpDir = dir
+}
+
+func (s *ParseExtraVarsPublicTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func (s *ParseExtraVarsPublicTestSuite) TestParseExtraVars() {
+	tests := []struct {
+		name              string
+		value             string
+		expected          map[string]interface{}
+		expectErr         bool
+		expectErrContains string
+		prepare           func(dir string)
+	}{
+		{
+			name:  "key value pairs",
+			value: "env=prod  region=us-east-1",
+			expected: map[string]interface{}{
+				"env":    "prod",
+				"region": "us-east-1",
+			},
+		},
+		{
+			name:  "value containing equals",
+			value: "query=a=b",
+			expected: map[string]interface{}{
+				"query": "a=b",
+			},
+		},
+		{
+			name:  "inline JSON",
+			value: `{"count": 3, "items": ["a", "b"]}`,
+			expected: map[string]interface{}{
+				"count": 3,
+				"items": []interface{}{"a", "b"},
+			},
+		},
+		{
+			name:  "file",
+			value: "@" + "extra.yml",
+			expected: map[string]interface{}{
+				"enabled": true,
+			},
+			prepare: func(dir string) {
+				_ = os.WriteFile(filepath.Join(dir, "extra.yml"), []byte("enabled: true\n"), 0o644)
+			},
+		},
+		{
+			name:              "missing file",
+			value:             "@missing.yml",
+			expectErr:         true,
+			expectErrContains: "failed to read extra vars file",
+		},
+		{
+			name:              "invalid JSON",
+			value:             `{"count": `,
+			expectErr:         true,
+			expectErrContains: "failed to parse extra vars",
+		},
+		{
+			name:              "bare word",
+			value:             "env",
+			expectErr:         true,
+			expectErrContains: `invalid extra var "env": expected key=value`,
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			if tc.prepare != nil {
+				tc.prepare(s.tmpDir)
+			}
+
+			value := tc.value
+			if len(value) > 0 && value[0] == '@' {
+				value = "@" + filepath.Join(s.tmpDir, value[1:])
+			}
+
+			got, err := vars.ParseExtraVars(value)
+
+			if tc.expectErr {
+				s.Error(err)
+				if tc.expectErrContains != "" {
+					s.Contains(err.Error(), tc.expectErrContains)
+				}
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Equal(tc.expected, got)
+		})
+	}
+}
+
+func TestParseExtraVarsPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseExtraVarsPublicTestSuite))
+}
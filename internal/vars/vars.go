@@ -0,0 +1,76 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package vars
+
+import (
+	"maps"
+)
+
+// Level is a variable precedence level. Variables from higher levels replace
+// those from lower levels.
+type Level int
+
+// Precedence levels, lowest first, following Ansible's variable precedence.
+const (
+	// RoleDefaults holds a role's defaults/main.yml.
+	RoleDefaults Level = iota
+	// Inventory holds inventory, group_vars and host_vars variables.
+	Inventory
+	// PlayVars holds a play's vars.
+	PlayVars
+	// RoleVars holds a role's vars/main.yml.
+	RoleVars
+	// TaskVars holds the vars of a task and of the blocks and includes around it.
+	TaskVars
+	// Facts holds set_fact and registered variables.
+	Facts
+	// ExtraVars holds variables passed on the command line.
+	ExtraVars
+	// Magic holds the variables describing the run, such as
+	// inventory_hostname, which cannot be overridden.
+	Magic
+)
+
+// levels lists every level from lowest to highest precedence.
+var levels = []Level{
+	RoleDefaults,
+	Inventory,
+	PlayVars,
+	RoleVars,
+	TaskVars,
+	Facts,
+	ExtraVars,
+	Magic,
+}
+
+// Layers holds one set of variables per precedence level.
+type Layers map[Level]map[string]interface{}
+
+// Merge flattens the layers into a single variable context. Top-level keys
+// from higher levels replace those from lower levels.
+func (l Layers) Merge() map[string]interface{} {
+	merged := make(map[string]interface{})
+	for _, level := range levels {
+		maps.Copy(merged, l[level])
+	}
+
+	return merged
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2025 John Dewey
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package vars_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/retr0h/voidspan/internal/vars"
+)
+
+type VarsPublicTestSuite struct {
+	suite.Suite
+}
+
+func (s *VarsPublicTestSuite) TestMerge() {
+	tests := []struct {
+		name     string
+		layers   vars.Layers
+		expected map[string]interface{}
+	}{
+		{
+			name:     "no layers",
+			layers:   vars.Layers{},
+			expected: map[string]interface{}{},
+		},
+		{
+			name: "every level in precedence order",
+			layers: vars.Layers{
+				vars.Magic:        {"magic": "magic"},
+				vars.ExtraVars:    {"magic": "extra", "extra": "extra"},
+				vars.Facts:        {"extra": "facts", "facts": "facts"},
+				vars.TaskVars:     {"extra": "task", "facts": "task", "task": "task"},
+				vars.RoleVars:     {"extra": "role", "facts": "role", "task": "role", "role": "role"},
+				vars.PlayVars:     {"role": "play", "play": "play"},
+				vars.Inventory:    {"play": "inventory", "inventory": "inventory"},
+				vars.RoleDefaults: {"inventory": "defaults", "defaults": "defaults"},
+			},
+			expected: map[string]interface{}{
+				"magic":     "magic",
+				"extra":     "extra",
+				"facts":     "facts",
+				"task":      "task",
+				"role":      "role",
+				"play":      "play",
+				"inventory": "inventory",
+				"defaults":  "defaults",
+			},
+		},
+		{
+			name: "mappings are replaced not merged",
+			layers: vars.Layers{
+				vars.RoleDefaults: {"cfg": map[string]interface{}{"a": 1, "b": 2}},
+				vars.PlayVars:     {"cfg": map[string]interface{}{"a": 3}},
+			},
+			expected: map[string]interface{}{
+				"cfg": map[string]interface{}{"a": 3},
+			},
+		},
+		{
+			name: "nil layer is ignored",
+			layers: vars.Layers{
+				vars.RoleDefaults: {"a": 1},
+				vars.PlayVars:     nil,
+			},
+			expected: map[string]interface{}{
+				"a": 1,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			s.Equal(tc.expected, tc.layers.Merge())
+		})
+	}
+}
+
+func TestVarsPublicTestSuite(t *testing.T) {
+	suite.Run(t, new(VarsPublicTestSuite))
+}